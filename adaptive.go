@@ -0,0 +1,104 @@
+package elogrus
+
+import (
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// AdaptiveConfig bounds the batch size and flush
+// interval adjusted in adaptive bulk mode, zero
+// values fall back to the defaults
+type AdaptiveConfig struct {
+	// MinActions is the smallest batch and the
+	// step batches grow by, default 100
+	MinActions int
+	// MaxActions is the largest batch, default 10000
+	MaxActions int
+	// MinInterval is the shortest flush interval
+	// and the step it shrinks by, default 100ms
+	MinInterval time.Duration
+	// MaxInterval is the longest flush
+	// interval, default 30 seconds
+	MaxInterval time.Duration
+	// TargetLatency is the bulk request latency above
+	// which the cluster is considered busy, default 1s
+	TargetLatency time.Duration
+}
+
+// WithAdaptiveBulk tunes the batch size and flush
+// interval of bulk mode from observed latency:
+// while the cluster keeps up batches grow and
+// flushes get more frequent step by step, slow
+// responses and 429s halve the batch size and
+// double the interval (AIMD). The BulkConfig
+// values are used as the starting point.
+func WithAdaptiveBulk(config AdaptiveConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.MinActions <= 0 {
+			config.MinActions = 100
+		}
+		if config.MaxActions <= 0 {
+			config.MaxActions = 10000
+		}
+		if config.MinInterval <= 0 {
+			config.MinInterval = 100 * time.Millisecond
+		}
+		if config.MaxInterval <= 0 {
+			config.MaxInterval = 30 * time.Second
+		}
+		if config.TargetLatency <= 0 {
+			config.TargetLatency = time.Second
+		}
+		hook.adaptive = &config
+	}
+}
+
+// adapt adjusts the batch size and flush
+// interval after a bulk request
+func (b *batcher) adapt(latency time.Duration, err error) {
+	a := b.hook.adaptive
+	if a == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case isThrottled(err) || latency > a.TargetLatency:
+		b.actions /= 2
+		b.interval *= 2
+	case err == nil:
+		b.actions += a.MinActions
+		b.interval -= a.MinInterval
+	}
+	if b.actions < a.MinActions {
+		b.actions = a.MinActions
+	}
+	if b.actions > a.MaxActions {
+		b.actions = a.MaxActions
+	}
+	if b.interval < a.MinInterval {
+		b.interval = a.MinInterval
+	}
+	if b.interval > a.MaxInterval {
+		b.interval = a.MaxInterval
+	}
+}
+
+// isThrottled reports whether ElasticSearch
+// rejected the request for being too busy
+func isThrottled(err error) bool {
+	switch e := err.(type) {
+	case *elastic.Error:
+		return e.Status == 429
+	case *BulkError:
+		for _, item := range e.Failed {
+			if item.Status == 429 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestAdaptiveBulk(t *testing.T) {
+	hook := &ElasticHook{}
+	WithBulk(BulkConfig{Actions: 400, FlushInterval: time.Second})(hook)
+	WithAdaptiveBulk(AdaptiveConfig{MinActions: 100, MaxActions: 600, TargetLatency: 500 * time.Millisecond})(hook)
+	b := hook.bulk
+
+	b.adapt(100*time.Millisecond, nil)
+	if b.actions != 500 || b.interval != 900*time.Millisecond {
+		t.Errorf("healthy cluster should grow batches additively, got %d / %s", b.actions, b.interval)
+	}
+
+	b.adapt(100*time.Millisecond, &BulkError{Failed: []*elastic.BulkResponseItem{{Status: 429}}})
+	if b.actions != 250 || b.interval != 1800*time.Millisecond {
+		t.Errorf("429 should back off multiplicatively, got %d / %s", b.actions, b.interval)
+	}
+
+	b.adapt(time.Second, nil)
+	b.adapt(time.Second, nil)
+	if b.actions != 100 {
+		t.Errorf("batch size must not drop below the minimum, got %d", b.actions)
+	}
+
+	for i := 0; i < 10; i++ {
+		b.adapt(time.Millisecond, nil)
+	}
+	if b.actions != 600 {
+		t.Errorf("batch size must not exceed the maximum, got %d", b.actions)
+	}
+}
@@ -0,0 +1,163 @@
+package elogrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AlertConfig configures the webhook
+// called when log shipping breaks
+type AlertConfig struct {
+	// URL of the webhook, e.g. a Slack
+	// incoming webhook or the PagerDuty
+	// events API
+	URL string
+	// RoutingKey sends PagerDuty Events v2
+	// payloads instead of Slack messages
+	RoutingKey string
+	// FailingFor triggers the alert when every
+	// delivery failed for that long, default
+	// 5 minutes
+	FailingFor time.Duration
+	// DropRate triggers the alert when more than
+	// this share of documents (0 to 1) is dropped
+	// within Window; 0 disables the check
+	DropRate float64
+	// Window for DropRate,
+	// default 1 minute
+	Window time.Duration
+	// Client defaults to one
+	// with a 5 second timeout
+	Client *http.Client
+}
+
+// WithAlertWebhook calls a webhook when delivery has
+// been failing for FailingFor or the drop rate is
+// exceeded, and again when delivery recovers, since
+// broken log shipping is otherwise invisible
+func WithAlertWebhook(config AlertConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.FailingFor <= 0 {
+			config.FailingFor = 5 * time.Minute
+		}
+		if config.Window <= 0 {
+			config.Window = time.Minute
+		}
+		if config.Client == nil {
+			config.Client = &http.Client{Timeout: 5 * time.Second}
+		}
+		hook.alerts = &alerter{config: config, host: hook.host, notify: hook.postAlert}
+	}
+}
+
+// minAlertSamples is the number of documents
+// a window needs before its drop rate counts
+const minAlertSamples = 20
+
+type alerter struct {
+	mu     sync.Mutex
+	config AlertConfig
+	host   string
+	notify func(config AlertConfig, text string, resolved bool)
+
+	failingSince time.Time
+	windowStart  time.Time
+	total        int
+	dropped      int
+	// alerted is the reason of the
+	// open alert, empty when none
+	alerted string
+}
+
+// record accounts the outcome
+// of a single document
+func (a *alerter) record(err error, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if now.Sub(a.windowStart) >= a.config.Window {
+		if a.alerted == "drop rate" && !a.dropRateExceeded() {
+			a.resolve("documents are no longer dropped")
+		}
+		a.windowStart = now
+		a.total, a.dropped = 0, 0
+	}
+	a.total++
+
+	switch {
+	case err == nil:
+		a.failingSince = time.Time{}
+		if a.alerted == "failing" {
+			a.resolve("ElasticSearch delivery recovered")
+		}
+	case err == ErrDropped:
+		a.dropped++
+	case a.failingSince.IsZero():
+		a.failingSince = now
+	}
+
+	if a.alerted != "" {
+		return
+	}
+	if !a.failingSince.IsZero() && now.Sub(a.failingSince) >= a.config.FailingFor {
+		a.alerted = "failing"
+		a.notify(a.config, fmt.Sprintf("ElasticSearch delivery from %s failing for %s: %v",
+			a.host, now.Sub(a.failingSince).Round(time.Second), err), false)
+	} else if a.dropRateExceeded() {
+		a.alerted = "drop rate"
+		a.notify(a.config, fmt.Sprintf("%s dropped %d of %d log documents within %s",
+			a.host, a.dropped, a.total, a.config.Window), false)
+	}
+}
+
+func (a *alerter) dropRateExceeded() bool {
+	return a.config.DropRate > 0 && a.total >= minAlertSamples &&
+		float64(a.dropped)/float64(a.total) > a.config.DropRate
+}
+
+func (a *alerter) resolve(text string) {
+	a.alerted = ""
+	a.notify(a.config, a.host+": "+text, true)
+}
+
+// postAlert calls the webhook
+// without blocking delivery
+func (hook *ElasticHook) postAlert(config AlertConfig, text string, resolved bool) {
+	var payload interface{} = map[string]string{"text": text}
+	if config.RoutingKey != "" {
+		action := "trigger"
+		if resolved {
+			action = "resolve"
+		}
+		payload = map[string]interface{}{
+			"routing_key":  config.RoutingKey,
+			"event_action": action,
+			"dedup_key":    "elogrus-" + hook.host + "-" + hook.index,
+			"payload": map[string]string{
+				"summary":  text,
+				"source":   hook.host,
+				"severity": "error",
+			},
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		hook.reportError(err)
+		return
+	}
+	go func() {
+		resp, err := config.Client.Post(config.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			hook.reportError(fmt.Errorf("Alert webhook failed: %w", err))
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			hook.reportError(fmt.Errorf("Alert webhook returned %s", resp.Status))
+		}
+	}()
+}
@@ -0,0 +1,60 @@
+package elogrus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type alertRecorder struct {
+	texts    []string
+	resolved []bool
+}
+
+func (r *alertRecorder) notify(config AlertConfig, text string, resolved bool) {
+	r.texts = append(r.texts, text)
+	r.resolved = append(r.resolved, resolved)
+}
+
+func TestAlertSustainedFailure(t *testing.T) {
+	rec := &alertRecorder{}
+	a := &alerter{config: AlertConfig{FailingFor: time.Minute, Window: time.Hour}, host: "web-1", notify: rec.notify}
+	start := time.Now()
+	failed := errors.New("connection refused")
+
+	a.record(failed, start)
+	a.record(failed, start.Add(30*time.Second))
+	if len(rec.texts) != 0 {
+		t.Fatal("alerted too early")
+	}
+	a.record(failed, start.Add(61*time.Second))
+	a.record(failed, start.Add(90*time.Second))
+	if len(rec.texts) != 1 || rec.resolved[0] || !strings.Contains(rec.texts[0], "failing for 1m1s") {
+		t.Fatalf("expected one alert, got %q", rec.texts)
+	}
+	a.record(nil, start.Add(2*time.Minute))
+	if len(rec.texts) != 2 || !rec.resolved[1] {
+		t.Errorf("expected a recovery notification, got %q", rec.texts)
+	}
+}
+
+func TestAlertDropRate(t *testing.T) {
+	rec := &alertRecorder{}
+	a := &alerter{config: AlertConfig{FailingFor: time.Hour, DropRate: 0.1, Window: time.Minute}, host: "web-1", notify: rec.notify}
+	now := time.Now()
+	for i := 0; i < minAlertSamples; i++ {
+		err := error(nil)
+		if i%4 == 0 {
+			err = ErrDropped
+		}
+		a.record(err, now)
+	}
+	if len(rec.texts) != 1 || !strings.Contains(rec.texts[0], "dropped 5 of 20") {
+		t.Fatalf("expected a drop rate alert, got %q", rec.texts)
+	}
+	a.record(nil, now.Add(2*time.Minute))
+	if len(rec.texts) != 1 {
+		t.Fatalf("the closed window still exceeded the rate, got %q", rec.texts)
+	}
+}
@@ -0,0 +1,286 @@
+package elogrus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// AsyncConfig configures async mode,
+// zero values fall back to the defaults
+type AsyncConfig struct {
+	// Buffer is the number of entries queued
+	// for the workers, 1000 unless memory is
+	// short, and Workers the number indexed
+	// concurrently, default 1, which keeps the
+	// entries in order; see WithSizing
+	Buffer  int
+	Workers int
+	// Policy applies when
+	// the buffer is full
+	Policy       EnqueuePolicy
+	BlockTimeout time.Duration
+	// Errors receives the error of every entry
+	// the workers failed to deliver; errors are
+	// dropped while it is full
+	Errors chan<- error
+}
+
+// NewAsyncElasticHook creates a hook indexing entries
+// from background workers, so Fire does not wait for
+// ElasticSearch. Call Close before exit to send the
+// queued entries. The parameters are the same as for
+// NewElasticHook.
+func NewAsyncElasticHook(client *elastic.Client, host string, level logrus.Level, index string, config AsyncConfig, opts ...Option) (*ElasticHook, error) {
+	return NewElasticHook(client, host, level, index, append(opts, WithAsync(config))...)
+}
+
+// WithAsync queues entries for background workers
+// which index them one by one, see
+// NewAsyncElasticHook. It has no effect in bulk
+// mode, which is asynchronous already.
+func WithAsync(config AsyncConfig) Option {
+	return func(hook *ElasticHook) {
+		sizing := hook.defaultSizing()
+		if config.Buffer <= 0 {
+			config.Buffer = sizing.AsyncBuffer
+		}
+		if config.Workers <= 0 {
+			config.Workers = sizing.AsyncWorkers
+		}
+		q := &asyncQueue{
+			config: config,
+			ch:     make(chan asyncItem, config.Buffer),
+		}
+		q.idle = sync.NewCond(&q.busyMu)
+		hook.async = q
+	}
+}
+
+// asyncItem holds the documents
+// of an entry waiting for a worker
+type asyncItem struct {
+	id    uint64
+	level logrus.Level
+	index string
+	// routing is the routing
+	// key, may be empty
+	routing string
+	docs    []map[string]interface{}
+	// ids are the IDs of docs, in
+	// the same order, see documentIDs
+	ids    []string
+	labels map[string]string
+	// fired is when the entry was
+	// fired, zero for internal ones
+	fired time.Time
+	// ctx bounds the delivery, the
+	// hook context when nil
+	ctx  context.Context
+	done func(error)
+}
+
+type asyncQueue struct {
+	// dropped is first to be 64-bit
+	// aligned for atomic access
+	dropped int64
+	config  AsyncConfig
+	ch      chan asyncItem
+	// evict reports an entry dropped
+	// for a newer one, set by start
+	evict func(asyncItem)
+	// mu is held for reading while adding,
+	// closed is set under the write lock
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+	// busy counts entries queued or being
+	// sent, idle is signalled when it is 0
+	busyMu sync.Mutex
+	busy   int
+	idle   *sync.Cond
+	// unsent holds the entries still queued
+	// when Shutdown gave up, guarded by unsentMu
+	unsentMu sync.Mutex
+	unsent   []asyncItem
+}
+
+func (q *asyncQueue) start(hook *ElasticHook) {
+	q.evict = hook.evicted
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for item := range q.ch {
+				if hook.ctx.Err() != nil {
+					q.abort(hook, item)
+					q.done(1)
+					continue
+				}
+				if err := hook.deliver(item); err != nil {
+					hook.reportFailed(err)
+					q.notify(err)
+				}
+				q.done(1)
+			}
+		}()
+	}
+}
+
+// abort keeps an entry queued when
+// Shutdown gave up, which returns it
+func (q *asyncQueue) abort(hook *ElasticHook, item asyncItem) {
+	err := hook.ctx.Err()
+	q.unsentMu.Lock()
+	q.unsent = append(q.unsent, item)
+	q.unsentMu.Unlock()
+	hook.observe(item.labels, err)
+	hook.recordSLO(err, item.fired)
+	hook.traceOutcome(item.id, err)
+	resolve(item.done, err)
+}
+
+// takeUnsent removes and returns
+// the entries aborted by Shutdown
+func (q *asyncQueue) takeUnsent() []asyncItem {
+	q.unsentMu.Lock()
+	defer q.unsentMu.Unlock()
+	items := q.unsent
+	q.unsent = nil
+	return items
+}
+
+// notify passes a delivery
+// error to the errors channel
+func (q *asyncQueue) notify(err error) {
+	if q.config.Errors == nil {
+		return
+	}
+	select {
+	case q.config.Errors <- err:
+	default:
+	}
+}
+
+// add queues item, it reports false
+// when the enqueue policy dropped it
+func (q *asyncQueue) add(item asyncItem) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return false
+	}
+
+	q.busyMu.Lock()
+	q.busy++
+	q.busyMu.Unlock()
+	select {
+	case q.ch <- item:
+		return true
+	default:
+	}
+	switch q.config.Policy {
+	case EnqueueDrop:
+	case EnqueueDropOldest:
+		for {
+			select {
+			case old := <-q.ch:
+				atomic.AddInt64(&q.dropped, 1)
+				if q.evict != nil {
+					q.evict(old)
+				}
+				q.done(1)
+			default:
+			}
+			select {
+			case q.ch <- item:
+				return true
+			default:
+			}
+		}
+	case EnqueueBlockTimeout:
+		timer := time.NewTimer(q.config.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case q.ch <- item:
+			return true
+		case <-timer.C:
+		}
+	default:
+		q.ch <- item
+		return true
+	}
+	q.done(1)
+	atomic.AddInt64(&q.dropped, 1)
+	return false
+}
+
+// done marks n entries as handled
+func (q *asyncQueue) done(n int) {
+	q.busyMu.Lock()
+	q.busy -= n
+	if q.busy == 0 {
+		q.idle.Broadcast()
+	}
+	q.busyMu.Unlock()
+}
+
+// wait blocks until the queued
+// entries have been handled
+func (q *asyncQueue) wait() {
+	q.busyMu.Lock()
+	for q.busy > 0 {
+		q.idle.Wait()
+	}
+	q.busyMu.Unlock()
+}
+
+// close stops accepting entries and
+// waits for the queued ones to be sent
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+// evicted reports an entry dropped
+// from the queue for a newer one
+func (hook *ElasticHook) evicted(item asyncItem) {
+	hook.trace(item.id, "dropped", ErrDropped)
+	hook.lost(item.level, len(item.docs))
+	hook.observe(item.labels, ErrDropped)
+	hook.recordSLO(ErrDropped, item.fired)
+	resolve(item.done, ErrDropped)
+}
+
+// fireAsync queues the entry for a worker
+func (hook *ElasticHook) fireAsync(entry *logrus.Entry, id uint64, s sample, done func(error)) error {
+	index := hook.indexFor(entry)
+	docs := hook.stampedDocuments(entry, s)
+	item := asyncItem{
+		id:      id,
+		level:   entry.Level,
+		index:   index,
+		routing: hook.routing(entry),
+		docs:    docs,
+		ids:     hook.documentIDs(docs),
+		labels:  hook.labels(entry, index),
+		fired:   time.Now(),
+		done:    done,
+	}
+	if !hook.async.add(item) {
+		hook.evicted(item)
+		return nil
+	}
+	hook.trace(id, "enqueued", nil)
+	return nil
+}
@@ -0,0 +1,115 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestAsyncDefaults(t *testing.T) {
+	hook := &ElasticHook{}
+	WithSizing(Sizing{AsyncWorkers: 1, AsyncBuffer: 1000})(hook)
+	WithAsync(AsyncConfig{})(hook)
+	if cap(hook.async.ch) != 1000 || hook.async.config.Workers != 1 {
+		t.Errorf("unexpected defaults: %d buffer, %d workers", cap(hook.async.ch), hook.async.config.Workers)
+	}
+}
+
+func TestAsyncSizing(t *testing.T) {
+	hook := &ElasticHook{}
+	WithAsync(AsyncConfig{})(hook)
+	if hook.async.config.Workers != 1 {
+		t.Errorf("expected a single worker by default, got %d", hook.async.config.Workers)
+	}
+
+	hook = &ElasticHook{}
+	WithSizing(Sizing{})(hook)
+	WithAsync(AsyncConfig{})(hook)
+	if hook.async.config.Workers < 1 || cap(hook.async.ch) < 100 {
+		t.Errorf("expected zero sizing fields to fall back, got %d buffer, %d workers", cap(hook.async.ch), hook.async.config.Workers)
+	}
+}
+
+func TestDefaultSizing(t *testing.T) {
+	for _, c := range []struct {
+		procs   int
+		memory  uint64
+		workers int
+		buffer  int
+	}{
+		{1, 0, 1, 1000},
+		{1, 32 << 20, 1, 512},
+		{4, 0, 2, 2000},
+		{64, 64 << 30, 8, 8000},
+		{2, 1 << 20, 1, 100},
+	} {
+		s := DefaultSizing(c.procs, c.memory)
+		if s.AsyncWorkers != c.workers || s.AsyncBuffer != c.buffer {
+			t.Errorf("%d procs, %d bytes: expected %d workers and %d buffer, got %+v",
+				c.procs, c.memory, c.workers, c.buffer, s)
+		}
+	}
+}
+
+func TestAsyncDropsWhenFull(t *testing.T) {
+	hook := &ElasticHook{}
+	WithAsync(AsyncConfig{Buffer: 1, Policy: EnqueueDrop})(hook)
+
+	if !hook.async.add(asyncItem{index: "test"}) {
+		t.Fatal("expected the first entry to be queued")
+	}
+	if hook.async.add(asyncItem{index: "test"}) {
+		t.Fatal("expected the entry to be dropped with a full buffer")
+	}
+	if n := hook.EnqueueDropped(); n != 1 {
+		t.Errorf("expected 1 dropped entry, got %d", n)
+	}
+}
+
+func TestAsyncClose(t *testing.T) {
+	hook := &ElasticHook{}
+	WithAsync(AsyncConfig{})(hook)
+	hook.async.close()
+	hook.async.close()
+	if hook.async.add(asyncItem{index: "test"}) {
+		t.Error("closed queue must not accept entries")
+	}
+}
+
+func TestAsyncCopiesData(t *testing.T) {
+	hook := &ElasticHook{}
+	WithAsync(AsyncConfig{})(hook)
+	entry := &logrus.Entry{Data: logrus.Fields{"user": "joe"}}
+
+	data := hook.fields(entry)
+	entry.Data["user"] = "jane"
+	if data["user"] != "joe" {
+		t.Error("queued documents must not see later changes to the entry")
+	}
+}
+
+func TestAsyncFlushTimeout(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithAsync(AsyncConfig{}),
+		WithMaxInFlight(1),
+		WithStopTimeout(20*time.Millisecond),
+	)
+	// occupy the only slot, as if the cluster hung
+	hook.inFlight <- struct{}{}
+
+	if err := hook.Fire(&logrus.Entry{Message: "pending", Data: logrus.Fields{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := hook.Flush(); err != ErrFlushTimeout {
+		t.Errorf("expected ErrFlushTimeout, got %v", err)
+	}
+	inFlight := hook.startFlush()
+	if err := hook.Flush(); err != ErrFlushTimeout || hook.startFlush() != inFlight {
+		t.Errorf("expected to wait for the flush in flight, got %v", err)
+	}
+	hook.Stop(false)
+	if err := hook.Flush(); err != nil {
+		t.Errorf("expected the queue to be drained after stopping, got %v", err)
+	}
+}
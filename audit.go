@@ -0,0 +1,74 @@
+package elogrus
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// AuditTransport is a http.RoundTripper logging
+// every outbound call (method, host, status and
+// duration) to Logger. Requests made by the hook
+// itself are never logged, so installing it on a
+// shared transport cannot cause a feedback loop.
+type AuditTransport struct {
+	// Transport used to send the request,
+	// http.DefaultTransport when nil
+	Transport http.RoundTripper
+	// Logger receives the audit entries
+	Logger *logrus.Logger
+	// Level of successful calls, failed
+	// calls are logged at error level
+	Level logrus.Level
+	// ExcludeHosts are hosts never logged,
+	// e.g. the ElasticSearch nodes
+	ExcludeHosts []string
+}
+
+// NewAuditTransport creates new audit transport
+// logging to logger at info level
+func NewAuditTransport(transport http.RoundTripper, logger *logrus.Logger) *AuditTransport {
+	return &AuditTransport{
+		Transport: transport,
+		Logger:    logger,
+		Level:     logrus.InfoLevel,
+	}
+}
+
+// RoundTrip is required to implement
+// http.RoundTripper
+func (t *AuditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if isInternal(req.Context()) || t.excluded(req.URL.Host) {
+		return transport.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := transport.RoundTrip(req)
+	fields := logrus.Fields{
+		"http.request.method": req.Method,
+		"url.domain":          req.URL.Hostname(),
+		"url.path":            req.URL.Path,
+		"event.duration":      time.Since(start).Nanoseconds(),
+	}
+	entry := t.Logger.WithFields(fields)
+	if err != nil {
+		entry.WithError(err).Error("outbound request failed")
+		return resp, err
+	}
+	entry.WithField("http.response.status_code", resp.StatusCode).Log(t.Level, "outbound request")
+	return resp, nil
+}
+
+func (t *AuditTransport) excluded(host string) bool {
+	for _, h := range t.ExcludeHosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package elogrus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type recordingHook struct {
+	entries []*logrus.Entry
+}
+
+func (h *recordingHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel, logrus.InfoLevel, logrus.DebugLevel}
+}
+
+func (h *recordingHook) Fire(entry *logrus.Entry) error {
+	h.entries = append(h.entries, entry)
+	return nil
+}
+
+func TestAuditTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	logger := logrus.New()
+	rec := &recordingHook{}
+	logger.Hooks.Add(rec)
+	client := &http.Client{Transport: NewAuditTransport(nil, logger)}
+
+	resp, err := client.Get(srv.URL + "/orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL+"/_bulk", nil)
+	resp, err = client.Do(req.WithContext(internalContext(context.Background())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(rec.entries) != 1 {
+		t.Fatalf("expected only the application request to be logged, got %d entries", len(rec.entries))
+	}
+	data := rec.entries[0].Data
+	if data["http.request.method"] != "GET" || data["url.path"] != "/orders" || data["http.response.status_code"] != http.StatusTeapot {
+		t.Errorf("unexpected fields %v", data)
+	}
+}
@@ -0,0 +1,129 @@
+package elogrus
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Fields of the audit chain
+const (
+	AuditInstanceField = "audit.instance"
+	AuditSeqField      = "audit.seq"
+	AuditPrevField     = "audit.prev"
+	AuditHashField     = "audit.hash"
+)
+
+var (
+	// Fired if a document does not
+	// follow its predecessor
+	ErrAuditChain = fmt.Errorf("Audit chain broken")
+)
+
+// WithAuditChain makes documents tamper-evident: each
+// carries its sequence number and a SHA-256 hash of
+// its content chained to the hash of the previous
+// document of instance, see VerifyAuditChain. The
+// chain restarts at sequence 1 with the process;
+// documents lost in delivery show up as gaps.
+func WithAuditChain(instance string) Option {
+	return func(hook *ElasticHook) {
+		hook.audit = &auditChain{instance: instance}
+	}
+}
+
+type auditChain struct {
+	mu       sync.Mutex
+	instance string
+	seq      uint64
+	prev     string
+}
+
+// link adds the chain fields to doc
+func (c *auditChain) link(doc map[string]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	doc[AuditInstanceField] = c.instance
+	doc[AuditSeqField] = c.seq + 1
+	body, err := json.Marshal(doc)
+	if err != nil {
+		delete(doc, AuditInstanceField)
+		delete(doc, AuditSeqField)
+		return err
+	}
+	c.seq++
+	doc[AuditPrevField] = c.prev
+	c.prev = auditHash(c.prev, body)
+	doc[AuditHashField] = c.prev
+	return nil
+}
+
+// chain links docs when the audit chain is on,
+// clones share the chain of their root unless
+// they were given their own
+func (hook *ElasticHook) chain(docs []map[string]interface{}) []map[string]interface{} {
+	if hook.audit == nil {
+		return docs
+	}
+	for _, doc := range docs {
+		if err := hook.audit.link(doc); err != nil {
+			hook.reportError(fmt.Errorf("Audit chain failed: %w", err))
+		}
+	}
+	return docs
+}
+
+func auditHash(prev string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prev))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyAuditChain checks documents of one instance,
+// as stored in ElasticSearch and sorted by sequence,
+// for changes and gaps. The first document may start
+// anywhere in the chain. The batch ID is stamped when
+// documents are sent, after chaining, and not covered.
+func VerifyAuditChain(docs []json.RawMessage) error {
+	var prev string
+	var seq json.Number
+	for i, raw := range docs {
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.UseNumber()
+		var doc map[string]interface{}
+		if err := d.Decode(&doc); err != nil {
+			return fmt.Errorf("%w: document %d: %v", ErrAuditChain, i, err)
+		}
+		hash, _ := doc[AuditHashField].(string)
+		docPrev, _ := doc[AuditPrevField].(string)
+		docSeq, _ := doc[AuditSeqField].(json.Number)
+		if i > 0 && (docPrev != prev || !follows(seq, docSeq)) {
+			return fmt.Errorf("%w: document %d does not follow %s", ErrAuditChain, i, seq)
+		}
+		delete(doc, AuditHashField)
+		delete(doc, AuditPrevField)
+		delete(doc, BatchIDField)
+		body, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		if auditHash(docPrev, body) != hash {
+			return fmt.Errorf("%w: document %d was changed", ErrAuditChain, i)
+		}
+		prev, seq = hash, docSeq
+	}
+	return nil
+}
+
+func follows(prev, next json.Number) bool {
+	p, err := prev.Int64()
+	if err != nil {
+		return false
+	}
+	n, err := next.Int64()
+	return err == nil && n == p+1
+}
@@ -0,0 +1,57 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestAuditChain(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "audit", WithAuditChain("node-1"))
+	var docs []json.RawMessage
+	for _, msg := range []string{"login", "transfer", "logout"} {
+		for _, doc := range hook.stampedDocuments(&logrus.Entry{Message: msg, Data: logrus.Fields{"amount": 1 << 60}}, sample{}) {
+			raw, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			docs = append(docs, raw)
+		}
+	}
+	if err := VerifyAuditChain(docs); err != nil {
+		t.Fatalf("expected a valid chain, got %v", err)
+	}
+	if err := VerifyAuditChain(docs[1:]); err != nil {
+		t.Errorf("a chain may be verified from any document, got %v", err)
+	}
+
+	tampered := append([]json.RawMessage(nil), docs...)
+	tampered[1] = json.RawMessage(strings.Replace(string(docs[1]), "transfer", "nothing", 1))
+	if err := VerifyAuditChain(tampered); !errors.Is(err, ErrAuditChain) {
+		t.Errorf("expected a changed document to be detected, got %v", err)
+	}
+	if err := VerifyAuditChain([]json.RawMessage{docs[0], docs[2]}); !errors.Is(err, ErrAuditChain) {
+		t.Errorf("expected a gap to be detected, got %v", err)
+	}
+}
+
+func TestAuditChainStamped(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "audit",
+		WithAuditChain("node-1"),
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithBatchIDs(),
+	)
+	docs := hook.stampedDocuments(&logrus.Entry{Message: "login", Data: logrus.Fields{}}, sample{dropped: 3})
+	items := []bulkItem{{doc: docs[0]}}
+	hook.bulk.stampBatch(items)
+	if !strings.Contains(string(items[0].body), SamplingRateField) || !strings.Contains(string(items[0].body), BatchIDField) {
+		t.Fatalf("expected a stamped document, got %s", items[0].body)
+	}
+	if err := VerifyAuditChain([]json.RawMessage{items[0].body}); err != nil {
+		t.Errorf("expected the stamped document to verify, got %v", err)
+	}
+}
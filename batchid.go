@@ -0,0 +1,117 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchIDField holds the ID of the
+// bulk batch a document was sent in
+const BatchIDField = "batch.id"
+
+// batchHistory is the number of
+// batch outcomes kept for Stats
+const batchHistory = 100
+
+// BatchStats is the outcome of a bulk batch
+type BatchStats struct {
+	ID   string
+	Time time.Time
+	// Documents is the size of the batch,
+	// Failed the documents not delivered
+	Documents int
+	Failed    int
+	// Err is the first error of the
+	// batch, empty when it succeeded
+	Err string
+}
+
+// WithBatchIDs stamps every document sent with the
+// bulk API with the ULID of its batch and keeps the
+// outcome of the last batches in Stats, to tell
+// whether a missing document's batch failed
+func WithBatchIDs() Option {
+	return func(hook *ElasticHook) {
+		hook.batchIDs = true
+	}
+}
+
+// batchRecord tracks a
+// batch while it is sent
+type batchRecord struct {
+	failed int64
+	stats  BatchStats
+}
+
+// batchLog keeps the
+// latest batch outcomes
+type batchLog struct {
+	mu      sync.Mutex
+	batches []BatchStats
+}
+
+func (l *batchLog) add(s BatchStats) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.batches) == batchHistory {
+		l.batches = append(l.batches[:0], l.batches[1:]...)
+	}
+	l.batches = append(l.batches, s)
+}
+
+func (l *batchLog) snapshot() []BatchStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]BatchStats(nil), l.batches...)
+}
+
+// stampBatch gives items a new batch ID, encoding
+// their documents with it, and returns the record
+// the outcomes of the items are counted in
+func (b *batcher) stampBatch(items []bulkItem) *batchRecord {
+	rec := &batchRecord{stats: BatchStats{ID: NewULID(), Time: time.Now(), Documents: len(items)}}
+	field := []byte(`{"` + BatchIDField + `":` + strconv.Quote(rec.stats.ID))
+	for i := range items {
+		items[i].batch = rec
+		body := items[i].body
+		if body == nil {
+			var err error
+			if body, err = json.Marshal(items[i].doc); err != nil {
+				// left to fail when sent
+				continue
+			}
+		}
+		if len(body) < 2 || body[0] != '{' {
+			continue
+		}
+		stamped := append([]byte(nil), field...)
+		if len(body) > 2 {
+			stamped = append(stamped, ',')
+		}
+		items[i].body = append(stamped, body[1:]...)
+	}
+	return rec
+}
+
+// fail counts a document
+// of the batch not delivered
+func (rec *batchRecord) fail() {
+	if rec != nil {
+		atomic.AddInt64(&rec.failed, 1)
+	}
+}
+
+// record adds the outcome of the batch
+func (b *batcher) record(rec *batchRecord, err error) {
+	if rec == nil {
+		return
+	}
+	rec.stats.Failed = int(atomic.LoadInt64(&rec.failed))
+	if err != nil {
+		rec.stats.Err = err.Error()
+	}
+	b.batches.add(rec.stats)
+}
@@ -0,0 +1,46 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestStampBatch(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}), WithBatchIDs())
+	items := []bulkItem{
+		{doc: map[string]interface{}{"Message": "one"}},
+		{doc: map[string]interface{}{}},
+	}
+	rec := hook.bulk.stampBatch(items)
+	for _, item := range items {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(item.body, &doc); err != nil {
+			t.Fatalf("invalid body %s: %v", item.body, err)
+		}
+		if doc[BatchIDField] != rec.stats.ID {
+			t.Errorf("expected batch ID %s, got %s", rec.stats.ID, item.body)
+		}
+	}
+}
+
+func TestBatchOutcomes(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}), WithBatchIDs())
+	hook.Fire(&logrus.Entry{Message: "lost", Data: logrus.Fields{}})
+	hook.Fire(&logrus.Entry{Message: "lost too", Data: logrus.Fields{}})
+	if err := hook.Flush(); err == nil {
+		t.Fatal("expected the batch to fail without a client")
+	}
+
+	batches := hook.Stats().Batches
+	if len(batches) != 1 {
+		t.Fatalf("expected one batch, got %v", batches)
+	}
+	if b := batches[0]; b.Documents != 2 || b.Failed != 2 || b.Err == "" || b.ID == "" {
+		t.Errorf("unexpected outcome %+v", b)
+	}
+}
@@ -0,0 +1,81 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// discardForwarder drops
+// the documents
+type discardForwarder struct{}
+
+func (discardForwarder) Forward(ctx context.Context, docs []ForwardedDocument) ([]error, error) {
+	return nil, nil
+}
+
+func benchmarkFire(b *testing.B, opts ...Option) {
+	hook, err := NewForwardingHook(discardForwarder{}, "localhost", logrus.DebugLevel, "bench", opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer hook.Close()
+	entry := &logrus.Entry{
+		Level:   logrus.InfoLevel,
+		Time:    time.Now(),
+		Message: "request served",
+		Data:    logrus.Fields{"user": "joe", "status": 200, "duration": 0.25},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := hook.Fire(entry); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFire(b *testing.B) {
+	benchmarkFire(b)
+}
+
+func BenchmarkFireBulk(b *testing.B) {
+	benchmarkFire(b, WithBulk(BulkConfig{Actions: 1000, FlushInterval: time.Second}))
+}
+
+func BenchmarkFireEnriched(b *testing.B) {
+	benchmarkFire(b,
+		WithStaticFields(logrus.Fields{"service": "api", "env": "prod"}),
+		WithEnrichment(ProcessEnricher(), EnricherFunc(func(ctx context.Context) (map[string]interface{}, error) {
+			return map[string]interface{}{"service.version": "1.2.3", "labels": map[string]string{"team": "payments", "tier": "1"}}, nil
+		})))
+}
+
+func BenchmarkFireCaller(b *testing.B) {
+	benchmarkFire(b, WithCaller(CallerConfig{}))
+}
+
+var benchDocument = map[string]interface{}{
+	"Host":      "localhost",
+	"Timestamp": "2024-01-02T03:04:05.000000006Z",
+	"Message":   "request served",
+	"Level":     "INFO",
+	"Data":      logrus.Fields{"user": "joe", "status": 200, "duration": 0.25},
+}
+
+func BenchmarkEncodeDocument(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		encodeDocument(benchDocument, nil)
+	}
+}
+
+func BenchmarkMarshalDocument(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		json.Marshal(benchDocument)
+	}
+}
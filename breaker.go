@@ -0,0 +1,237 @@
+package elogrus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+var (
+	// Fired if the circuit breaker
+	// rejects the request
+	ErrBreakerOpen = fmt.Errorf("Circuit breaker is open")
+)
+
+// BreakerState is the state
+// of the circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets all requests through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all requests
+	BreakerOpen
+	// BreakerHalfOpen lets a limited
+	// number of probe requests through
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// BreakerConfig tunes the circuit breaker,
+// zero values fall back to the defaults
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive
+	// failures opening the breaker, default 5
+	FailureThreshold int
+	// OpenDuration is how long the breaker stays
+	// open before probing, default 30 seconds
+	OpenDuration time.Duration
+	// HalfOpenProbes is the number of probe requests
+	// that must succeed to close the breaker, default 1
+	HalfOpenProbes int
+	// OnStateChange is called on every transition
+	OnStateChange func(from, to BreakerState)
+}
+
+// WithCircuitBreaker stops sending to
+// ElasticSearch after repeated failures
+func WithCircuitBreaker(config BreakerConfig) Option {
+	return func(hook *ElasticHook) {
+		hook.breaker = newBreaker(config)
+	}
+}
+
+type breaker struct {
+	mu        sync.Mutex
+	config    BreakerConfig
+	state     BreakerState
+	failures  int
+	inFlight  int
+	successes int
+	openedAt  time.Time
+	now       func() time.Time
+	// persist is called with the time the breaker
+	// reopens, or the zero time when it closes
+	persist func(until time.Time)
+}
+
+func newBreaker(config BreakerConfig) *breaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = 30 * time.Second
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = 1
+	}
+	return &breaker{config: config, now: time.Now}
+}
+
+// allow reports whether a request
+// may be sent; every allowed request
+// must be followed by done
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	from := b.state
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.config.OpenDuration {
+		b.state = BreakerHalfOpen
+		b.inFlight = 0
+		b.successes = 0
+	}
+	allowed := true
+	switch b.state {
+	case BreakerOpen:
+		allowed = false
+	case BreakerHalfOpen:
+		if b.inFlight+b.successes >= b.config.HalfOpenProbes {
+			allowed = false
+		} else {
+			b.inFlight++
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.changed(from, to)
+	return allowed
+}
+
+// done records the outcome
+// of an allowed request
+func (b *breaker) done(err error) {
+	b.mu.Lock()
+	from := b.state
+	failed := isClusterFailure(err)
+	switch b.state {
+	case BreakerClosed:
+		if failed {
+			b.failures++
+			if b.failures >= b.config.FailureThreshold {
+				b.trip()
+			}
+		} else {
+			b.failures = 0
+		}
+	case BreakerHalfOpen:
+		b.inFlight--
+		if failed {
+			b.trip()
+		} else {
+			b.successes++
+			if b.successes >= b.config.HalfOpenProbes {
+				b.state = BreakerClosed
+				b.failures = 0
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.changed(from, to)
+}
+
+// State returns the current state
+func (b *breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// trip opens the breaker,
+// b.mu must be held
+func (b *breaker) trip() {
+	b.state = BreakerOpen
+	b.openedAt = b.now()
+	b.failures = 0
+}
+
+func (b *breaker) changed(from, to BreakerState) {
+	if from == to {
+		return
+	}
+	if b.persist != nil {
+		switch to {
+		case BreakerOpen:
+			b.mu.Lock()
+			until := b.openedAt.Add(b.config.OpenDuration)
+			b.mu.Unlock()
+			b.persist(until)
+		case BreakerClosed:
+			b.persist(time.Time{})
+		}
+	}
+	if b.config.OnStateChange != nil {
+		b.config.OnStateChange(from, to)
+	}
+}
+
+// isClusterFailure reports whether err says
+// something about the health of the cluster:
+// transport errors, timeouts and 429 or 5xx
+// statuses. Rejected documents and errors
+// before the request, e.g. marshaling,
+// do not count
+func isClusterFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if err == ErrBreakerOpen || err == ErrBootstrapPending || isFatalClientError(err) || err == elastic.ErrTimeout || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		for _, item := range bulkErr.Failed {
+			if isClusterStatus(item.Status) {
+				return true
+			}
+		}
+		return false
+	}
+	var esErr *elastic.Error
+	if errors.As(err, &esErr) {
+		return isClusterStatus(esErr.Status)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func isClusterStatus(status int) bool {
+	return status == 429 || status >= 500
+}
+
+// BreakerState returns the state of the
+// circuit breaker, BreakerClosed when
+// the hook has none
+func (hook *ElasticHook) BreakerState() BreakerState {
+	if hook.breaker == nil {
+		return BreakerClosed
+	}
+	return hook.breaker.State()
+}
@@ -0,0 +1,97 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	var changes []string
+	b := newBreaker(BreakerConfig{
+		FailureThreshold: 2,
+		OpenDuration:     time.Minute,
+		HalfOpenProbes:   2,
+		OnStateChange: func(from, to BreakerState) {
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	down := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	for i := 0; i < 2; i++ {
+		if !b.allow() {
+			t.Fatal("closed breaker must allow requests")
+		}
+		b.done(down)
+	}
+	if b.allow() {
+		t.Fatal("breaker should be open after threshold failures")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow() || !b.allow() {
+		t.Fatal("half-open breaker should allow the configured probes")
+	}
+	if b.allow() {
+		t.Fatal("half-open breaker must not allow more than the configured probes")
+	}
+	b.done(nil)
+	b.done(nil)
+	if b.State() != BreakerClosed {
+		t.Fatalf("expected closed after successful probes, got %s", b.State())
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, changes)
+		}
+	}
+}
+
+func TestBreakerIgnoresRejectedDocuments(t *testing.T) {
+	b := newBreaker(BreakerConfig{FailureThreshold: 1})
+	b.allow()
+	b.done(&elastic.Error{Status: 400})
+	if b.State() != BreakerClosed {
+		t.Error("mapping errors must not open the breaker")
+	}
+}
+
+func TestIsClusterFailure(t *testing.T) {
+	_, marshalErr := json.Marshal(make(chan int))
+	for _, c := range []struct {
+		err    error
+		failed bool
+	}{
+		{marshalErr, false},
+		{ErrRetentionPattern, false},
+		{context.Canceled, false},
+		{fmt.Errorf("Delivery aborted: %w", context.Canceled), false},
+		{&elastic.Error{Status: 400}, false},
+		{&BulkError{Failed: []*elastic.BulkResponseItem{{Status: 400}}}, false},
+		{&elastic.Error{Status: 429}, true},
+		{&elastic.Error{Status: 503}, true},
+		{fmt.Errorf("Mirror failed: %w", &elastic.Error{Status: 502}), true},
+		{&BulkError{Failed: []*elastic.BulkResponseItem{{Status: 400}, {Status: 503}}}, true},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{context.DeadlineExceeded, true},
+		{elastic.ErrNoClient, true},
+		{ErrBreakerOpen, true},
+	} {
+		if got := isClusterFailure(c.err); got != c.failed {
+			t.Errorf("isClusterFailure(%v) = %v, expected %v", c.err, got, c.failed)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithBreakerStateFile persists the open state of the
+// circuit breaker to path, so a restarted (or crash
+// looping) process keeps the breaker open instead of
+// hitting a struggling cluster right away. Requires
+// WithCircuitBreaker.
+func WithBreakerStateFile(path string) Option {
+	return func(hook *ElasticHook) {
+		hook.breakerStateFile = path
+	}
+}
+
+type breakerState struct {
+	OpenUntil time.Time `json:"open_until"`
+}
+
+// restoreBreaker opens the breaker if the state
+// file says it is open, and persists changes
+func (hook *ElasticHook) restoreBreaker() {
+	if hook.breaker == nil || hook.breakerStateFile == "" {
+		return
+	}
+	path := hook.breakerStateFile
+	b := hook.breaker
+
+	if until, ok := loadBreakerState(path); ok && b.now().Before(until) {
+		b.state = BreakerOpen
+		b.openedAt = until.Add(-b.config.OpenDuration)
+	}
+	b.persist = func(until time.Time) {
+		if err := saveBreakerState(path, until); err != nil {
+			hook.reportError(err)
+		}
+	}
+}
+
+func loadBreakerState(path string) (time.Time, bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var state breakerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return time.Time{}, false
+	}
+	return state.OpenUntil, !state.OpenUntil.IsZero()
+}
+
+// saveBreakerState writes the state atomically,
+// a zero until removes the file
+func saveBreakerState(path string, until time.Time) error {
+	if until.IsZero() {
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	data, err := json.Marshal(breakerState{OpenUntil: until})
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
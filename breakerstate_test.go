@@ -0,0 +1,44 @@
+package elogrus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestBreakerStateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "elogrus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "breaker.json")
+
+	until := time.Now().Add(time.Minute)
+	if err := saveBreakerState(path, until); err != nil {
+		t.Fatal(err)
+	}
+
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBreakerStateFile(path),
+		WithCircuitBreaker(BreakerConfig{OpenDuration: 5 * time.Minute}),
+	)
+	if hook.BreakerState() != BreakerOpen {
+		t.Fatal("breaker should be restored as open")
+	}
+	if err := hook.Fire(&logrus.Entry{}); err != ErrBreakerOpen {
+		t.Fatalf("expected ErrBreakerOpen, got %v", err)
+	}
+
+	// successful probe after the restored deadline closes it and removes the file
+	hook.breaker.now = func() time.Time { return until.Add(time.Second) }
+	hook.breaker.allow()
+	hook.breaker.done(nil)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("state file should be removed once the breaker closes")
+	}
+}
@@ -0,0 +1,130 @@
+package elogrus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// BudgetExceededField marks the document
+// written when the daily budget runs out
+const BudgetExceededField = "budget.exceeded"
+
+// BudgetConfig limits what a hook
+// ships per day (UTC); zero limits
+// are not enforced
+type BudgetConfig struct {
+	// Documents and Bytes are the daily
+	// budget, bytes counted as accepted
+	// by ElasticSearch
+	Documents int64
+	Bytes     int64
+	// Level is the least severe level still
+	// shipped once the budget is exceeded,
+	// default logrus.WarnLevel
+	Level logrus.Level
+}
+
+// WithDailyBudget caps the documents and bytes shipped
+// per day, protecting shared clusters from runaway
+// debug logging: once exceeded, only entries at or
+// above config.Level are shipped until midnight UTC,
+// and a document marked budget.exceeded is written
+func WithDailyBudget(config BudgetConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Level == logrus.PanicLevel {
+			config.Level = logrus.WarnLevel
+		}
+		hook.budget = &budget{config: config, now: time.Now}
+	}
+}
+
+// BudgetDropped returns the number of entries
+// dropped today for exceeding the daily budget
+func (hook *ElasticHook) BudgetDropped() int64 {
+	if hook.budget == nil {
+		return 0
+	}
+	hook.budget.mu.Lock()
+	defer hook.budget.mu.Unlock()
+	return hook.budget.dropped
+}
+
+type budget struct {
+	mu        sync.Mutex
+	config    BudgetConfig
+	day       string
+	documents int64
+	bytes     int64
+	dropped   int64
+	exceeded  bool
+	now       func() time.Time
+}
+
+// roll starts a new day
+// budget, under mu
+func (b *budget) roll() {
+	day := b.now().UTC().Format("2006-01-02")
+	if day != b.day {
+		b.day = day
+		b.documents, b.bytes, b.dropped = 0, 0, 0
+		b.exceeded = false
+	}
+}
+
+// allow reports whether an entry at level is
+// shipped, and whether the budget ran out now
+func (b *budget) allow(level logrus.Level) (bool, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll()
+	over := (b.config.Documents > 0 && b.documents >= b.config.Documents) ||
+		(b.config.Bytes > 0 && b.bytes >= b.config.Bytes)
+	first := over && !b.exceeded
+	if over {
+		b.exceeded = true
+		if level > b.config.Level {
+			b.dropped++
+			return false, first
+		}
+	}
+	b.documents++
+	return true, first
+}
+
+// add counts bytes
+// shipped today
+func (b *budget) add(bytes int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll()
+	b.bytes += int64(bytes)
+}
+
+// withinBudget reports whether entry may be
+// shipped, writing the marker document when
+// the budget runs out
+func (hook *ElasticHook) withinBudget(entry *logrus.Entry) bool {
+	if hook.budget == nil {
+		return true
+	}
+	ok, exceeded := hook.budget.allow(entry.Level)
+	if exceeded {
+		level := logrus.WarnLevel
+		if hook.budget.config.Level < level {
+			level = hook.budget.config.Level
+		}
+		hook.fire(&logrus.Entry{
+			Time:    hook.budget.now(),
+			Level:   level,
+			Message: "Daily log budget exceeded, shipping only " + hook.budget.config.Level.String() + " and above",
+			Data: logrus.Fields{
+				BudgetExceededField: true,
+				"budget.documents":  hook.budget.config.Documents,
+				"budget.bytes":      hook.budget.config.Bytes,
+			},
+		}, nil)
+	}
+	return ok
+}
@@ -0,0 +1,40 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestDailyBudget(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithDailyBudget(BudgetConfig{Documents: 2}),
+	)
+	now := time.Date(2024, 5, 17, 23, 0, 0, 0, time.UTC)
+	hook.budget.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "debugging", Data: logrus.Fields{}})
+	}
+	hook.Fire(&logrus.Entry{Level: logrus.WarnLevel, Message: "disk low", Data: logrus.Fields{}})
+
+	items := hook.bulk.take()
+	if len(items) != 4 {
+		t.Fatalf("expected two entries, the marker and the warning, got %d", len(items))
+	}
+	marker := items[2].doc.(map[string]interface{})["Data"].(logrus.Fields)
+	if marker[BudgetExceededField] != true {
+		t.Errorf("expected the budget marker, got %v", marker)
+	}
+	if hook.BudgetDropped() != 1 {
+		t.Errorf("expected one dropped entry, got %d", hook.BudgetDropped())
+	}
+
+	now = now.Add(time.Hour)
+	hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "new day", Data: logrus.Fields{}})
+	if len(hook.bulk.take()) != 1 {
+		t.Error("expected the budget to reset at midnight UTC")
+	}
+}
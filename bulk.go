@@ -0,0 +1,792 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// BulkConfig configures bulk mode,
+// zero values fall back to the defaults
+type BulkConfig struct {
+	// Actions is the number of pending
+	// documents triggering a flush, default 1000
+	Actions int
+	// FlushInterval is the period in which pending
+	// documents are flushed, default 1 second
+	FlushInterval time.Duration
+	// Workers is the number of bulk requests sent
+	// concurrently, default 1 which keeps batches
+	// in order
+	Workers int
+	// MaxBytes flushes a batch when its bulk body
+	// reaches about that size and keeps requests
+	// below it, e.g. under http.max_content_length;
+	// 0 disables it. Documents are encoded when
+	// queued with it.
+	MaxBytes int
+	// MaxPending bounds the queued documents,
+	// at least Actions; 0 means unbounded
+	MaxPending int
+	// Policy applies when MaxPending
+	// documents are queued
+	Policy       EnqueuePolicy
+	BlockTimeout time.Duration
+	// Jitter varies each flush interval by up to
+	// that fraction of it, half above and half
+	// below, so instances sharing an interval do
+	// not flush in waves; 0 disables it
+	Jitter float64
+}
+
+// WithBulk batches documents and sends
+// them with the bulk API instead of one
+// request per entry. Call Close before
+// exit to flush the pending documents.
+func WithBulk(config BulkConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Actions <= 0 {
+			config.Actions = 1000
+		}
+		if config.FlushInterval <= 0 {
+			config.FlushInterval = time.Second
+		}
+		if config.Workers <= 0 {
+			config.Workers = 1
+		}
+		if config.MaxPending > 0 && config.MaxPending < config.Actions {
+			config.MaxPending = config.Actions
+		}
+		if config.Jitter < 0 || config.Jitter > 1 {
+			hook.optionErr(ErrInvalidJitter)
+		}
+		hook.bulk = &batcher{
+			hook:         hook,
+			actions:      config.Actions,
+			interval:     config.FlushInterval,
+			maxBytes:     config.MaxBytes,
+			maxPending:   config.MaxPending,
+			policy:       config.Policy,
+			blockTimeout: config.BlockTimeout,
+			jitter:       config.Jitter,
+			space:        make(chan struct{}),
+			workers:      make(chan struct{}, config.Workers),
+			kick:         make(chan struct{}, 1),
+			quit:         make(chan struct{}),
+		}
+	}
+}
+
+// NewBulkElasticHook creates a hook in bulk mode,
+// flushing by count, body size or interval as
+// configured. Call Close before exit to flush the
+// pending documents. The other parameters are the
+// same as for NewElasticHook.
+func NewBulkElasticHook(client *elastic.Client, host string, level logrus.Level, index string, config BulkConfig, opts ...Option) (*ElasticHook, error) {
+	return NewElasticHook(client, host, level, index, append(opts, WithBulk(config))...)
+}
+
+// SetBulkLimits changes the batch size and flush
+// interval at runtime, zero values keep the current
+// ones. Adaptive batching keeps adjusting them. It
+// is a no-op outside bulk mode.
+func (hook *ElasticHook) SetBulkLimits(actions int, interval time.Duration) {
+	b := hook.bulk
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	if actions > 0 {
+		b.actions = actions
+	}
+	if interval > 0 {
+		b.interval = interval
+	}
+	full := len(b.pending) >= b.actions
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// WithFlushLevel makes entries of the given
+// level or more severe flush the pending
+// batch before Fire returns, so errors
+// are visible without batching delay
+func WithFlushLevel(level logrus.Level) Option {
+	return func(hook *ElasticHook) {
+		hook.flushLevel = level
+		hook.flushOnLevel = true
+	}
+}
+
+var (
+	// Fired if Flush did not finish
+	// within the stop timeout
+	ErrFlushTimeout = fmt.Errorf("Flush timed out")
+	// Fired if the bulk flush jitter
+	// is not between 0 and 1
+	ErrInvalidJitter = fmt.Errorf("Bulk jitter must be between 0 and 1")
+)
+
+// BulkError is returned when ElasticSearch
+// rejects some documents of a bulk request
+type BulkError struct {
+	Failed []*elastic.BulkResponseItem
+}
+
+func (e *BulkError) Error() string {
+	reason := ""
+	if len(e.Failed) > 0 && e.Failed[0].Error != nil {
+		reason = ": " + e.Failed[0].Error.Reason
+	}
+	return fmt.Sprintf("%d documents failed%s", len(e.Failed), reason)
+}
+
+// Flush sends all pending documents in bulk
+// and async mode and waits for the result, up to
+// the stop timeout when set, see WithStopTimeout.
+// A flush outliving the timeout goes on in the
+// background; Flush waits for it meanwhile
+// instead of starting another.
+func (hook *ElasticHook) Flush() error {
+	if hook.bulk == nil && hook.async == nil {
+		return nil
+	}
+	call := hook.startFlush()
+	if hook.stopTimeout <= 0 {
+		<-call.done
+		return call.err
+	}
+
+	timer := time.NewTimer(hook.stopTimeout)
+	defer timer.Stop()
+	select {
+	case <-call.done:
+		return call.err
+	case <-timer.C:
+		return ErrFlushTimeout
+	}
+}
+
+// flushCall is a flush in flight,
+// err is set once done is closed
+type flushCall struct {
+	done chan struct{}
+	err  error
+}
+
+// startFlush returns the flush in flight
+// of the engine, starting one if there is none
+func (hook *ElasticHook) startFlush() *flushCall {
+	root := hook.root()
+	root.flushMu.Lock()
+	defer root.flushMu.Unlock()
+	if root.flushing != nil {
+		return root.flushing
+	}
+	call := &flushCall{done: make(chan struct{})}
+	root.flushing = call
+	go func() {
+		call.err = hook.flush()
+		root.flushMu.Lock()
+		root.flushing = nil
+		root.flushMu.Unlock()
+		close(call.done)
+	}()
+	return call
+}
+
+func (hook *ElasticHook) flush() error {
+	if hook.async != nil {
+		hook.async.wait()
+	}
+	if hook.bulk == nil {
+		return nil
+	}
+	return hook.bulk.flush()
+}
+
+// Close flushes pending documents and stops
+// background work. With a stop timeout it gives
+// up like a graceful Stop when it has passed.
+func (hook *ElasticHook) Close() error {
+	if hook.parent != nil {
+		return hook.Flush()
+	}
+	if hook.stopTimeout > 0 {
+		return hook.Stop(true)
+	}
+	hook.stop()
+	defer hook.controlCancel()
+	defer hook.closeSpool()
+	return hook.drain()
+}
+
+// drain sends the queued
+// documents and stops
+func (hook *ElasticHook) drain() error {
+	if hook.async != nil {
+		hook.async.close()
+	}
+	if hook.bulk == nil {
+		return nil
+	}
+	return hook.bulk.close()
+}
+
+// stop ends background
+// work besides batching
+func (hook *ElasticHook) stop() {
+	hook.quitOnce.Do(func() {
+		close(hook.quit)
+	})
+}
+
+// fireBulk queues the entry for the next batch
+func (hook *ElasticHook) fireBulk(entry *logrus.Entry, id uint64, s sample, done func(error)) error {
+	index := hook.indexFor(entry)
+	labels := hook.labels(entry, index)
+	routing := hook.routing(entry)
+	docs := hook.stampedDocuments(entry, s)
+	done = resolveAll(len(docs), done)
+	fired := time.Now()
+	for _, doc := range docs {
+		item := bulkItem{id: id, docID: hook.documentID(doc), level: entry.Level, index: index, pipeline: hook.pipeline, routing: routing, create: hook.dataStream, doc: doc, labels: labels, fired: fired, done: done}
+		if hook.bulk.maxBytes > 0 {
+			body, err := encodeDocument(doc, hook.static)
+			if err != nil {
+				hook.bulk.finish(item, err)
+				continue
+			}
+			item.body = body
+		}
+		if !hook.bulk.add(item) {
+			hook.trace(id, "dropped", ErrDropped)
+			hook.bulk.finish(item, ErrDropped)
+			continue
+		}
+		hook.trace(id, "enqueued", nil)
+	}
+
+	if hook.flushOnLevel && entry.Level <= hook.flushLevel {
+		return hook.bulk.flush()
+	}
+	return nil
+}
+
+type batcher struct {
+	// dropped is first to be 64-bit
+	// aligned for atomic access
+	dropped int64
+	hook    *ElasticHook
+
+	mu       sync.Mutex
+	actions  int
+	interval time.Duration
+	pending  []bulkItem
+	// pendingBytes is the estimated bulk
+	// body size, with maxBytes
+	pendingBytes int
+	maxBytes     int
+	// space is closed and replaced
+	// when pending documents are taken
+	space        chan struct{}
+	maxPending   int
+	policy       EnqueuePolicy
+	blockTimeout time.Duration
+	jitter       float64
+	// rand jitters the flushes of this hook
+	// apart from those of other processes
+	rand *rand.Rand
+	// unsent holds documents whose request
+	// was aborted by Shutdown
+	unsent []bulkItem
+	// batches holds the latest
+	// outcomes, with WithBatchIDs
+	batches batchLog
+
+	// workers holds a token per bulk request in flight
+	workers chan struct{}
+	kick    chan struct{}
+	quit    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+}
+
+func (b *batcher) start() {
+	b.wg.Add(1)
+	go b.run()
+}
+
+func (b *batcher) run() {
+	defer b.wg.Done()
+
+	for {
+		b.mu.Lock()
+		timer := time.NewTimer(b.nextFlush())
+		b.mu.Unlock()
+
+		select {
+		case <-timer.C:
+		case <-b.kick:
+			timer.Stop()
+		case <-b.quit:
+			timer.Stop()
+			return
+		}
+
+		b.workers <- struct{}{}
+		items := b.takeBatch()
+		if len(items) == 0 {
+			<-b.workers
+			continue
+		}
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			defer func() { <-b.workers }()
+			if err := b.sendItems(items); err != nil {
+				b.hook.reportFailed(err)
+			}
+		}()
+	}
+}
+
+// nextFlush returns the time until
+// the next flush, jittered, under mu
+func (b *batcher) nextFlush() time.Duration {
+	if b.jitter <= 0 {
+		return b.interval
+	}
+	if b.rand == nil {
+		b.rand = rand.New(rand.NewSource(time.Now().UnixNano() ^ int64(os.Getpid())<<32))
+	}
+	spread := b.jitter * float64(b.interval)
+	return b.interval + time.Duration((b.rand.Float64()-0.5)*spread)
+}
+
+// bulkItem is a document
+// waiting to be sent
+type bulkItem struct {
+	// id is the delivery ID
+	// when tracing
+	id uint64
+	// docID is the document
+	// ID, may be empty
+	docID string
+	level logrus.Level
+	index string
+	// pipeline is the ingest pipeline
+	// and routing the routing key,
+	// both may be empty
+	pipeline string
+	routing  string
+	// create writes with op_type
+	// create, for data streams
+	create bool
+	doc    interface{}
+	// body is the encoded doc,
+	// set with maxBytes
+	body   []byte
+	labels map[string]string
+	// fired is when the entry was
+	// fired, zero for internal ones
+	fired time.Time
+	// batch counts the outcome,
+	// with WithBatchIDs
+	batch *batchRecord
+	// done is called with the outcome,
+	// may be nil
+	done func(error)
+}
+
+// size estimates the bytes item adds
+// to the bulk body, with its action line
+func (item bulkItem) size() int {
+	return len(item.body) + len(item.index) + len(item.docID) + 48
+}
+
+// spoolRecord returns the
+// spool record of item
+func (item bulkItem) spoolRecord() spoolRecord {
+	return spoolRecord{Index: item.index, ID: item.docID, Pipeline: item.pipeline, Routing: item.routing, Create: item.create, Level: uint32(item.level)}
+}
+
+// finish reports the outcome of item
+func (b *batcher) finish(item bulkItem, err error) {
+	if err != nil {
+		item.batch.fail()
+	}
+	switch {
+	case err == nil:
+		b.hook.delivered()
+	case err == ErrDropped:
+		b.hook.lost(item.level, 1)
+	case b.hook.aborted(err):
+		// returned by Shutdown
+		// rather than spooled
+		b.mu.Lock()
+		b.unsent = append(b.unsent, item)
+		b.mu.Unlock()
+	case !b.hook.spoolDoc(item.spoolRecord(), item.doc, err):
+		b.hook.fallback(item.level, item.doc)
+		b.hook.discard(item.doc, err)
+		b.hook.lost(item.level, 1)
+	}
+	b.hook.observe(item.labels, err)
+	b.hook.recordSLO(err, item.fired)
+	b.hook.traceOutcome(item.id, err)
+	resolve(item.done, err)
+}
+
+// request serializes the document with static, the
+// body is passed on as is to know its size; an
+// empty typ leaves the type out
+func (item bulkItem) request(typ string, static map[string]staticField) (elastic.BulkableRequest, int, error) {
+	body := item.body
+	if body == nil {
+		var err error
+		if body, err = encode(item.doc, static); err != nil {
+			return nil, 0, err
+		}
+	}
+	req := elastic.NewBulkIndexRequest().
+		Index(item.index).
+		Doc(json.RawMessage(body))
+	if typ != "" {
+		req.Type(typ)
+	}
+	if item.docID != "" {
+		req.Id(item.docID)
+	}
+	if item.docID != "" || item.create {
+		req.OpType("create")
+	}
+	if item.routing != "" {
+		req.Routing(item.routing)
+	}
+	if item.pipeline != "" {
+		return pipelineRequest{req, item.pipeline}, len(body), nil
+	}
+	return req, len(body), nil
+}
+
+// add queues item, it reports false when
+// the enqueue policy dropped it
+func (b *batcher) add(item bulkItem) bool {
+	b.mu.Lock()
+	for b.policy == EnqueueDropOldest && b.maxPending > 0 && len(b.pending) >= b.maxPending {
+		old := b.pending[0]
+		b.pending = b.pending[1:]
+		if b.maxBytes > 0 {
+			b.pendingBytes -= old.size()
+		}
+		atomic.AddInt64(&b.dropped, 1)
+		b.mu.Unlock()
+		b.finish(old, ErrDropped)
+		b.mu.Lock()
+	}
+	// one timeout for all the wakeups
+	deadline := time.Now().Add(b.blockTimeout)
+	for b.maxPending > 0 && len(b.pending) >= b.maxPending {
+		space := b.space
+		b.mu.Unlock()
+		if !b.wait(space, deadline) {
+			atomic.AddInt64(&b.dropped, 1)
+			return false
+		}
+		b.mu.Lock()
+	}
+	b.pending = append(b.pending, item)
+	if b.maxBytes > 0 {
+		b.pendingBytes += item.size()
+	}
+	full := len(b.pending) >= b.actions ||
+		(b.maxPending > 0 && len(b.pending) >= b.maxPending) ||
+		(b.maxBytes > 0 && b.pendingBytes >= b.maxBytes)
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.kick <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// flush sends the pending documents
+// and waits for the result
+func (b *batcher) flush() error {
+	b.workers <- struct{}{}
+	defer func() { <-b.workers }()
+	var first error
+	for {
+		items := b.takeBatch()
+		if len(items) == 0 {
+			return first
+		}
+		if err := b.sendItems(items); err != nil && first == nil {
+			first = err
+		}
+	}
+}
+
+// take removes and returns
+// the pending documents
+func (b *batcher) take() []bulkItem {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := b.pending
+	b.pending = nil
+	b.pendingBytes = 0
+	if len(items) > 0 {
+		b.freed()
+	}
+	return items
+}
+
+// takeBatch removes and returns the next batch,
+// the pending documents up to maxBytes
+func (b *batcher) takeBatch() []bulkItem {
+	b.mu.Lock()
+	if b.maxBytes <= 0 {
+		b.mu.Unlock()
+		return b.take()
+	}
+	defer b.mu.Unlock()
+
+	n, size := 0, 0
+	for ; n < len(b.pending); n++ {
+		s := b.pending[n].size()
+		if n > 0 && size+s > b.maxBytes {
+			break
+		}
+		size += s
+	}
+	items := b.pending[:n:n]
+	b.pending = append([]bulkItem(nil), b.pending[n:]...)
+	b.pendingBytes -= size
+	if n > 0 {
+		b.freed()
+	}
+	if b.pendingBytes >= b.maxBytes {
+		select {
+		case b.kick <- struct{}{}:
+		default:
+		}
+	}
+	return items
+}
+
+// freed wakes adders waiting
+// for space, under mu
+func (b *batcher) freed() {
+	if b.space != nil {
+		close(b.space)
+		b.space = make(chan struct{})
+	}
+}
+
+func (b *batcher) sendItems(items []bulkItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	for _, item := range items {
+		b.hook.trace(item.id, "batched", nil)
+	}
+	var rec *batchRecord
+	if b.hook.batchIDs {
+		rec = b.stampBatch(items)
+	}
+	var err error
+	if b.hook.forwarder != nil {
+		err = b.forward(items)
+	} else {
+		sent := false
+		err = b.hook.do(func(client *elastic.Client) error {
+			sent = true
+			return b.send(client, items)
+		})
+		if !sent {
+			for _, item := range items {
+				b.finish(item, err)
+			}
+		}
+		b.mirror(items)
+	}
+	b.record(rec, err)
+	return err
+}
+
+// send ensures the routed indices exist and
+// sends items, documents for indices which
+// cannot be created are dropped
+func (b *batcher) send(client *elastic.Client, items []bulkItem) error {
+	var ensureErr error
+	checked := map[string]error{}
+	sent := make([]bulkItem, 0, len(items))
+	reqs := make([]elastic.BulkableRequest, 0, len(items))
+	sizes := make([]int, 0, len(items))
+	for _, item := range items {
+		err, ok := checked[item.index]
+		if !ok {
+			err = b.hook.ensureRouted(client, item.index)
+			checked[item.index] = err
+		}
+		var req elastic.BulkableRequest
+		var size int
+		if err == nil {
+			req, size, err = item.request(b.hook.bulkType(), b.hook.static)
+		}
+		if err != nil {
+			ensureErr = err
+			b.finish(item, err)
+			continue
+		}
+		sent = append(sent, item)
+		reqs = append(reqs, req)
+		sizes = append(sizes, size)
+	}
+	if len(sent) == 0 {
+		return ensureErr
+	}
+
+	for _, item := range sent {
+		b.hook.trace(item.id, "sent", nil)
+	}
+	var failed error
+	for attempt := 0; len(sent) > 0; attempt++ {
+		b.hook.pace(b.hook.ctx, len(reqs))
+		start := time.Now()
+		ctx, cancel := b.hook.dataContext()
+		errs, err := sendBulk(ctx, client, reqs)
+		cancel()
+		b.adapt(time.Since(start), err)
+		b.hook.throttled(err)
+		b.hook.flushed(len(reqs), time.Since(start), err)
+
+		var retry []int
+		var retryErr error
+		for i, item := range sent {
+			itemErr := err
+			if errs != nil {
+				itemErr = errs[i]
+			}
+			if isTransient(itemErr) {
+				retry = append(retry, i)
+				retryErr = itemErr
+				continue
+			}
+			if itemErr == nil {
+				b.hook.shipped(item.index, sizes[i])
+			} else {
+				failed = err
+			}
+			b.finish(item, itemErr)
+		}
+		if len(retry) == 0 {
+			break
+		}
+		if !b.hook.backoff(attempt, retryErr) {
+			for _, i := range retry {
+				itemErr := err
+				if errs != nil {
+					itemErr = errs[i]
+				}
+				b.finish(sent[i], itemErr)
+			}
+			failed = err
+			break
+		}
+		client = b.hook.failover(client, retryErr)
+		// resend only the documents
+		// which failed transiently
+		var next []bulkItem
+		var nextReqs []elastic.BulkableRequest
+		var nextSizes []int
+		for _, i := range retry {
+			b.hook.trace(sent[i].id, "retried", retryErr)
+			next = append(next, sent[i])
+			nextReqs = append(nextReqs, reqs[i])
+			nextSizes = append(nextSizes, sizes[i])
+		}
+		sent, reqs, sizes = next, nextReqs, nextSizes
+	}
+	if failed != nil {
+		return failed
+	}
+	return ensureErr
+}
+
+func (b *batcher) close() error {
+	b.once.Do(func() {
+		close(b.quit)
+	})
+	b.wg.Wait()
+	return b.flush()
+}
+
+// sendBulk sends reqs in a single bulk request. It
+// returns the error of each document, when the
+// response could be matched to the requests, and
+// a BulkError if any document failed.
+func sendBulk(ctx context.Context, client *elastic.Client, reqs []elastic.BulkableRequest) ([]error, error) {
+	bulk := client.Bulk()
+	for _, req := range reqs {
+		bulk.Add(req)
+	}
+	resp, err := bulk.DoC(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bulkErrors(resp, len(reqs))
+}
+
+// bulkErrors returns the errors of the n
+// documents of a bulk response, see sendBulk
+func bulkErrors(resp *elastic.BulkResponse, n int) ([]error, error) {
+	var errs []error
+	if len(resp.Items) == n {
+		errs = make([]error, n)
+	}
+	var failed []*elastic.BulkResponseItem
+	for i, item := range resp.Items {
+		for _, result := range item {
+			// a conflict on create means the
+			// document was indexed before
+			if (result.Status >= 200 && result.Status <= 299) || result.Status == 409 {
+				continue
+			}
+			failed = append(failed, result)
+			if errs != nil {
+				errs[i] = &elastic.Error{Status: result.Status, Details: result.Error}
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return errs, &BulkError{Failed: failed}
+	}
+	return errs, nil
+}
+
+// BulkErrors returns the errors of the n documents of a bulk
+// response as Forward does, for forwarders built on other
+// clients, which convert the items of their responses
+func BulkErrors(resp *elastic.BulkResponse, n int) ([]error, error) {
+	return bulkErrors(resp, n)
+}
@@ -0,0 +1,121 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestBulkDefaults(t *testing.T) {
+	hook := &ElasticHook{}
+	WithBulk(BulkConfig{})(hook)
+	if hook.bulk.actions != 1000 || hook.bulk.interval != time.Second {
+		t.Errorf("unexpected defaults: %d actions, %s interval", hook.bulk.actions, hook.bulk.interval)
+	}
+}
+
+func TestBatcherKicksWhenFull(t *testing.T) {
+	hook := &ElasticHook{}
+	WithBulk(BulkConfig{Actions: 2, FlushInterval: time.Hour})(hook)
+
+	hook.bulk.add(bulkItem{index: "test"})
+	select {
+	case <-hook.bulk.kick:
+		t.Fatal("batch is not full yet")
+	default:
+	}
+	hook.bulk.add(bulkItem{index: "test"})
+	select {
+	case <-hook.bulk.kick:
+	default:
+		t.Fatal("full batch should trigger a flush")
+	}
+	if len(hook.bulk.pending) != 2 {
+		t.Errorf("expected 2 pending requests, got %d", len(hook.bulk.pending))
+	}
+}
+
+func TestBulkCopiesData(t *testing.T) {
+	hook := &ElasticHook{}
+	WithBulk(BulkConfig{})(hook)
+	entry := &logrus.Entry{Data: logrus.Fields{"user": "joe"}}
+
+	data := hook.fields(entry)
+	entry.Data["user"] = "jane"
+	if data["user"] != "joe" {
+		t.Error("queued documents must not see later changes to the entry")
+	}
+}
+
+func TestBatcherMaxBytes(t *testing.T) {
+	hook := &ElasticHook{}
+	WithBulk(BulkConfig{Actions: 100, MaxBytes: 200, FlushInterval: time.Hour})(hook)
+
+	body := make([]byte, 40)
+	item := bulkItem{index: "test", body: body}
+	hook.bulk.add(item)
+	hook.bulk.add(item)
+	select {
+	case <-hook.bulk.kick:
+		t.Fatal("batch is not full yet")
+	default:
+	}
+	hook.bulk.add(item)
+	select {
+	case <-hook.bulk.kick:
+	default:
+		t.Fatal("batch reaching MaxBytes should trigger a flush")
+	}
+
+	// 3 items of 92 bytes, each batch stays below 200
+	for i, expected := range []int{2, 1} {
+		if n := len(hook.bulk.takeBatch()); n != expected {
+			t.Errorf("batch %d: expected %d items, got %d", i, expected, n)
+		}
+	}
+	if hook.bulk.pendingBytes != 0 || len(hook.bulk.takeBatch()) != 0 {
+		t.Error("expected the queue to be empty")
+	}
+}
+
+func TestSetBulkLimits(t *testing.T) {
+	hook := &ElasticHook{}
+	hook.SetBulkLimits(10, time.Second)
+	WithBulk(BulkConfig{Actions: 10, FlushInterval: time.Hour})(hook)
+
+	hook.bulk.add(bulkItem{index: "test"})
+	hook.bulk.add(bulkItem{index: "test"})
+	hook.SetBulkLimits(2, 0)
+	select {
+	case <-hook.bulk.kick:
+	default:
+		t.Fatal("lowering the batch size below the pending documents should trigger a flush")
+	}
+	if hook.bulk.actions != 2 || hook.bulk.interval != time.Hour {
+		t.Errorf("unexpected limits: %d actions, %s interval", hook.bulk.actions, hook.bulk.interval)
+	}
+}
+
+func TestBulkJitter(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Second, Jitter: 0.2}))
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		hook.bulk.mu.Lock()
+		d := hook.bulk.nextFlush()
+		hook.bulk.mu.Unlock()
+		if d < 900*time.Millisecond || d > 1100*time.Millisecond {
+			t.Fatalf("interval %v outside the jitter", d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Error("expected the intervals to vary")
+	}
+
+	hook = newHook(nil, "localhost", logrus.DebugLevel, "test", WithBulk(BulkConfig{Jitter: 2}))
+	if hook.err != ErrInvalidJitter {
+		t.Errorf("expected ErrInvalidJitter, got %v", hook.err)
+	}
+}
@@ -0,0 +1,111 @@
+package elogrus
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Fields set with WithCaller
+const (
+	CallerFileField     = "log.origin.file.name"
+	CallerLineField     = "log.origin.file.line"
+	CallerFunctionField = "log.origin.function"
+)
+
+// callerDepth bounds the
+// frames searched for the caller
+const callerDepth = 32
+
+// CallerConfig configures caller detection
+type CallerConfig struct {
+	// SkipPackages lists package path prefixes
+	// whose frames are not the caller, e.g. the
+	// one of a logging wrapper. The hook and
+	// logrus are always skipped.
+	SkipPackages []string
+	// Skip is the number of frames to skip
+	// after the first frame outside them
+	Skip int
+}
+
+// WithCaller adds the file, line and function
+// logging each entry. The entry caller is used
+// when logrus reports it, otherwise the stack is
+// walked, which is expensive. Hooks used behind
+// a logging wrapper list it in SkipPackages.
+func WithCaller(config CallerConfig) Option {
+	return func(hook *ElasticHook) {
+		config.SkipPackages = append([]string(nil), config.SkipPackages...)
+		hook.caller = &config
+	}
+}
+
+// hookPackage is the package path of the hook
+var hookPackage = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndex(name, "/")
+	return name[:slash+strings.Index(name[slash:], ".")]
+}()
+
+// skipped reports a frame of the
+// hook, logrus or a skipped package
+func (c *CallerConfig) skipped(frame runtime.Frame) bool {
+	// the tests of the package
+	// are callers like any other
+	if strings.HasPrefix(frame.Function, hookPackage+".") {
+		return !strings.HasSuffix(frame.File, "_test.go")
+	}
+	prefixes := append([]string{"github.com/Sirupsen/logrus", "github.com/sirupsen/logrus"}, c.SkipPackages...)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// callerFrame returns the frame logging entry
+func (hook *ElasticHook) callerFrame(entry *logrus.Entry) (runtime.Frame, bool) {
+	if entry.HasCaller() {
+		return *entry.Caller, true
+	}
+	config := hook.caller
+	if config == nil {
+		// size warnings look up
+		// callers without WithCaller
+		config = &CallerConfig{}
+	}
+	pcs := make([]uintptr, callerDepth)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	skip := config.Skip
+	for {
+		frame, more := frames.Next()
+		if !config.skipped(frame) {
+			if skip == 0 {
+				return frame, true
+			}
+			skip--
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// addCaller sets the caller
+// fields of entry on doc
+func (hook *ElasticHook) addCaller(doc map[string]interface{}, entry *logrus.Entry) {
+	if hook.caller == nil {
+		return
+	}
+	frame, ok := hook.callerFrame(entry)
+	if !ok {
+		return
+	}
+	doc[hook.originField(CallerFileField, "file")] = frame.File
+	doc[hook.originField(CallerLineField, "line")] = frame.Line
+	doc[hook.originField(CallerFunctionField, "function")] = frame.Function
+}
@@ -0,0 +1,81 @@
+package elogrus
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// logVia stands in for a logging wrapper
+func logVia(hook *ElasticHook, entry *logrus.Entry) map[string]interface{} {
+	return hook.document(entry)
+}
+
+func TestCallerDetection(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithCaller(CallerConfig{}), WithFlatOrigin())
+	_, _, line, _ := runtime.Caller(0)
+	doc := hook.document(&logrus.Entry{Data: logrus.Fields{}})
+	if !strings.HasSuffix(doc[CallerFileField].(string), "caller_test.go") || doc[CallerLineField] != line+1 {
+		t.Errorf("expected this line, got %v:%v", doc[CallerFileField], doc[CallerLineField])
+	}
+	if !strings.HasSuffix(doc[CallerFunctionField].(string), "TestCallerDetection") {
+		t.Errorf("unexpected function %v", doc[CallerFunctionField])
+	}
+
+	hook = newHook(nil, "localhost", logrus.DebugLevel, "test", WithCaller(CallerConfig{Skip: 1}), WithFlatOrigin())
+	doc = logVia(hook, &logrus.Entry{Data: logrus.Fields{}})
+	if !strings.HasSuffix(doc[CallerFunctionField].(string), "TestCallerDetection") {
+		t.Errorf("expected the wrapper to be skipped, got %v", doc[CallerFunctionField])
+	}
+}
+
+func TestCallerPrefersEntryCaller(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithCaller(CallerConfig{}), WithFlatOrigin())
+	logger := logrus.New()
+	logger.ReportCaller = true
+	frame := &runtime.Frame{File: "/app/main.go", Line: 42, Function: "main.main"}
+	doc := hook.document(&logrus.Entry{Logger: logger, Caller: frame, Data: logrus.Fields{}})
+	if doc[CallerFileField] != "/app/main.go" || doc[CallerLineField] != 42 {
+		t.Errorf("expected the entry caller, got %v:%v", doc[CallerFileField], doc[CallerLineField])
+	}
+
+	hook = newHook(nil, "localhost", logrus.DebugLevel, "test")
+	if _, ok := hook.document(&logrus.Entry{Data: logrus.Fields{}})[CallerFileField]; ok {
+		t.Error("caller capture must be off by default")
+	}
+}
+
+func TestFireKeepsEntryData(t *testing.T) {
+	fwd := &flakyForwarder{}
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test",
+		WithCaller(CallerConfig{}),
+		WithStaticFields(logrus.Fields{"service": "api"}),
+		WithFlattening(FlattenConfig{}),
+		WithMaxDocumentSize(512),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := logrus.Fields{
+		"body":          strings.Repeat("x", 1024),
+		"request":       map[string]interface{}{"path": "/"},
+		logrus.ErrorKey: errors.New("failed"),
+	}
+	before := logrus.Fields{}
+	for k, v := range data {
+		before[k] = v
+	}
+	if err := hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "request", Data: data}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(data, before) {
+		t.Errorf("entry data changed to %v", data)
+	}
+	if len(fwd.docs) != 1 || !strings.Contains(string(fwd.docs[0].Body), `"origin.file"`) {
+		t.Errorf("expected a document with the caller, got %v", fwd.docs)
+	}
+}
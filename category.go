@@ -0,0 +1,119 @@
+package elogrus
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// ECS event categorization fields
+const (
+	EventKindField     = "event.kind"
+	EventCategoryField = "event.category"
+	EventTypeField     = "event.type"
+)
+
+var (
+	// Fired if an event categorization
+	// value is not one ECS allows
+	ErrEventCategory = fmt.Errorf("Unknown ECS event categorization value")
+)
+
+// ecsEventValues holds the values ECS allows
+// for each categorization field, SIEM rules
+// ignore events with other values
+var ecsEventValues = map[string][]string{
+	EventKindField: {"alert", "enrichment", "event", "metric", "state", "pipeline_error", "signal"},
+	EventCategoryField: {"api", "authentication", "configuration", "database", "driver", "email", "file",
+		"host", "iam", "intrusion_detection", "library", "malware", "network", "package", "process",
+		"registry", "session", "threat", "vulnerability", "web"},
+	EventTypeField: {"access", "admin", "allowed", "change", "connection", "creation", "deletion", "denied",
+		"end", "error", "group", "indicator", "info", "installation", "protocol", "start", "user"},
+}
+
+// EventCategory holds the ECS categorization
+// of an event, e.g. kind "event", category
+// "authentication" and type "start"
+type EventCategory struct {
+	Kind     string
+	Category []string
+	Type     []string
+}
+
+// Fields returns the categorization as entry
+// fields, to override the hook default with
+// log.WithFields(c.Fields())
+func (c EventCategory) Fields() logrus.Fields {
+	fields := logrus.Fields{}
+	if c.Kind != "" {
+		fields[EventKindField] = c.Kind
+	}
+	if len(c.Category) > 0 {
+		fields[EventCategoryField] = c.Category
+	}
+	if len(c.Type) > 0 {
+		fields[EventTypeField] = c.Type
+	}
+	return fields
+}
+
+// validate checks every value
+// against the ECS allowed values
+func (c EventCategory) validate() error {
+	values := map[string][]string{
+		EventCategoryField: c.Category,
+		EventTypeField:     c.Type,
+	}
+	if c.Kind != "" {
+		values[EventKindField] = []string{c.Kind}
+	}
+	for field, vs := range values {
+		for _, v := range vs {
+			if !allowedEventValue(field, v) {
+				return fmt.Errorf("%w: %s %q", ErrEventCategory, field, v)
+			}
+		}
+	}
+	return nil
+}
+
+func allowedEventValue(field, value string) bool {
+	for _, allowed := range ecsEventValues[field] {
+		if value == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// WithEventCategory stamps every document with
+// the ECS event.kind, event.category and event.type
+// of c, so SIEM tools consume the logs without an
+// enrichment pipeline. Entries carrying one of the
+// fields, see EventCategory.Fields, override it.
+func WithEventCategory(c EventCategory) Option {
+	return func(hook *ElasticHook) {
+		if err := c.validate(); err != nil {
+			hook.optionErr(err)
+			return
+		}
+		hook.eventCategory = &c
+	}
+}
+
+// addEventCategory sets the categorization
+// fields of entry, or the defaults, on doc
+func (hook *ElasticHook) addEventCategory(doc map[string]interface{}, entry *logrus.Entry) {
+	c := hook.eventCategory
+	if c == nil {
+		return
+	}
+	defaults := c.Fields()
+	for _, name := range []string{EventKindField, EventCategoryField, EventTypeField} {
+		if v, ok := entry.Data[name]; ok {
+			doc[name] = v
+		} else if v, ok := defaults[name]; ok {
+			doc[name] = v
+		}
+	}
+}
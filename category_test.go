@@ -0,0 +1,37 @@
+package elogrus
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestEventCategory(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithEventCategory(EventCategory{Kind: "event", Category: []string{"web"}}))
+
+	doc := hook.document(&logrus.Entry{Data: logrus.Fields{}})
+	if doc[EventKindField] != "event" || !reflect.DeepEqual(doc[EventCategoryField], []string{"web"}) {
+		t.Errorf("expected the defaults, got %v", doc)
+	}
+	if _, ok := doc[EventTypeField]; ok {
+		t.Error("unset fields must not be stamped")
+	}
+
+	login := EventCategory{Kind: "event", Category: []string{"authentication"}, Type: []string{"start"}}
+	doc = hook.document(&logrus.Entry{Data: login.Fields()})
+	if !reflect.DeepEqual(doc[EventCategoryField], []string{"authentication"}) ||
+		!reflect.DeepEqual(doc[EventTypeField], []string{"start"}) {
+		t.Errorf("expected the entry categorization, got %v", doc)
+	}
+}
+
+func TestEventCategoryRejectsUnknownValues(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithEventCategory(EventCategory{Kind: "event", Category: []string{"login"}}))
+	if !errors.Is(hook.err, ErrEventCategory) {
+		t.Errorf("expected ErrEventCategory, got %v", hook.err)
+	}
+}
@@ -0,0 +1,111 @@
+package elogrus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// ChainLink is a hook run by a Chain
+type ChainLink struct {
+	Hook logrus.Hook
+	// Priority orders the links,
+	// lower values run first
+	Priority int
+	// StopOnError skips the following
+	// links when this one fails
+	StopOnError bool
+}
+
+// Chain is a logrus hook running other hooks,
+// e.g. an ElasticHook and a fallback hook, in
+// priority order. Each link is isolated: its
+// error or panic is collected and, unless it
+// stops the chain, the next link still runs.
+type Chain struct {
+	links  []ChainLink
+	levels []logrus.Level
+}
+
+// ChainError holds the errors
+// of the failed links
+type ChainError struct {
+	Errors []error
+}
+
+func (e *ChainError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d hooks failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// NewChain creates new chain of links,
+// links of equal priority keep their order
+func NewChain(links ...ChainLink) *Chain {
+	c := &Chain{links: append([]ChainLink(nil), links...)}
+	sort.SliceStable(c.links, func(i, j int) bool {
+		return c.links[i].Priority < c.links[j].Priority
+	})
+	seen := map[logrus.Level]bool{}
+	for _, link := range c.links {
+		for _, l := range link.Hook.Levels() {
+			if !seen[l] {
+				seen[l] = true
+				c.levels = append(c.levels, l)
+			}
+		}
+	}
+	return c
+}
+
+// Levels is required to implement
+// Logrus hook, it returns the levels
+// of all links
+func (c *Chain) Levels() []logrus.Level {
+	return c.levels
+}
+
+// Fire is required to implement
+// Logrus hook
+func (c *Chain) Fire(entry *logrus.Entry) error {
+	var errs []error
+	for _, link := range c.links {
+		if !hasLevel(link.Hook.Levels(), entry.Level) {
+			continue
+		}
+		if err := fireLink(link.Hook, entry); err != nil {
+			errs = append(errs, err)
+			if link.StopOnError {
+				break
+			}
+		}
+	}
+	if errs != nil {
+		return &ChainError{Errors: errs}
+	}
+	return nil
+}
+
+// fireLink fires hook,
+// recovering its panics
+func fireLink(hook logrus.Hook, entry *logrus.Entry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Hook panicked: %v", r)
+		}
+	}()
+	return hook.Fire(entry)
+}
+
+func hasLevel(levels []logrus.Level, level logrus.Level) bool {
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package elogrus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type testHook struct {
+	name   string
+	levels []logrus.Level
+	fire   func() error
+	calls  *[]string
+}
+
+func (h testHook) Levels() []logrus.Level { return h.levels }
+
+func (h testHook) Fire(*logrus.Entry) error {
+	*h.calls = append(*h.calls, h.name)
+	return h.fire()
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	all := []logrus.Level{logrus.ErrorLevel, logrus.InfoLevel}
+	ok := func() error { return nil }
+	chain := NewChain(
+		ChainLink{Hook: testHook{"last", all, ok, &calls}, Priority: 10},
+		ChainLink{Hook: testHook{"panics", all, func() error { panic("boom") }, &calls}},
+		ChainLink{Hook: testHook{"errors", []logrus.Level{logrus.ErrorLevel}, func() error { return errors.New("down") }, &calls}, StopOnError: true},
+	)
+
+	err := chain.Fire(&logrus.Entry{Level: logrus.InfoLevel})
+	if len(calls) != 2 || calls[0] != "panics" || calls[1] != "last" {
+		t.Errorf("unexpected calls %v", calls)
+	}
+	if cerr, isChain := err.(*ChainError); !isChain || len(cerr.Errors) != 1 {
+		t.Errorf("expected the panic to be isolated, got %v", err)
+	}
+
+	calls = nil
+	err = chain.Fire(&logrus.Entry{Level: logrus.ErrorLevel})
+	if len(calls) != 2 || calls[1] != "errors" {
+		t.Errorf("expected the failing link to stop the chain, got %v", calls)
+	}
+	if cerr, isChain := err.(*ChainError); !isChain || len(cerr.Errors) != 2 {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(chain.Levels()) != 2 {
+		t.Errorf("expected the levels of all links, got %v", chain.Levels())
+	}
+}
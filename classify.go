@@ -0,0 +1,72 @@
+package elogrus
+
+import "github.com/Sirupsen/logrus"
+
+// Classification is the verdict
+// of a Classifier on an entry
+type Classification struct {
+	// Fields tag the document, e.g. noise=true
+	// or category=timeout, replacing entry data
+	// of the same name. Index routers see them.
+	Fields logrus.Fields
+	// Drop drops the entry,
+	// e.g. as known noise
+	Drop bool
+	// Keep exempts the
+	// entry from sampling
+	Keep bool
+}
+
+// Classifier is called for every entry before it is
+// routed and sampled, to plug rules or ML based noise
+// handling into the hook. It must be safe for
+// concurrent use and must not modify the entry.
+type Classifier interface {
+	Classify(entry *logrus.Entry) Classification
+}
+
+// ClassifierFunc adapts a
+// func to Classifier
+type ClassifierFunc func(entry *logrus.Entry) Classification
+
+// Classify is required to
+// implement Classifier
+func (f ClassifierFunc) Classify(entry *logrus.Entry) Classification {
+	return f(entry)
+}
+
+// WithClassifier adds classifiers, called in order;
+// each sees the fields tagged by the previous ones
+func WithClassifier(classifiers ...Classifier) Option {
+	return func(hook *ElasticHook) {
+		hook.classifiers = append(hook.classifiers, classifiers...)
+	}
+}
+
+// classify applies the classifiers, it
+// returns entry with the tagged fields
+// and the combined verdict
+func (hook *ElasticHook) classify(entry *logrus.Entry) (*logrus.Entry, Classification) {
+	var verdict Classification
+	for _, c := range hook.classifiers {
+		v := c.Classify(entry)
+		if v.Drop {
+			return entry, Classification{Drop: true}
+		}
+		verdict.Keep = verdict.Keep || v.Keep
+		if len(v.Fields) == 0 {
+			continue
+		}
+		data := make(logrus.Fields, len(entry.Data)+len(v.Fields))
+		for k, val := range entry.Data {
+			data[k] = val
+		}
+		for k, val := range v.Fields {
+			data[k] = val
+		}
+		tagged := *entry
+		tagged.Data = data
+		entry = &tagged
+	}
+	return entry, verdict
+}
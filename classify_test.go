@@ -0,0 +1,48 @@
+package elogrus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestClassifier(t *testing.T) {
+	noise := ClassifierFunc(func(entry *logrus.Entry) Classification {
+		switch {
+		case strings.Contains(entry.Message, "healthz"):
+			return Classification{Drop: true}
+		case strings.Contains(entry.Message, "timeout"):
+			return Classification{Fields: logrus.Fields{"category": "timeout"}, Keep: true}
+		}
+		return Classification{}
+	})
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithSampling(SamplingConfig{Every: map[logrus.Level]int{logrus.InfoLevel: 100}}),
+		WithClassifier(noise),
+		WithIndexRouter(FieldRouter("category", "test-")),
+	)
+
+	entry := &logrus.Entry{Level: logrus.InfoLevel, Message: "upstream timeout", Data: logrus.Fields{"user": "joe"}}
+	for i := 0; i < 2; i++ {
+		hook.Fire(entry)
+	}
+	hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "GET /healthz", Data: logrus.Fields{}})
+
+	items := hook.bulk.take()
+	if len(items) != 2 {
+		t.Fatalf("expected both timeouts to be kept past sampling, got %d", len(items))
+	}
+	if items[0].index != "test-timeout" {
+		t.Errorf("expected routing on the tag, got %s", items[0].index)
+	}
+	data := items[0].doc.(map[string]interface{})["Data"].(logrus.Fields)
+	if data["category"] != "timeout" || data["user"] != "joe" {
+		t.Errorf("unexpected data %v", data)
+	}
+	if _, ok := entry.Data["category"]; ok {
+		t.Error("the entry must not be modified")
+	}
+}
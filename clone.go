@@ -0,0 +1,161 @@
+package elogrus
+
+import (
+	"sync/atomic"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Clone derives a hook sharing the client and
+// shipping engine of hook: its breaker, in-flight
+// limit, bulk or async queue, spool, clusters,
+// health throttle, local-only mode, gap reports,
+// SLO and statistics. Its documents join the audit
+// chain of hook unless WithAuditChain starts one. opts
+// change the rest, e.g. WithIndex, WithLevels or
+// WithStaticFields for an audit hook next to the
+// application hook. Options of the engine and of
+// background work (bulk, warm-up, retention, index
+// templates, checks) are ignored. Close and
+// Shutdown of a clone only flush; the engine stops
+// with the hook it was cloned from.
+func (hook *ElasticHook) Clone(opts ...Option) *ElasticHook {
+	root := hook.root()
+	c := &ElasticHook{
+		parent:         root,
+		parentCtx:      root.parentCtx,
+		ctx:            root.ctx,
+		cancel:         root.cancel,
+		controlCtx:     root.controlCtx,
+		controlCancel:  root.controlCancel,
+		typ:            root.typ,
+		typeless:       root.typeless,
+		dataStream:     hook.dataStream,
+		timeouts:       root.timeouts,
+		requestTimeout: root.requestTimeout,
+
+		entryCancellation: root.entryCancellation,
+		quit:              root.quit,
+		err:               root.err,
+
+		host:   hook.host,
+		index:  hook.index,
+		levels: atomic.LoadUint32(&hook.levels),
+
+		levelField:       hook.levelField,
+		timestampField:   hook.timestampField,
+		timestampFormat:  hook.timestampFormat,
+		severityField:    hook.severityField,
+		levelValueField:  hook.levelValueField,
+		correlationField: hook.correlationField,
+		fieldTypes:       hook.fieldTypes,
+		quarantineSuffix: hook.quarantineSuffix,
+		strictFields:     hook.strictFields,
+		filters:          append([]func(*logrus.Entry) bool(nil), hook.filters...),
+		schema:           hook.schema,
+		schemaConfig:     hook.schemaConfig,
+		goroutineInfo:    hook.goroutineInfo,
+		eventSequence:    hook.eventSequence,
+		enrichment:       hook.enrichment,
+		static:           hook.static,
+		originNamespace:  hook.originNamespace,
+		extraFields:      append([]extraField(nil), hook.extraFields...),
+		messageTemplate:  hook.messageTemplate,
+		ecs:              hook.ecs,
+		filebeat:         hook.filebeat,
+		errorDetails:     hook.errorDetails,
+		errorStack:       hook.errorStack,
+		eventCategory:    hook.eventCategory,
+		caller:           hook.caller,
+		batchIDs:         root.batchIDs,
+		createMappings:   hook.createMappings,
+		pipeline:         hook.pipeline,
+		customMappings:   hook.customMappings,
+		pii:              hook.pii,
+		fieldFilters:     append([]FieldFilter(nil), hook.fieldFilters...),
+		fieldLimits:      hook.fieldLimits,
+		docLimit:         hook.docLimit,
+		routingFunc:      hook.routingFunc,
+		flattener:        hook.flattener,
+		geoPoints:        append([]GeoConfig(nil), hook.geoPoints...),
+		messageFunc:      hook.messageFunc,
+		userAgentField:   hook.userAgentField,
+		retry:            hook.retry,
+		onDiscard:        hook.onDiscard,
+		errorHandler:     hook.errorHandler,
+		forwarder:        hook.forwarder,
+		instanceID:       hook.instanceID,
+		maxMessage:       hook.maxMessage,
+		overflow:         hook.overflow,
+
+		flushLevel:   hook.flushLevel,
+		flushOnLevel: hook.flushOnLevel,
+
+		router:        hook.router,
+		indices:       root.indices,
+		pattern:       hook.pattern,
+		patternSource: hook.patternSource,
+		quota:         hook.quota,
+		budget:        hook.budget,
+		sampler:       hook.sampler,
+		alertRules:    hook.alertRules,
+		classifiers:   append([]Classifier(nil), hook.classifiers...),
+		staticFields:  hook.staticFields,
+		sizeWarnings:  hook.sizeWarnings,
+		idFunc:        hook.idFunc,
+		writeOnly:     hook.writeOnly,
+
+		internalLogger: hook.internalLogger,
+		deliveryTrace:  hook.deliveryTrace,
+		localOutput:    hook.localOutput,
+		residue:        hook.residue,
+		stopTimeout:    hook.stopTimeout,
+		fatal:          hook.fatal,
+		audit:          hook.audit,
+		onClusterEvent: hook.onClusterEvent,
+
+		fallbacks:    append([]fallbackRoute(nil), hook.fallbacks...),
+		alerts:       hook.alerts,
+		observer:     hook.observer,
+		metricLabels: hook.metricLabels,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// the engine and background
+	// work stay with the root
+	c.client = nil
+	c.clientFunc = nil
+	c.breaker = root.breaker
+	c.inFlight = root.inFlight
+	c.bulk = root.bulk
+	c.async = root.async
+	c.adaptive = root.adaptive
+	c.spool = root.spool
+	c.slo = root.slo
+	c.clusters = root.clusters
+	c.throttle = root.throttle
+	c.gaps = root.gaps
+	c.localOnly = root.localOnly
+	c.warmup = nil
+	c.runtimeMetrics = nil
+	c.retention = nil
+	c.lease = nil
+	c.indexTemplate = nil
+	c.selfTest = false
+	c.mappingCheck = false
+	// enrichers of the clone add
+	// to the enrichment of hook
+	c.enrich()
+	return c
+}
+
+// root returns the hook
+// owning the engine
+func (hook *ElasticHook) root() *ElasticHook {
+	if hook.parent != nil {
+		return hook.parent
+	}
+	return hook
+}
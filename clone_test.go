@@ -0,0 +1,75 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestClone(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "app",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithDocumentIDs("node"),
+	)
+	audit := hook.Clone(
+		WithIndex("audit"),
+		WithLevels(logrus.InfoLevel),
+		WithStaticFields(logrus.Fields{"kind": "audit"}),
+		WithBulk(BulkConfig{}),
+	)
+
+	if audit.bulk != hook.bulk {
+		t.Error("expected the clone to share the bulk queue")
+	}
+	if audit.index != "audit" || hook.index != "app" {
+		t.Errorf("unexpected indices %q and %q", audit.index, hook.index)
+	}
+	if len(audit.Options().Levels) != 1 || len(hook.Options().Levels) != 6 {
+		t.Errorf("unexpected levels %v and %v", audit.Options().Levels, hook.Options().Levels)
+	}
+	if data := audit.addStaticFields(&logrus.Entry{Data: logrus.Fields{}}).Data; data["kind"] != "audit" {
+		t.Errorf("expected the static field, got %v", data)
+	}
+	if data := hook.addStaticFields(&logrus.Entry{Data: logrus.Fields{}}).Data; data["kind"] != nil {
+		t.Error("clone options must not change the hook")
+	}
+	if hook.documentID(nil) == audit.documentID(nil) {
+		t.Error("expected document IDs to stay unique across clones")
+	}
+
+	if err := audit.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-hook.quit:
+		t.Fatal("closing a clone must not stop the hook")
+	default:
+	}
+	hook.Close()
+}
+
+func TestCloneSharesEngineState(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "app",
+		WithHealthThrottle(HealthThrottleConfig{}),
+		WithLocalOnly(LocalOnlyConfig{After: time.Minute}),
+		WithGapReports(GapReportConfig{}),
+		WithSLO(SLOConfig{}),
+		WithClusters(ClusterConfig{}),
+		WithAuditChain("node-1"),
+	)
+	defer hook.Close()
+	c := hook.Clone(WithIndex("audit"))
+	if c.throttle != hook.throttle || c.localOnly != hook.localOnly || c.gaps != hook.gaps || c.slo != hook.slo || c.clusters != hook.clusters {
+		t.Error("expected the clone to share the engine state")
+	}
+	if o := c.Options(); o.Throttle == nil || o.LocalOnly == nil || o.GapReports == nil || o.SLO == nil || len(o.Clusters) != 1 {
+		t.Errorf("expected the clone options to show the engine state, got %+v", o)
+	}
+	if c.audit != hook.audit {
+		t.Error("expected the clone to join the audit chain")
+	}
+	if own := hook.Clone(WithAuditChain("audit")); own.audit == hook.audit {
+		t.Error("expected WithAuditChain to start a chain for the clone")
+	}
+}
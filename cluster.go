@@ -0,0 +1,342 @@
+package elogrus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+var (
+	// Fired if a cluster of
+	// WithClusters has no client
+	ErrNoClusterClient = fmt.Errorf("Cluster client is required")
+)
+
+// PrimaryCluster is the name of
+// the cluster of the hook client
+const PrimaryCluster = "primary"
+
+// ClusterMode sets how the
+// clusters are written to
+type ClusterMode int
+
+const (
+	// Failover writes to the first
+	// reachable cluster, in order
+	Failover ClusterMode = iota
+	// Mirror writes to
+	// every cluster
+	Mirror
+)
+
+// Cluster is a cluster
+// besides the hook client
+type Cluster struct {
+	Name   string
+	Client *elastic.Client
+}
+
+// ClusterConfig configures WithClusters,
+// zero values take the defaults
+type ClusterConfig struct {
+	Mode ClusterMode
+	// Clusters follow the hook client in
+	// order of preference for Failover
+	Clusters []Cluster
+	// FailBack is how long an unreachable cluster
+	// is skipped before requests try it again,
+	// default 30 seconds
+	FailBack time.Duration
+}
+
+// ClusterStatus is the
+// health of a cluster
+type ClusterStatus struct {
+	Name string
+	// Healthy is unset from the failed request
+	// reaching no node until one succeeds, Active
+	// is set for the cluster Failover writes to
+	Healthy bool
+	Active  bool
+	// Failures counts the failed requests,
+	// LastError is the error of the last
+	Failures  int64
+	LastError error
+}
+
+// WithClusters adds clusters, e.g. a disaster recovery one.
+// Failover sends requests to the first cluster reachable as
+// of the last request, so retries and later batches go to
+// the next once one fails, and back once it recovers;
+// unreachable clusters are skipped for FailBack. Mirror
+// also sends every document to the other reachable
+// clusters; the hook client decides the outcome, and
+// mirror failures are reported, not retried. The
+// clusters are bootstrapped on first use.
+// Forwarding hooks ignore them.
+func WithClusters(config ClusterConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.FailBack <= 0 {
+			config.FailBack = 30 * time.Second
+		}
+		s := &clusterSet{
+			mode:     config.Mode,
+			failBack: config.FailBack,
+			clusters: []*cluster{{name: PrimaryCluster}},
+			now:      time.Now,
+		}
+		for i, c := range config.Clusters {
+			if c.Client == nil {
+				hook.optionErr(ErrNoClusterClient)
+				return
+			}
+			name := c.Name
+			if name == "" {
+				name = fmt.Sprintf("cluster-%d", i+1)
+			}
+			s.clusters = append(s.clusters, &cluster{name: name, client: c.Client})
+		}
+		hook.clusters = s
+	}
+}
+
+type clusterSet struct {
+	mode     ClusterMode
+	failBack time.Duration
+	// clusters start with the primary,
+	// whose client is the hook client
+	clusters []*cluster
+	now      func() time.Time
+
+	mu     sync.Mutex
+	active *cluster
+}
+
+type cluster struct {
+	name   string
+	client *elastic.Client
+
+	// bootMu guards bootstrapped,
+	// held while bootstrapping
+	bootMu       sync.Mutex
+	bootstrapped bool
+
+	// guarded by clusterSet.mu
+	downSince time.Time
+	failures  int64
+	lastErr   error
+}
+
+// candidates returns the clusters to try in order, those
+// skipped since failing last, or all if every one is
+func (s *clusterSet) candidates() []*cluster {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var up []*cluster
+	for _, c := range s.clusters {
+		if c.downSince.IsZero() || now.Sub(c.downSince) >= s.failBack {
+			up = append(up, c)
+		}
+	}
+	if len(up) == 0 {
+		return s.clusters
+	}
+	return up
+}
+
+// record tracks the outcome of a request to c
+func (s *clusterSet) record(c *cluster, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !unreachable(err) {
+		c.downSince = time.Time{}
+		return
+	}
+	c.failures++
+	c.lastErr = err
+	c.downSince = s.now()
+}
+
+// activate makes c the cluster
+// requests go to, warning on change
+func (s *clusterSet) activate(hook *ElasticHook, c *cluster) {
+	s.mu.Lock()
+	prev := s.active
+	s.active = c
+	s.mu.Unlock()
+	if prev == nil || prev == c {
+		return
+	}
+	if c == s.clusters[0] {
+		hook.warn(fmt.Sprintf("Failing back to ElasticSearch cluster %s", c.name))
+		return
+	}
+	hook.warn(fmt.Sprintf("ElasticSearch cluster %s unreachable, failing over to %s", prev.name, c.name))
+}
+
+// find returns the cluster of client,
+// the primary for the hook client
+func (s *clusterSet) find(client *elastic.Client) *cluster {
+	for _, c := range s.clusters[1:] {
+		if c.client == client {
+			return c
+		}
+	}
+	return s.clusters[0]
+}
+
+// connect returns the client of c,
+// bootstrapped on first use
+func (hook *ElasticHook) connect(c *cluster) (*elastic.Client, error) {
+	if c.client == nil {
+		return hook.getClient()
+	}
+	c.bootMu.Lock()
+	defer c.bootMu.Unlock()
+	if !c.bootstrapped {
+		if err := hook.bootstrap(c.client); err != nil {
+			return nil, err
+		}
+		c.bootstrapped = true
+	}
+	return c.client, nil
+}
+
+// clusterClient returns the client requests go
+// to, trying the clusters in order for Failover
+func (hook *ElasticHook) clusterClient() (*elastic.Client, error) {
+	s := hook.clusters
+	if s == nil || s.mode != Failover {
+		return hook.getClient()
+	}
+	var lastErr error
+	for _, c := range s.candidates() {
+		client, err := hook.connect(c)
+		if err == nil {
+			s.activate(hook, c)
+			return client, nil
+		}
+		s.record(c, err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// failover records the failure of a request to
+// client and returns the client to retry with,
+// client itself without another reachable
+func (hook *ElasticHook) failover(client *elastic.Client, err error) *elastic.Client {
+	s := hook.root().clusters
+	if s == nil || s.mode != Failover || !unreachable(err) {
+		return client
+	}
+	s.record(s.find(client), err)
+	next, err := hook.root().clusterClient()
+	if err != nil {
+		return client
+	}
+	return next
+}
+
+// done records the outcome of a request made by
+// do with client, unless it failed over since
+func (s *clusterSet) done(client *elastic.Client, err error) {
+	c := s.find(client)
+	s.mu.Lock()
+	current := s.mode != Failover || s.active == c
+	s.mu.Unlock()
+	if current {
+		s.record(c, err)
+	}
+}
+
+// mirrors returns the clients of the reachable
+// clusters besides the primary, for Mirror
+func (hook *ElasticHook) mirrors() []*cluster {
+	s := hook.root().clusters
+	if s == nil || s.mode != Mirror {
+		return nil
+	}
+	var mirrors []*cluster
+	for _, c := range s.candidates() {
+		if c.client != nil {
+			mirrors = append(mirrors, c)
+		}
+	}
+	return mirrors
+}
+
+// mirror sends the documents of an entry
+// with ids to the mirror clusters
+func (hook *ElasticHook) mirror(ctx context.Context, index, routing string, docs []map[string]interface{}, ids []string) {
+	for _, c := range hook.mirrors() {
+		client, err := hook.root().connect(c)
+		if err == nil {
+			_, err = hook.send(ctx, client, index, routing, docs, ids)
+		}
+		hook.mirrored(c, err)
+	}
+}
+
+// mirror sends items to the
+// mirror clusters in bulk
+func (b *batcher) mirror(items []bulkItem) {
+	mirrors := b.hook.mirrors()
+	if len(mirrors) == 0 {
+		return
+	}
+	reqs := make([]elastic.BulkableRequest, 0, len(items))
+	for _, item := range items {
+		if req, _, err := item.request(b.hook.bulkType(), b.hook.static); err == nil {
+			reqs = append(reqs, req)
+		}
+	}
+	if len(reqs) == 0 {
+		return
+	}
+	for _, c := range mirrors {
+		client, err := b.hook.root().connect(c)
+		if err == nil {
+			ctx, cancel := b.hook.dataContext()
+			_, err = sendBulk(ctx, client, reqs)
+			cancel()
+		}
+		b.hook.mirrored(c, err)
+	}
+}
+
+// mirrored records the outcome of
+// a mirror request to c
+func (hook *ElasticHook) mirrored(c *cluster, err error) {
+	hook.root().clusters.record(c, err)
+	if err != nil {
+		hook.reportError(fmt.Errorf("Mirror to %s failed: %w", c.name, err))
+	}
+}
+
+// Clusters returns the health of the clusters
+// of WithClusters, the primary first, or nil
+func (hook *ElasticHook) Clusters() []ClusterStatus {
+	s := hook.root().clusters
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]ClusterStatus, 0, len(s.clusters))
+	for _, c := range s.clusters {
+		active := s.active == c || (s.active == nil && c == s.clusters[0])
+		statuses = append(statuses, ClusterStatus{
+			Name:      c.name,
+			Healthy:   c.downSince.IsZero(),
+			Active:    s.mode == Failover && active,
+			Failures:  c.failures,
+			LastError: c.lastErr,
+		})
+	}
+	return statuses
+}
@@ -0,0 +1,63 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestClustersFailover(t *testing.T) {
+	primary, dr := &elastic.Client{}, &elastic.Client{}
+	hook := newHook(primary, "localhost", logrus.DebugLevel, "test",
+		WithClusters(ClusterConfig{Clusters: []Cluster{{Name: "dr", Client: dr}}, FailBack: time.Minute}))
+	s := hook.clusters
+	s.clusters[1].bootstrapped = true
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	if client, err := hook.clusterClient(); err != nil || client != primary {
+		t.Fatalf("expected the primary, got %v", err)
+	}
+	if next := hook.failover(primary, elastic.ErrNoClient); next != dr {
+		t.Fatal("expected to fail over to dr")
+	}
+	statuses := hook.Clusters()
+	if statuses[0].Healthy || statuses[0].Failures != 1 || !statuses[1].Active {
+		t.Errorf("unexpected statuses %+v", statuses)
+	}
+	// a late outcome of the primary
+	// does not mark it healthy
+	s.done(primary, nil)
+	if hook.Clusters()[0].Healthy {
+		t.Error("expected the primary to stay unhealthy")
+	}
+
+	now = now.Add(time.Minute)
+	if client, _ := hook.clusterClient(); client != primary {
+		t.Fatal("expected to fail back to the primary")
+	}
+	s.done(primary, nil)
+	if statuses := hook.Clusters(); !statuses[0].Healthy || !statuses[0].Active {
+		t.Errorf("unexpected statuses %+v", statuses)
+	}
+}
+
+func TestClustersMirror(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithClusters(ClusterConfig{Mode: Mirror, Clusters: []Cluster{{Client: &elastic.Client{}}}}))
+	mirrors := hook.mirrors()
+	if len(mirrors) != 1 || mirrors[0].name != "cluster-1" {
+		t.Fatalf("unexpected mirrors %v", mirrors)
+	}
+	hook.mirrored(mirrors[0], elastic.ErrNoClient)
+	if len(hook.mirrors()) != 0 || hook.Clusters()[1].Healthy {
+		t.Error("expected the unreachable mirror to be skipped")
+	}
+
+	hook = newHook(nil, "localhost", logrus.DebugLevel, "test", WithClusters(ClusterConfig{Clusters: []Cluster{{Name: "dr"}}}))
+	if hook.err != ErrNoClusterClient {
+		t.Errorf("expected ErrNoClusterClient, got %v", hook.err)
+	}
+}
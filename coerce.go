@@ -0,0 +1,156 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// FieldType is the type
+// expected for a field
+type FieldType int
+
+const (
+	// StringField accepts any value,
+	// formatted as a string
+	StringField FieldType = iota
+	// IntField accepts integers, integral
+	// floats and numeric strings
+	IntField
+	// FloatField accepts numbers
+	// and numeric strings
+	FloatField
+	// BoolField accepts booleans and
+	// strings like "true" or "0"
+	BoolField
+)
+
+// WithFieldTypes declares the expected type of
+// fields. Values are converted to that type, so
+// the first document to use a field does not pick
+// a mapping that rejects later ones. Values which
+// cannot be converted are left out, or moved to a
+// quarantine field, see WithQuarantineSuffix.
+func WithFieldTypes(types map[string]FieldType) Option {
+	return func(hook *ElasticHook) {
+		hook.fieldTypes = types
+	}
+}
+
+// WithQuarantineSuffix keeps values which cannot be
+// converted to their declared type as a string in
+// the field name plus suffix, e.g. "user_id__raw"
+// for the suffix "__raw", instead of leaving them out
+func WithQuarantineSuffix(suffix string) Option {
+	return func(hook *ElasticHook) {
+		hook.quarantineSuffix = suffix
+	}
+}
+
+// coerceFields converts the
+// declared fields of data
+func (hook *ElasticHook) coerceFields(data logrus.Fields) {
+	for k, typ := range hook.fieldTypes {
+		v, ok := data[k]
+		if !ok || v == nil {
+			continue
+		}
+		if c, ok := coerce(v, typ); ok {
+			data[k] = c
+			continue
+		}
+		delete(data, k)
+		if hook.quarantineSuffix != "" {
+			data[k+hook.quarantineSuffix] = coerceString(v)
+		}
+	}
+}
+
+// coerce converts v to typ
+func coerce(v interface{}, typ FieldType) (interface{}, bool) {
+	switch typ {
+	case StringField:
+		return coerceString(v), true
+	case IntField:
+		f, ok := toFloat(v)
+		if !ok || f != math.Trunc(f) || math.IsInf(f, 0) {
+			return nil, false
+		}
+		if s, isString := v.(string); isString {
+			// keep precision of large integers
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, true
+			}
+		}
+		if i, isInt := toInt(v); isInt {
+			return i, true
+		}
+		return int64(f), true
+	case FloatField:
+		return toFloat(v)
+	case BoolField:
+		switch b := v.(type) {
+		case bool:
+			return b, true
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			return parsed, err == nil
+		}
+	}
+	return nil, false
+}
+
+func coerceString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case error:
+		return s.Error()
+	case fmt.Stringer:
+		return s.String()
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Ptr:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+func toInt(v interface{}) (int64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return int64(u), true
+		}
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	if i, ok := toInt(v); ok {
+		return float64(i), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		return f, !math.IsNaN(f) && !math.IsInf(f, 0)
+	case reflect.String:
+		f, err := strconv.ParseFloat(rv.String(), 64)
+		return f, err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+	}
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
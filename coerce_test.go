@@ -0,0 +1,69 @@
+package elogrus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestCoerce(t *testing.T) {
+	cases := []struct {
+		in   interface{}
+		typ  FieldType
+		want interface{}
+		ok   bool
+	}{
+		{42, StringField, "42", true},
+		{errors.New("boom"), StringField, "boom", true},
+		{map[string]int{"a": 1}, StringField, `{"a":1}`, true},
+		{"17", IntField, int64(17), true},
+		{3.0, IntField, int64(3), true},
+		{3.5, IntField, nil, false},
+		{"abc", IntField, nil, false},
+		{uint8(7), FloatField, float64(7), true},
+		{"1.25", FloatField, 1.25, true},
+		{true, FloatField, nil, false},
+		{"true", BoolField, true, true},
+		{1, BoolField, nil, false},
+	}
+	for _, c := range cases {
+		got, ok := coerce(c.in, c.typ)
+		if ok != c.ok || (ok && got != c.want) {
+			t.Errorf("coerce(%#v, %d) = %#v, %v; want %#v, %v", c.in, c.typ, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestCoerceFieldsLeavesEntryAlone(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithFieldTypes(map[string]FieldType{"user_id": StringField, "duration_ms": FloatField}),
+	)
+	entry := &logrus.Entry{Data: logrus.Fields{"user_id": 12, "duration_ms": "slow"}}
+
+	data := hook.fields(entry)
+	if data["user_id"] != "12" {
+		t.Errorf("expected user_id as string, got %#v", data["user_id"])
+	}
+	if _, ok := data["duration_ms"]; ok {
+		t.Error("value which cannot be converted should be left out")
+	}
+	if entry.Data["user_id"] != 12 {
+		t.Error("entry data must not be modified")
+	}
+}
+
+func TestQuarantineSuffix(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithFieldTypes(map[string]FieldType{"user_id": IntField}),
+		WithQuarantineSuffix("__raw"),
+	)
+
+	data := hook.fields(&logrus.Entry{Data: logrus.Fields{"user_id": "joe"}})
+	if _, ok := data["user_id"]; ok {
+		t.Error("mismatched value must not stay in the typed field")
+	}
+	if data["user_id__raw"] != "joe" {
+		t.Errorf("expected value in quarantine field, got %#v", data["user_id__raw"])
+	}
+}
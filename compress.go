@@ -0,0 +1,145 @@
+package elogrus
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// CompressTransport is a http.RoundTripper gzipping
+// request bodies of at least Threshold bytes, small
+// single document requests are sent as is since
+// compressing them costs more CPU than it saves.
+// ElasticSearch needs http.compression enabled.
+// Install it with SetCompression or
+// Config.Compression.
+type CompressTransport struct {
+	// stats is first to be 64-bit
+	// aligned for atomic access
+	stats CompressStats
+
+	// Transport used to send the request,
+	// http.DefaultTransport when nil
+	Transport http.RoundTripper
+	// Threshold is the smallest body
+	// compressed, default 1024 bytes
+	Threshold int
+}
+
+// CompressStats counts the request
+// bodies sent by a CompressTransport
+type CompressStats struct {
+	// CompressedRequests had their body
+	// compressed from CompressedBytesIn
+	// to CompressedBytesOut bytes
+	CompressedRequests int64
+	CompressedBytesIn  int64
+	CompressedBytesOut int64
+	// PlainRequests were below the threshold
+	// and sent with PlainBytes bytes
+	PlainRequests int64
+	PlainBytes    int64
+}
+
+// Stats returns the
+// counters so far
+func (t *CompressTransport) Stats() CompressStats {
+	return CompressStats{
+		CompressedRequests: atomic.LoadInt64(&t.stats.CompressedRequests),
+		CompressedBytesIn:  atomic.LoadInt64(&t.stats.CompressedBytesIn),
+		CompressedBytesOut: atomic.LoadInt64(&t.stats.CompressedBytesOut),
+		PlainRequests:      atomic.LoadInt64(&t.stats.PlainRequests),
+		PlainBytes:         atomic.LoadInt64(&t.stats.PlainBytes),
+	}
+}
+
+// RoundTrip is required to implement
+// http.RoundTripper
+func (t *CompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.roundTrip(req, t.transport())
+}
+
+// roundTrip compresses req and sends it through next
+func (t *CompressTransport) roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if req.Body == nil || req.Header.Get("Content-Encoding") != "" {
+		return next.RoundTrip(req)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = cloneRequest(req)
+	threshold := t.Threshold
+	if threshold <= 0 {
+		threshold = 1024
+	}
+	if len(body) < threshold {
+		atomic.AddInt64(&t.stats.PlainRequests, 1)
+		atomic.AddInt64(&t.stats.PlainBytes, int64(len(body)))
+		setBody(req, body)
+		return next.RoundTrip(req)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&t.stats.CompressedRequests, 1)
+	atomic.AddInt64(&t.stats.CompressedBytesIn, int64(len(body)))
+	atomic.AddInt64(&t.stats.CompressedBytesOut, int64(buf.Len()))
+	req.Header.Set("Content-Encoding", "gzip")
+	setBody(req, buf.Bytes())
+	return next.RoundTrip(req)
+}
+
+// compressVia sends the requests of a CompressTransport
+// through next, counting them in its stats, so
+// Config does not set the Transport of the caller
+type compressVia struct {
+	t    *CompressTransport
+	next http.RoundTripper
+}
+
+// RoundTrip is required to
+// implement http.RoundTripper
+func (c compressVia) RoundTrip(req *http.Request) (*http.Response, error) {
+	return c.t.roundTrip(req, c.next)
+}
+
+// SetCompression is a client option sending the
+// requests of the ElasticSearch client through t,
+// e.g. for NewClient, SharedClient or a ClientFunc.
+// It replaces the HTTP client, and so the TLS
+// settings, headers and timeout of other options;
+// use Config.Compression with a Config.
+func SetCompression(t *CompressTransport) elastic.ClientOptionFunc {
+	return elastic.SetHttpClient(&http.Client{Transport: t})
+}
+
+func (t *CompressTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+// setBody replaces the body of req
+func setBody(req *http.Request, body []byte) {
+	req.ContentLength = int64(len(body))
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+}
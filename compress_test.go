@@ -0,0 +1,51 @@
+package elogrus
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressTransport(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Error(err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		b, _ := ioutil.ReadAll(body)
+		bodies = append(bodies, r.Header.Get("Content-Encoding")+":"+string(b))
+	}))
+	defer srv.Close()
+
+	transport := &CompressTransport{Threshold: 100}
+	client := &http.Client{Transport: transport}
+	large := strings.Repeat(`{"Message":"hello"}`, 20)
+	for _, body := range []string{`{"Message":"hi"}`, large} {
+		resp, err := client.Post(srv.URL+"/_bulk", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(bodies) != 2 || bodies[0] != `:{"Message":"hi"}` || bodies[1] != "gzip:"+large {
+		t.Errorf("unexpected bodies %q", bodies)
+	}
+	stats := transport.Stats()
+	if stats.PlainRequests != 1 || stats.PlainBytes != 16 || stats.CompressedRequests != 1 || stats.CompressedBytesIn != int64(len(large)) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.CompressedBytesOut <= 0 || stats.CompressedBytesOut >= stats.CompressedBytesIn {
+		t.Errorf("expected compression to shrink the body, got %+v", stats)
+	}
+}
@@ -0,0 +1,193 @@
+package elogrus
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+var (
+	// Fired if a Config sets both
+	// basic auth and an API key
+	ErrAuthConflict = fmt.Errorf("Either basic auth or an API key can be set")
+	// Fired if the CA certificate
+	// of a Config holds no PEM
+	ErrNoCACert = fmt.Errorf("No certificate found in CA file")
+)
+
+// Config describes the connection to ElasticSearch,
+// e.g. read from a config file or ConfigFromEnv.
+// Zero values take the defaults.
+type Config struct {
+	// URLs default to http://localhost:9200
+	URLs []string
+	// Username and Password enable basic
+	// authentication, APIKey the
+	// "ApiKey" authorization instead
+	Username string
+	Password string
+	APIKey   string
+	// CACert is a PEM file with the certificates
+	// trusted for the cluster, ClientCert and
+	// ClientKey PEM files for mutual TLS
+	CACert             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	// Sniff discovers the other nodes, which
+	// fails behind proxies and in containers
+	Sniff              bool
+	DisableHealthcheck bool
+	// Timeout bounds every request,
+	// default 30 seconds
+	Timeout time.Duration
+	// Header is sent with every request
+	Header http.Header
+	// Compression gzips the requests, its
+	// Transport defaults to the one of the
+	// TLS settings and headers
+	Compression *CompressTransport
+	// Index is the index of the hook,
+	// as with WithIndex, when set
+	Index string
+	// Options are passed on to
+	// elastic.NewClient last
+	Options []elastic.ClientOptionFunc
+}
+
+// ConfigFromEnv reads a Config from ELASTICSEARCH_URL,
+// with comma separated URLs, ELASTICSEARCH_USERNAME,
+// ELASTICSEARCH_PASSWORD, ELASTICSEARCH_API_KEY,
+// ELASTICSEARCH_CA_CERT, ELASTICSEARCH_CLIENT_CERT,
+// ELASTICSEARCH_CLIENT_KEY, ELASTICSEARCH_INSECURE,
+// ELASTICSEARCH_SNIFF and ELASTICSEARCH_INDEX
+func ConfigFromEnv() Config {
+	c := Config{
+		Username:   os.Getenv("ELASTICSEARCH_USERNAME"),
+		Password:   os.Getenv("ELASTICSEARCH_PASSWORD"),
+		APIKey:     os.Getenv("ELASTICSEARCH_API_KEY"),
+		CACert:     os.Getenv("ELASTICSEARCH_CA_CERT"),
+		ClientCert: os.Getenv("ELASTICSEARCH_CLIENT_CERT"),
+		ClientKey:  os.Getenv("ELASTICSEARCH_CLIENT_KEY"),
+		Index:      os.Getenv("ELASTICSEARCH_INDEX"),
+	}
+	for _, u := range strings.Split(os.Getenv("ELASTICSEARCH_URL"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			c.URLs = append(c.URLs, u)
+		}
+	}
+	c.InsecureSkipVerify, _ = strconv.ParseBool(os.Getenv("ELASTICSEARCH_INSECURE"))
+	c.Sniff, _ = strconv.ParseBool(os.Getenv("ELASTICSEARCH_SNIFF"))
+	return c
+}
+
+// NewElasticHookFromConfig creates a hook with a client
+// built from config; as with New, the host defaults to
+// the hostname and WithIndex is required unless
+// config sets the index
+func NewElasticHookFromConfig(config Config, opts ...Option) (*ElasticHook, error) {
+	client, err := config.Client()
+	if err != nil {
+		return nil, err
+	}
+	if config.Index != "" {
+		opts = append([]Option{WithIndex(config.Index)}, opts...)
+	}
+	hook, err := New(client, opts...)
+	if err != nil {
+		client.Stop()
+		return nil, err
+	}
+	return hook, nil
+}
+
+// Client creates the client of config,
+// it is a ClientFunc for lazy hooks
+func (c Config) Client() (*elastic.Client, error) {
+	if c.APIKey != "" && (c.Username != "" || c.Password != "") {
+		return nil, ErrAuthConflict
+	}
+	httpClient, err := c.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	urls := c.URLs
+	if len(urls) == 0 {
+		urls = []string{"http://localhost:9200"}
+	}
+	opts := []elastic.ClientOptionFunc{
+		elastic.SetURL(urls...),
+		elastic.SetHttpClient(httpClient),
+		elastic.SetSniff(c.Sniff),
+		elastic.SetHealthcheck(!c.DisableHealthcheck),
+	}
+	if c.Username != "" || c.Password != "" {
+		opts = append(opts, elastic.SetBasicAuth(c.Username, c.Password))
+	}
+	return elastic.NewClient(append(opts, c.Options...)...)
+}
+
+// httpClient returns the HTTP client
+// with the TLS settings and headers
+func (c Config) httpClient() (*http.Client, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.CACert != "" || c.ClientCert != "" || c.InsecureSkipVerify {
+		tlsConfig, err := c.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	header := make(http.Header, len(c.Header)+1)
+	for k, v := range c.Header {
+		header[k] = v
+	}
+	if c.APIKey != "" {
+		header.Set("Authorization", "ApiKey "+c.APIKey)
+	}
+	var rt http.RoundTripper = transport
+	if len(header) > 0 {
+		rt = &HeaderTransport{Transport: transport, Header: header}
+	}
+	if c.Compression != nil {
+		if c.Compression.Transport != nil {
+			rt = c.Compression.Transport
+		}
+		rt = compressVia{t: c.Compression, next: rt}
+	}
+	return &http.Client{Transport: rt, Timeout: timeout}, nil
+}
+
+func (c Config) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.CACert != "" {
+		pem, err := ioutil.ReadFile(c.CACert)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, ErrNoCACert
+		}
+	}
+	if c.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
@@ -0,0 +1,97 @@
+package elogrus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("ELASTICSEARCH_URL", "https://es-1:9200, https://es-2:9200")
+	t.Setenv("ELASTICSEARCH_API_KEY", "a2V5")
+	t.Setenv("ELASTICSEARCH_SNIFF", "true")
+	t.Setenv("ELASTICSEARCH_INDEX", "myapp")
+	c := ConfigFromEnv()
+	if !reflect.DeepEqual(c.URLs, []string{"https://es-1:9200", "https://es-2:9200"}) {
+		t.Errorf("unexpected URLs %v", c.URLs)
+	}
+	if c.APIKey != "a2V5" || !c.Sniff || c.Index != "myapp" || c.InsecureSkipVerify {
+		t.Errorf("unexpected config %+v", c)
+	}
+}
+
+func TestConfigAPIKey(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	client, err := Config{APIKey: "a2V5", Header: http.Header{"X-Tenant": {"acme"}}}.httpClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if auth != "ApiKey a2V5" {
+		t.Errorf("unexpected authorization %q", auth)
+	}
+
+	if _, err := (Config{APIKey: "a2V5", Username: "elastic"}).Client(); err != ErrAuthConflict {
+		t.Errorf("expected ErrAuthConflict, got %v", err)
+	}
+}
+
+func TestConfigCACert(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := ioutil.WriteFile(path, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (Config{CACert: path}).httpClient(); err != ErrNoCACert {
+		t.Errorf("expected ErrNoCACert, got %v", err)
+	}
+	if _, err := (Config{CACert: path + ".missing"}).httpClient(); !os.IsNotExist(err) {
+		t.Errorf("expected a missing file, got %v", err)
+	}
+}
+
+func TestConfigCompression(t *testing.T) {
+	var auth, encoding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, encoding = r.Header.Get("Authorization"), r.Header.Get("Content-Encoding")
+	}))
+	defer srv.Close()
+
+	compression := &CompressTransport{Threshold: 10}
+	client, err := Config{APIKey: "a2V5", Timeout: time.Second, Compression: compression}.httpClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Timeout != time.Second {
+		t.Errorf("expected the timeout kept, got %v", client.Timeout)
+	}
+	resp, err := client.Post(srv.URL, "application/json", bytes.NewReader([]byte(strings.Repeat("x", 100))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if auth != "ApiKey a2V5" || encoding != "gzip" {
+		t.Errorf("expected a compressed request with the API key, got %q and %q", auth, encoding)
+	}
+	if compression.Stats().CompressedRequests != 1 {
+		t.Errorf("unexpected stats %+v", compression.Stats())
+	}
+	if compression.Transport != nil {
+		t.Error("the transport of the caller must not be changed")
+	}
+}
@@ -0,0 +1,51 @@
+package elogrus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type correlationKey struct{}
+
+// ContextWithCorrelationID returns a copy of ctx
+// carrying the correlation ID picked up by hooks
+// configured with WithCorrelationID
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationIDFromContext returns the
+// correlation ID stored in ctx, if any
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(correlationKey{}).(string)
+	return id, ok && id != ""
+}
+
+// WithCorrelationID stores a correlation ID under
+// field in every document. It is taken from the
+// entry field of that name, the entry context,
+// or generated as a ULID when neither has one.
+func WithCorrelationID(field string) Option {
+	return func(hook *ElasticHook) {
+		hook.correlationField = field
+	}
+}
+
+// correlationID finds or generates
+// the correlation ID of entry
+func (hook *ElasticHook) correlationID(entry *logrus.Entry) string {
+	if v, ok := entry.Data[hook.correlationField]; ok && v != nil {
+		if id := fmt.Sprint(v); id != "" {
+			return id
+		}
+	}
+	if id, ok := CorrelationIDFromContext(entry.Context); ok {
+		return id
+	}
+	return NewULID()
+}
@@ -0,0 +1,28 @@
+package elogrus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestCorrelationID(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithCorrelationID("request_id"))
+
+	doc := hook.document(&logrus.Entry{Data: logrus.Fields{"request_id": "abc"}})
+	if doc["request_id"] != "abc" {
+		t.Errorf("expected id from entry data, got %v", doc["request_id"])
+	}
+
+	ctx := ContextWithCorrelationID(context.Background(), "from-ctx")
+	doc = hook.document(&logrus.Entry{Data: logrus.Fields{}, Context: ctx})
+	if doc["request_id"] != "from-ctx" {
+		t.Errorf("expected id from context, got %v", doc["request_id"])
+	}
+
+	doc = hook.document(&logrus.Entry{Data: logrus.Fields{}})
+	if id, _ := doc["request_id"].(string); len(id) != 26 {
+		t.Errorf("expected generated ULID, got %v", doc["request_id"])
+	}
+}
@@ -0,0 +1,98 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+var (
+	// Fired if a data stream is
+	// given a legacy template
+	ErrLegacyDataStream = fmt.Errorf("Data streams require a composable index template")
+	// Fired if the index template of the data
+	// stream exists without enabling data streams
+	ErrNoDataStreamTemplate = fmt.Errorf("Index template does not enable data streams")
+)
+
+// dataStreamPriority outranks the
+// built-in logs-*-* template
+const dataStreamPriority = 200
+
+// WithDataStream writes to the data stream name
+// (ElasticSearch 7.9+) instead of an index, for ILM
+// managed logs: documents are created, typeless and
+// timestamped in @timestamp, and no index is created.
+// Without WithIndexTemplate an index template for
+// name is checked, and installed when neither it
+// nor another template matches the stream.
+func WithDataStream(name string) Option {
+	return func(hook *ElasticHook) {
+		hook.index = name
+		hook.dataStream = true
+		hook.typ = "_doc"
+		hook.typeless = true
+		hook.timestampField = "@timestamp"
+	}
+}
+
+// ensureStreamTemplate checks the index template
+// of the data stream, and installs it if missing
+func (hook *ElasticHook) ensureStreamTemplate(client *elastic.Client) error {
+	if !hook.dataStream || hook.indexTemplate != nil || hook.writeOnly {
+		return nil
+	}
+	ctx, cancel := hook.controlContext()
+	resp, err := client.PerformRequestC(ctx, "GET", "/_index_template/"+hook.index, nil, nil)
+	cancel()
+	if isStatus(err, 404) {
+		// another template, e.g. the built-in
+		// logs-*-*, may match the stream
+		if matched, err := hook.matchesTemplate(client); err != nil || matched {
+			return err
+		}
+		return hook.putTemplate(client, &TemplateConfig{
+			Name:     hook.index,
+			Patterns: []string{hook.index + "*"},
+			Priority: dataStreamPriority,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	var existing struct {
+		IndexTemplates []struct {
+			IndexTemplate struct {
+				DataStream *json.RawMessage `json:"data_stream"`
+			} `json:"index_template"`
+		} `json:"index_templates"`
+	}
+	if err := json.Unmarshal(resp.Body, &existing); err != nil {
+		return err
+	}
+	for _, t := range existing.IndexTemplates {
+		if t.IndexTemplate.DataStream == nil {
+			return ErrNoDataStreamTemplate
+		}
+	}
+	return nil
+}
+
+// matchesTemplate tells if an index template
+// of another name matches the data stream
+func (hook *ElasticHook) matchesTemplate(client *elastic.Client) (bool, error) {
+	ctx, cancel := hook.controlContext()
+	defer cancel()
+	resp, err := client.PerformRequestC(ctx, "POST", "/_index_template/_simulate_index/"+hook.index, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	var simulated struct {
+		Template *json.RawMessage `json:"template"`
+	}
+	if err := json.Unmarshal(resp.Body, &simulated); err != nil {
+		return false, err
+	}
+	return simulated.Template != nil, nil
+}
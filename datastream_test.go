@@ -0,0 +1,43 @@
+package elogrus
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestDataStream(t *testing.T) {
+	var lines []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := bufio.NewScanner(r.Body)
+		for s.Scan() {
+			lines = append(lines, s.Text())
+		}
+		w.Write([]byte(`{"errors":false,"items":[{"create":{"status":201}}]}`))
+	}))
+	defer srv.Close()
+
+	hook, err := NewOpenSearchHook(OpenSearchConfig{URL: srv.URL}, "localhost", logrus.DebugLevel, "",
+		WithDataStream("logs-myapp-default"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hook.Fire(&logrus.Entry{Message: "streamed", Data: logrus.Fields{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lines) != 2 || lines[0] != `{"create":{"_index":"logs-myapp-default"}}` {
+		t.Fatalf("unexpected bulk body %v", lines)
+	}
+	if !strings.Contains(lines[1], `"@timestamp":`) {
+		t.Errorf("expected the document to be timestamped in @timestamp, got %s", lines[1])
+	}
+	if o := hook.Options(); !o.DataStream || !o.Typeless {
+		t.Errorf("unexpected options %+v", o)
+	}
+}
@@ -0,0 +1,196 @@
+package elogrus
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+var (
+	// Fired if an entry was only written
+	// locally while shipping is suspended
+	ErrLocalOnly = fmt.Errorf("Entry written locally only")
+)
+
+// Fields of the summary document
+// sent when shipping resumes
+const (
+	GapStartField   = "gap.start"
+	GapEndField     = "gap.end"
+	GapEntriesField = "gap.entries"
+)
+
+// LocalOnlyConfig configures local-only
+// mode, zero values fall back to the defaults
+type LocalOnlyConfig struct {
+	// After is how long the cluster must fail
+	// before shipping stops, default 5 minutes
+	After time.Duration
+	// ProbeInterval is the time between two
+	// attempts to resume, default 30 seconds
+	ProbeInterval time.Duration
+}
+
+// WithLocalOnly stops shipping after the cluster failed
+// for config.After: entries are no longer queued but
+// written to the fallbacks, or the local output without
+// any. Sending a summary of the gap probes the cluster
+// periodically; once it is delivered shipping resumes.
+func WithLocalOnly(config LocalOnlyConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.After <= 0 {
+			config.After = 5 * time.Minute
+		}
+		if config.ProbeInterval <= 0 {
+			config.ProbeInterval = 30 * time.Second
+		}
+		hook.localOnly = &localOnly{config: config, now: time.Now}
+	}
+}
+
+type localOnly struct {
+	mu     sync.Mutex
+	config LocalOnlyConfig
+	// failingSince is the first of the
+	// current run of cluster failures
+	failingSince time.Time
+	// active is set in local-only mode, since
+	// gapStart, with entries written locally
+	active   bool
+	gapStart time.Time
+	entries  int64
+	now      func() time.Time
+}
+
+// record accounts a request outcome, it
+// reports whether local-only mode started
+func (l *localOnly) record(err error) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !isClusterFailure(err) {
+		l.failingSince = time.Time{}
+		return false
+	}
+	now := l.now()
+	if l.failingSince.IsZero() {
+		l.failingSince = now
+	}
+	if l.active || now.Sub(l.failingSince) < l.config.After {
+		return false
+	}
+	l.active = true
+	l.gapStart = l.failingSince
+	l.entries = 0
+	return true
+}
+
+// local counts an entry written locally,
+// it reports false when shipping is on
+func (l *localOnly) local() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active {
+		l.entries++
+	}
+	return l.active
+}
+
+// gap returns the summary of the gap so far
+func (l *localOnly) gap() (time.Time, time.Time, int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.gapStart, l.now(), l.entries
+}
+
+// resume ends local-only mode
+func (l *localOnly) resume() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active = false
+	l.failingSince = time.Time{}
+}
+
+// available records a request outcome,
+// switching to local-only mode if due
+func (hook *ElasticHook) available(err error) {
+	hook.recordHealth(err)
+	l := hook.root().localOnly
+	if l == nil || !l.record(err) {
+		return
+	}
+	hook.warn(fmt.Sprintf("ElasticSearch unavailable for %s, logging locally only: %v", l.config.After, err))
+	go hook.root().probeLocalOnly()
+}
+
+// writeLocal writes entry to the fallbacks or
+// local output in local-only mode, it reports
+// false when shipping is on
+func (hook *ElasticHook) writeLocal(entry *logrus.Entry, id uint64, done func(error)) bool {
+	l := hook.root().localOnly
+	if l == nil || !l.local() {
+		return false
+	}
+	if len(hook.fallbacks) == 0 {
+		hook.printLocal(entry)
+	}
+	docs := hook.stampedDocuments(entry, sample{})
+	for _, doc := range docs {
+		hook.fallback(entry.Level, doc)
+	}
+	hook.lost(entry.Level, len(docs))
+	hook.traceOutcome(id, ErrLocalOnly)
+	resolve(done, ErrLocalOnly)
+	return true
+}
+
+// sendNow sends docs of the hook
+// itself to the hook index, bypassing
+// queues, fallbacks and counters
+func (hook *ElasticHook) sendNow(docs []map[string]interface{}) error {
+	ctx, cancel := hook.dataContext()
+	defer cancel()
+	ids := hook.documentIDs(docs)
+	if hook.forwarder != nil {
+		_, err := hook.forward(ctx, hook.index, "", docs, ids)
+		return err
+	}
+	return hook.do(func(client *elastic.Client) error {
+		_, err := hook.send(ctx, client, hook.index, "", docs, ids)
+		return err
+	})
+}
+
+// probeLocalOnly sends the summary of the
+// gap until it is delivered, then resumes
+func (hook *ElasticHook) probeLocalOnly() {
+	l := hook.localOnly
+	ticker := time.NewTicker(l.config.ProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-hook.quit:
+			return
+		}
+		start, end, entries := l.gap()
+		docs := []map[string]interface{}{hook.document(&logrus.Entry{
+			Level:   logrus.WarnLevel,
+			Time:    end,
+			Message: fmt.Sprintf("Log shipping resumed, %d entries written locally only", entries),
+			Data: logrus.Fields{
+				GapStartField:   start.UTC().Format(time.RFC3339Nano),
+				GapEndField:     end.UTC().Format(time.RFC3339Nano),
+				GapEntriesField: entries,
+			},
+		})}
+		if hook.sendNow(docs) == nil {
+			l.resume()
+			hook.warn(fmt.Sprintf("ElasticSearch available again after %s, shipping resumed", end.Sub(start).Round(time.Second)))
+			return
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+type flakyForwarder struct {
+	mu   sync.Mutex
+	err  error
+	docs []ForwardedDocument
+}
+
+func (f *flakyForwarder) Forward(ctx context.Context, docs []ForwardedDocument) ([]error, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.docs = append(f.docs, docs...)
+	return nil, nil
+}
+
+func TestLocalOnly(t *testing.T) {
+	fwd := &flakyForwarder{err: &elastic.Error{Status: 503}}
+	var local []string
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test",
+		WithLocalOnly(LocalOnlyConfig{After: time.Minute, ProbeInterval: 10 * time.Millisecond}),
+		WithFallback(logrus.InfoLevel, FallbackFunc(func(doc json.RawMessage) error {
+			local = append(local, string(doc))
+			return nil
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	now := time.Now()
+	hook.localOnly.now = func() time.Time { return now }
+
+	hook.Fire(&logrus.Entry{Message: "first", Data: logrus.Fields{}})
+	now = now.Add(2 * time.Minute)
+	hook.Fire(&logrus.Entry{Message: "second", Data: logrus.Fields{}})
+	if !hook.Stats().LocalOnly {
+		t.Fatal("expected local-only mode after prolonged failures")
+	}
+	local = nil
+	if err := hook.Fire(&logrus.Entry{Message: "local", Data: logrus.Fields{}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(local) != 1 || !strings.Contains(local[0], `"local"`) {
+		t.Errorf("expected the entry to be written locally, got %v", local)
+	}
+
+	fwd.mu.Lock()
+	fwd.err = nil
+	fwd.mu.Unlock()
+	for deadline := time.Now().Add(time.Second); hook.Stats().LocalOnly && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	fwd.mu.Lock()
+	defer fwd.mu.Unlock()
+	if len(fwd.docs) != 1 || !strings.Contains(string(fwd.docs[0].Body), `"gap.entries":1`) {
+		t.Errorf("expected a summary of the gap, got %v", fwd.docs)
+	}
+}
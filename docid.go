@@ -0,0 +1,83 @@
+package elogrus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+)
+
+// WithDocumentIDs gives every document the ID
+// "<instance>-<sequence>" and indexes it with the
+// create operation, so sending it again, e.g. when
+// replaying unsent documents after a crash, finds
+// the existing document instead of duplicating it.
+// An empty instance ID generates a ULID.
+func WithDocumentIDs(instance string) Option {
+	return func(hook *ElasticHook) {
+		if instance == "" {
+			instance = NewULID()
+		}
+		hook.instanceID = instance
+	}
+}
+
+// WithIDFunc derives the ID of every document with
+// fn, indexing it with the create operation, so a
+// retried or replayed document is found instead of
+// duplicated; see ContentID. It takes precedence
+// over WithDocumentIDs.
+func WithIDFunc(fn func(doc map[string]interface{}) string) Option {
+	return func(hook *ElasticHook) {
+		hook.idFunc = fn
+	}
+}
+
+// WithContentIDs derives the document IDs from
+// their content, see ContentID
+func WithContentIDs() Option {
+	return WithIDFunc(ContentID)
+}
+
+// ContentID hashes the JSON of doc, which holds
+// the timestamp, message and fields, so entries
+// logged twice at the same time share an ID
+func ContentID(doc map[string]interface{}) string {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:16])
+}
+
+// documentID returns the ID of doc,
+// empty when IDs are off
+func (hook *ElasticHook) documentID(doc map[string]interface{}) string {
+	if hook.idFunc != nil {
+		return hook.idFunc(doc)
+	}
+	if hook.instanceID == "" {
+		return ""
+	}
+	seq := atomic.AddUint64(&hook.root().sequence, 1)
+	return hook.instanceID + "-" + strconv.FormatUint(seq, 10)
+}
+
+// documentIDs returns the IDs of docs, assigned
+// once so every attempt reuses them
+func (hook *ElasticHook) documentIDs(docs []map[string]interface{}) []string {
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = hook.documentID(doc)
+	}
+	return ids
+}
+
+// creates reports whether a document with id is
+// written with op_type create, so retries do not
+// duplicate it and data streams accept it
+func (hook *ElasticHook) creates(id string) bool {
+	return id != "" || hook.dataStream
+}
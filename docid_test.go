@@ -0,0 +1,99 @@
+package elogrus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestDocumentIDs(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithDocumentIDs("web-1"))
+	if id := hook.documentID(nil); id != "web-1-1" {
+		t.Errorf("unexpected first ID %q", id)
+	}
+	if id := hook.documentID(nil); id != "web-1-2" {
+		t.Errorf("unexpected second ID %q", id)
+	}
+
+	hook = newHook(nil, "localhost", logrus.DebugLevel, "test")
+	if id := hook.documentID(nil); id != "" {
+		t.Errorf("expected no ID by default, got %q", id)
+	}
+}
+
+func TestContentIDs(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithDocumentIDs("web-1"),
+		WithContentIDs(),
+	)
+	at := time.Date(2024, 5, 17, 10, 0, 0, 0, time.UTC)
+	for _, msg := range []string{"charged", "charged", "refunded"} {
+		hook.Fire(&logrus.Entry{Time: at, Message: msg, Data: logrus.Fields{"order": 42}})
+	}
+
+	items := hook.bulk.take()
+	if items[0].docID == "" || items[0].docID != items[1].docID {
+		t.Errorf("expected equal documents to share an ID, got %q and %q", items[0].docID, items[1].docID)
+	}
+	if items[2].docID == items[0].docID {
+		t.Error("expected different documents to get different IDs")
+	}
+	if len(items[0].docID) != 32 {
+		t.Errorf("unexpected ID %q", items[0].docID)
+	}
+}
+
+func TestUnsentDocumentIDs(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithMaxInFlight(1),
+		WithDocumentIDs("web-1"),
+	)
+	hook.inFlight <- struct{}{}
+	hook.Fire(&logrus.Entry{Data: logrus.Fields{}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	uerr, ok := hook.Shutdown(ctx).(*UnsentError)
+	if !ok || len(uerr.IDs) != 1 || uerr.IDs[0] != "web-1-1" {
+		t.Errorf("expected the ID of the unsent document, got %v", uerr)
+	}
+}
+
+// retryingForwarder fails the first
+// attempt and records every ID it gets
+type retryingForwarder struct {
+	ids []string
+}
+
+func (f *retryingForwarder) Forward(ctx context.Context, docs []ForwardedDocument) ([]error, error) {
+	for _, doc := range docs {
+		f.ids = append(f.ids, doc.ID)
+	}
+	if len(f.ids) == 1 {
+		return nil, &elastic.Error{Status: 503}
+	}
+	return nil, nil
+}
+
+func TestRetriedDocumentIDs(t *testing.T) {
+	fwd := &retryingForwarder{}
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test",
+		WithRetry(RetryConfig{Attempts: 2, MinBackoff: time.Millisecond}),
+		WithDocumentIDs("web-1"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	if err := hook.Fire(&logrus.Entry{Data: logrus.Fields{}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fwd.ids) != 2 || fwd.ids[0] != "web-1-1" || fwd.ids[1] != fwd.ids[0] {
+		t.Errorf("expected the retry to reuse the ID, got %v", fwd.ids)
+	}
+}
@@ -0,0 +1,146 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// TruncatedField is set to true in
+// documents cut to the size limit
+const TruncatedField = "truncated"
+
+// truncatedMarker ends the values
+// cut to the document size limit
+const truncatedMarker = "...[truncated]"
+
+// WithMaxDocumentSize cuts documents encoded larger than
+// bytes, e.g. below http.max_content_length, instead of
+// having ElasticSearch reject them: the longest string
+// values are truncated first and TruncatedField is set.
+// Documents still too large after that, e.g. with many
+// small fields, are sent as they are. See
+// DocumentsTruncated and WithFieldLimits.
+func WithMaxDocumentSize(bytes int) Option {
+	return func(hook *ElasticHook) {
+		hook.docLimit = &docLimiter{max: bytes}
+		if bytes <= 0 {
+			hook.docLimit = nil
+		}
+	}
+}
+
+// DocumentsTruncated returns the number
+// of documents cut to the size limit
+func (hook *ElasticHook) DocumentsTruncated() int64 {
+	if hook.docLimit == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&hook.docLimit.truncated)
+}
+
+type docLimiter struct {
+	// truncated is first to be 64-bit
+	// aligned for atomic access
+	truncated int64
+	max       int
+}
+
+// limitDocuments cuts the
+// documents over the limit
+func (hook *ElasticHook) limitDocuments(docs []map[string]interface{}) []map[string]interface{} {
+	if hook.docLimit == nil {
+		return docs
+	}
+	for i, doc := range docs {
+		docs[i] = hook.docLimit.limit(doc)
+	}
+	return docs
+}
+
+// limit returns a copy of doc with its longest
+// strings cut until it fits, doc when it does
+func (l *docLimiter) limit(doc map[string]interface{}) map[string]interface{} {
+	size := encodedSize(doc)
+	if size <= l.max {
+		return doc
+	}
+	atomic.AddInt64(&l.truncated, 1)
+	doc = copyMaps(doc).(map[string]interface{})
+	doc[TruncatedField] = true
+	for size > l.max {
+		fields, key, s := longestString(doc)
+		if fields == nil || len(s) <= len(truncatedMarker) {
+			break
+		}
+		if keep := len(s) - (size - l.max) - len(truncatedMarker); keep > 0 {
+			s = truncate(s, keep)
+		} else {
+			s = ""
+		}
+		fields[key] = s + truncatedMarker
+		// escaped characters may
+		// need a further round
+		if size = encodedSize(doc); size < 0 {
+			break
+		}
+	}
+	return doc
+}
+
+// encodedSize returns the JSON
+// size of v, -1 on errors
+func encodedSize(v interface{}) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return -1
+	}
+	return len(b)
+}
+
+// copyMaps copies the maps nested in v, so
+// values can be cut without changing the
+// entry data shared with the document
+func copyMaps(v interface{}) interface{} {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(m))
+		for k, e := range m {
+			c[k] = copyMaps(e)
+		}
+		return c
+	case logrus.Fields:
+		c := make(logrus.Fields, len(m))
+		for k, e := range m {
+			c[k] = copyMaps(e)
+		}
+		return c
+	}
+	return v
+}
+
+// longestString returns the map and key of the
+// longest string nested in fields, nil if none
+func longestString(fields map[string]interface{}) (map[string]interface{}, string, string) {
+	var (
+		found map[string]interface{}
+		key   string
+		s     string
+	)
+	for k, v := range fields {
+		m, mk, ms := map[string]interface{}(nil), k, ""
+		switch v := v.(type) {
+		case string:
+			m, ms = fields, v
+		case map[string]interface{}:
+			m, mk, ms = longestString(v)
+		case logrus.Fields:
+			m, mk, ms = longestString(v)
+		}
+		if m != nil && (found == nil || len(ms) > len(s)) {
+			found, key, s = m, mk, ms
+		}
+	}
+	return found, key, s
+}
@@ -0,0 +1,57 @@
+package elogrus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// ECSVersion is the Elastic Common Schema
+// version of documents in ECS format
+const ECSVersion = "1.12.0"
+
+// WithECSFormat sends documents in the Elastic Common
+// Schema: @timestamp, message, log.level, host.name
+// and ecs.version, with the logrus error field as
+// error.message. Other fields are added at the top
+// level under their names, so "service.name" or
+// "user.id" map to ECS fields; fields the hook sets
+// take precedence. WithLevelField is ignored.
+func WithECSFormat() Option {
+	return func(hook *ElasticHook) {
+		hook.ecs = true
+	}
+}
+
+// ecsDocument fills doc with the
+// ECS fields of entry
+func (hook *ElasticHook) ecsDocument(doc map[string]interface{}, entry *logrus.Entry) {
+	doc["@timestamp"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	doc["message"] = entry.Message
+	doc["log.level"] = strings.ToLower(entry.Level.String())
+	doc[hook.originField("host.name", "host")] = hook.host
+	doc["ecs.version"] = ECSVersion
+	for k, v := range hook.fields(entry) {
+		if k == logrus.ErrorKey {
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			doc["error.message"] = fmt.Sprint(v)
+			continue
+		}
+		if _, set := doc[k]; !set {
+			doc[k] = v
+		}
+	}
+}
+
+// messageField returns the name
+// of the message field
+func (hook *ElasticHook) messageField() string {
+	if hook.ecs || hook.filebeat != nil {
+		return "message"
+	}
+	return "Message"
+}
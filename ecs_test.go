@@ -0,0 +1,41 @@
+package elogrus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestECSDocument(t *testing.T) {
+	hook := newHook(nil, "web-1", logrus.DebugLevel, "test", WithECSFormat())
+	doc := hook.document(&logrus.Entry{
+		Time:    time.Date(2024, 5, 17, 13, 0, 0, 0, time.UTC),
+		Level:   logrus.ErrorLevel,
+		Message: "payment failed",
+		Data: logrus.Fields{
+			logrus.ErrorKey: errors.New("card declined"),
+			"service.name":  "billing",
+			"message":       "shadowed",
+		},
+	})
+
+	expected := map[string]interface{}{
+		"@timestamp":    "2024-05-17T13:00:00Z",
+		"message":       "payment failed",
+		"log.level":     "error",
+		"origin.host":   "web-1",
+		"ecs.version":   ECSVersion,
+		"error.message": "card declined",
+		"service.name":  "billing",
+	}
+	for k, v := range expected {
+		if doc[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, doc[k])
+		}
+	}
+	if _, ok := doc["Data"]; ok {
+		t.Error("ECS documents must not nest fields under Data")
+	}
+}
@@ -0,0 +1,142 @@
+// Package elogrusprom exports the delivery
+// metrics of elogrus hooks to Prometheus
+package elogrusprom
+
+import (
+	"time"
+
+	"github.com/iain17/elogrus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Observer counts delivered and failed
+// documents, it implements elogrus.Observer,
+// elogrus.VolumeObserver,
+// elogrus.OperationObserver,
+// elogrus.SchemaObserver and
+// elogrus.SLOObserver
+type Observer struct {
+	labels     []string
+	delivered  *prometheus.CounterVec
+	failed     *prometheus.CounterVec
+	documents  *prometheus.CounterVec
+	bytes      *prometheus.CounterVec
+	retries    *prometheus.CounterVec
+	flushes    *prometheus.HistogramVec
+	violations *prometheus.CounterVec
+	slo        *prometheus.GaugeVec
+}
+
+// New creates an Observer whose counters carry
+// the given labels and registers them with reg.
+// Pass the same labels to elogrus.WithMetricLabels.
+func New(reg prometheus.Registerer, labels ...string) (*Observer, error) {
+	o := &Observer{
+		labels: labels,
+		delivered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "elogrus",
+			Name:      "documents_delivered_total",
+			Help:      "Documents accepted by ElasticSearch.",
+		}, labels),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "elogrus",
+			Name:      "documents_failed_total",
+			Help:      "Documents which could not be delivered to ElasticSearch.",
+		}, labels),
+		documents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "elogrus",
+			Name:      "index_documents_total",
+			Help:      "Documents accepted by ElasticSearch per index.",
+		}, []string{"index"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "elogrus",
+			Name:      "index_bytes_total",
+			Help:      "JSON bytes of the documents accepted by ElasticSearch per index.",
+		}, []string{"index"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "elogrus",
+			Name:      "retries_total",
+			Help:      "Requests to ElasticSearch retried after a transient failure.",
+		}, nil),
+		flushes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "elogrus",
+			Name:      "bulk_flush_seconds",
+			Help:      "Duration of bulk requests by outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		violations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "elogrus",
+			Name:      "schema_violations_total",
+			Help:      "Entry fields breaking the schema by kind.",
+		}, []string{"kind"}),
+		slo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "elogrus",
+			Name:      "slo_compliance",
+			Help:      "Delivery SLO over the rolling window: success and latency ratios, and the error budgets left.",
+		}, []string{"measure"}),
+	}
+	for _, c := range []prometheus.Collector{o.delivered, o.failed, o.documents, o.bytes, o.retries, o.flushes, o.violations, o.slo} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Labels returns the label names,
+// for elogrus.WithMetricLabels
+func (o *Observer) Labels() []string {
+	return o.labels
+}
+
+// Delivered is required to
+// implement elogrus.Observer
+func (o *Observer) Delivered(labels map[string]string, err error) {
+	values := make([]string, len(o.labels))
+	for i, name := range o.labels {
+		values[i] = labels[name]
+	}
+	if err != nil {
+		o.failed.WithLabelValues(values...).Inc()
+		return
+	}
+	o.delivered.WithLabelValues(values...).Inc()
+}
+
+// Shipped is required to implement
+// elogrus.VolumeObserver
+func (o *Observer) Shipped(index string, bytes int) {
+	o.documents.WithLabelValues(index).Inc()
+	o.bytes.WithLabelValues(index).Add(float64(bytes))
+}
+
+// Retried is required to implement
+// elogrus.OperationObserver
+func (o *Observer) Retried(err error) {
+	o.retries.WithLabelValues().Inc()
+}
+
+// Flushed is required to implement
+// elogrus.OperationObserver
+func (o *Observer) Flushed(documents int, latency time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	o.flushes.WithLabelValues(outcome).Observe(latency.Seconds())
+}
+
+// SchemaViolated is required to implement
+// elogrus.SchemaObserver
+func (o *Observer) SchemaViolated(v elogrus.SchemaViolation) {
+	o.violations.WithLabelValues(v.Kind).Inc()
+}
+
+// SLOReported is required to implement
+// elogrus.SLOObserver
+func (o *Observer) SLOReported(s elogrus.SLOStats) {
+	o.slo.WithLabelValues("success_ratio").Set(s.SuccessRatio)
+	o.slo.WithLabelValues("latency_ratio").Set(s.LatencyRatio)
+	o.slo.WithLabelValues("error_budget").Set(s.ErrorBudget)
+	o.slo.WithLabelValues("latency_budget").Set(s.LatencyBudget)
+}
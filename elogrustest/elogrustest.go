@@ -0,0 +1,185 @@
+// Package elogrustest records the documents of elogrus
+// hooks in memory, to unit test logging without a cluster
+package elogrustest
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/iain17/elogrus"
+)
+
+// Document is a document
+// indexed by a hook
+type Document struct {
+	Index   string
+	ID      string
+	Routing string
+	// Fields is the decoded body,
+	// Body the body as sent
+	Fields map[string]interface{}
+	Body   json.RawMessage
+}
+
+// Field returns the value at path, a field name
+// or a dotted path into nested objects, e.g.
+// "Data.user" or "log.origin.file.name"
+func (d Document) Field(path string) (interface{}, bool) {
+	return lookup(d.Fields, path)
+}
+
+func lookup(fields map[string]interface{}, path string) (interface{}, bool) {
+	if v, ok := fields[path]; ok {
+		return v, true
+	}
+	// dotted names may be
+	// keys or nested objects
+	for i := strings.IndexByte(path, '.'); i >= 0; i = nextDot(path, i) {
+		if nested, ok := fields[path[:i]].(map[string]interface{}); ok {
+			if v, ok := lookup(nested, path[i+1:]); ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func nextDot(path string, i int) int {
+	j := strings.IndexByte(path[i+1:], '.')
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
+
+// Recorder is an elogrus.Forwarder keeping the
+// documents in memory, safe for concurrent use
+type Recorder struct {
+	mu   sync.Mutex
+	docs []Document
+	err  error
+}
+
+// NewHook creates a hook recording its documents,
+// for all levels, with host "test" and index "test",
+// configured by opts. Bulk and async hooks record
+// their documents when flushed.
+func NewHook(opts ...elogrus.Option) (*elogrus.ElasticHook, *Recorder, error) {
+	r := &Recorder{}
+	hook, err := elogrus.NewForwardingHook(r, "test", logrus.DebugLevel, "test", opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return hook, r, nil
+}
+
+// Forward is required to
+// implement elogrus.Forwarder
+func (r *Recorder) Forward(ctx context.Context, docs []elogrus.ForwardedDocument) ([]error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, doc := range docs {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(doc.Body, &fields); err != nil {
+			return nil, err
+		}
+		r.docs = append(r.docs, Document{Index: doc.Index, ID: doc.ID, Routing: doc.Routing, Fields: fields, Body: doc.Body})
+	}
+	return nil, nil
+}
+
+// Fail makes the recorder fail every
+// request with err, e.g. to test
+// fallbacks; nil recovers it
+func (r *Recorder) Fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+// Documents returns the
+// documents recorded so far
+func (r *Recorder) Documents() []Document {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Document(nil), r.docs...)
+}
+
+// Reset forgets the
+// recorded documents
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.docs = nil
+}
+
+// Find returns the documents having all fields,
+// by path as for Document.Field, with equal
+// values after JSON encoding, so 1 matches 1.0
+func (r *Recorder) Find(fields map[string]interface{}) []Document {
+	var found []Document
+	for _, doc := range r.Documents() {
+		if matches(doc, fields) {
+			found = append(found, doc)
+		}
+	}
+	return found
+}
+
+func matches(doc Document, fields map[string]interface{}) bool {
+	for path, want := range fields {
+		got, ok := doc.Field(path)
+		if !ok || !equal(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares the
+// values as JSON
+func equal(got, want interface{}) bool {
+	g, err := json.Marshal(got)
+	if err != nil {
+		return false
+	}
+	w, err := json.Marshal(want)
+	return err == nil && string(g) == string(w)
+}
+
+// AssertIndexed fails t unless a document having
+// fields was recorded, and returns the first one
+func AssertIndexed(t testing.TB, r *Recorder, fields map[string]interface{}) Document {
+	t.Helper()
+	found := r.Find(fields)
+	if len(found) == 0 {
+		t.Errorf("no document with %v among %d indexed", fields, len(r.Documents()))
+		return Document{}
+	}
+	return found[0]
+}
+
+// AssertNotIndexed fails t if a
+// document having fields was recorded
+func AssertNotIndexed(t testing.TB, r *Recorder, fields map[string]interface{}) {
+	t.Helper()
+	if found := r.Find(fields); len(found) > 0 {
+		t.Errorf("%d documents with %v indexed, e.g. %s", len(found), fields, found[0].Body)
+	}
+}
+
+// AssertCount fails t unless
+// n documents were recorded
+func AssertCount(t testing.TB, r *Recorder, n int) {
+	t.Helper()
+	if got := len(r.Documents()); got != n {
+		t.Errorf("expected %d documents indexed, got %d", n, got)
+	}
+}
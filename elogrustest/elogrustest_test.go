@@ -0,0 +1,37 @@
+package elogrustest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/iain17/elogrus"
+)
+
+func TestRecorder(t *testing.T) {
+	hook, rec, err := NewHook(elogrus.WithCaller(elogrus.CallerConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logrus.New()
+	logger.Hooks.Add(hook)
+	logger.WithFields(logrus.Fields{"user": "alice", "attempt": 2}).Warn("login failed")
+
+	doc := AssertIndexed(t, rec, map[string]interface{}{
+		"Message":      "login failed",
+		"Data.user":    "alice",
+		"Data.attempt": 2,
+	})
+	if _, ok := doc.Field("origin.file"); !ok || doc.Index != "test" {
+		t.Errorf("unexpected document %s in %s", doc.Body, doc.Index)
+	}
+	AssertNotIndexed(t, rec, map[string]interface{}{"Data.user": "bob"})
+
+	rec.Fail(errors.New("unavailable"))
+	logger.Info("lost")
+	rec.Fail(nil)
+	AssertCount(t, rec, 1)
+
+	rec.Reset()
+	AssertCount(t, rec, 0)
+}
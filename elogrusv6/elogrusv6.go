@@ -0,0 +1,153 @@
+// Package elogrusv6 indexes logrus entries with
+// gopkg.in/olivere/elastic.v6, for ElasticSearch 6,
+// whose indices have the single type _doc
+package elogrusv6
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/iain17/elogrus"
+	elastic3 "gopkg.in/olivere/elastic.v3"
+	"gopkg.in/olivere/elastic.v6"
+)
+
+var (
+	// Fired if a hook is
+	// created without client
+	ErrNilClient = fmt.Errorf("Client is required")
+)
+
+// DocumentType is the type of the documents,
+// the one ElasticSearch 7 uses for typeless APIs
+const DocumentType = "_doc"
+
+// Indexer sends documents with a v6 client,
+// it implements elogrus.Forwarder
+type Indexer struct {
+	client *elastic.Client
+}
+
+// NewIndexer creates an
+// indexer for client
+func NewIndexer(client *elastic.Client) *Indexer {
+	return &Indexer{client: client}
+}
+
+// NewHook creates a hook indexing entries with client, and
+// the index unless it exists; data streams, write-only
+// hooks and index patterns leave indices to the cluster.
+// The other parameters are the same as for
+// elogrus.NewElasticHook.
+func NewHook(client *elastic.Client, host string, level logrus.Level, index string, opts ...elogrus.Option) (*elogrus.ElasticHook, error) {
+	if client == nil {
+		return nil, ErrNilClient
+	}
+	hook, err := elogrus.NewForwardingHook(NewIndexer(client), host, level, index, opts...)
+	if err != nil {
+		return nil, err
+	}
+	o := hook.Options()
+	if o.DataStream || o.WriteOnly || o.IndexPattern != "" {
+		return hook, nil
+	}
+	ctx := context.Background()
+	if o.Timeouts.Control > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeouts.Control)
+		defer cancel()
+	}
+	if err := createIndex(ctx, client, index); err != nil {
+		hook.Stop(false)
+		return nil, err
+	}
+	return hook, nil
+}
+
+// createIndex creates index unless it
+// exists or is created meanwhile
+func createIndex(ctx context.Context, client *elastic.Client, index string) error {
+	exists, err := client.IndexExists(index).Do(ctx)
+	if err != nil {
+		return convertError(err)
+	}
+	if exists {
+		return nil
+	}
+	_, err = client.CreateIndex(index).Do(ctx)
+	if e, ok := err.(*elastic.Error); ok && e.Details != nil && e.Details.Type == "resource_already_exists_exception" {
+		return nil
+	}
+	return convertError(err)
+}
+
+// Forward is required to implement elogrus.Forwarder,
+// documents with ID are created, so retries
+// do not duplicate them
+func (i *Indexer) Forward(ctx context.Context, docs []elogrus.ForwardedDocument) ([]error, error) {
+	bulk := i.client.Bulk()
+	for _, doc := range docs {
+		req := elastic.NewBulkIndexRequest().Index(doc.Index).Type(DocumentType).Doc(doc.Body)
+		if doc.ID != "" {
+			req.Id(doc.ID)
+		}
+		if doc.ID != "" || doc.Create {
+			req.OpType("create")
+		}
+		if doc.Routing != "" {
+			req.Routing(doc.Routing)
+		}
+		if doc.Pipeline != "" {
+			req.Pipeline(doc.Pipeline)
+		}
+		bulk.Add(req)
+	}
+	resp, err := bulk.Do(ctx)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return elogrus.BulkErrors(convertResponse(resp), len(docs))
+}
+
+// convertResponse converts resp to the response of the
+// v3 client, whose errors elogrus retries and reports
+func convertResponse(resp *elastic.BulkResponse) *elastic3.BulkResponse {
+	converted := &elastic3.BulkResponse{Errors: resp.Errors}
+	for _, item := range resp.Items {
+		results := make(map[string]*elastic3.BulkResponseItem, len(item))
+		for op, result := range item {
+			results[op] = &elastic3.BulkResponseItem{
+				Index:  result.Index,
+				Type:   result.Type,
+				Id:     result.Id,
+				Status: result.Status,
+				Error:  convertDetails(result.Error),
+			}
+		}
+		converted.Items = append(converted.Items, results)
+	}
+	return converted
+}
+
+// convertError converts the errors of the v6
+// client elogrus tells apart, see convertResponse
+func convertError(err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *elastic.Error:
+		return &elastic3.Error{Status: e.Status, Details: convertDetails(e.Details)}
+	}
+	if err == elastic.ErrNoClient {
+		return elastic3.ErrNoClient
+	}
+	return err
+}
+
+func convertDetails(details *elastic.ErrorDetails) *elastic3.ErrorDetails {
+	if details == nil {
+		return nil
+	}
+	return &elastic3.ErrorDetails{Type: details.Type, Reason: details.Reason}
+}
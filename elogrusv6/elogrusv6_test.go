@@ -0,0 +1,54 @@
+package elogrusv6
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/iain17/elogrus"
+	elastic3 "gopkg.in/olivere/elastic.v3"
+	"gopkg.in/olivere/elastic.v6"
+)
+
+func TestNewHookWithoutClient(t *testing.T) {
+	if _, err := NewHook(nil, "localhost", logrus.DebugLevel, "test"); err != ErrNilClient {
+		t.Fatalf("expected ErrNilClient, got %v", err)
+	}
+}
+
+func TestConvertResponse(t *testing.T) {
+	resp := &elastic.BulkResponse{Errors: true, Items: []map[string]*elastic.BulkResponseItem{
+		{"create": {Index: "test", Id: "1", Status: 201}},
+		{"create": {Index: "test", Id: "2", Status: 409}},
+		{"create": {Index: "test", Id: "3", Status: 429, Error: &elastic.ErrorDetails{Type: "es_rejected_execution_exception", Reason: "queue full"}}},
+	}}
+	errs, err := elogrus.BulkErrors(convertResponse(resp), 3)
+	var bulkErr *elogrus.BulkError
+	if !errors.As(err, &bulkErr) || len(bulkErr.Failed) != 1 {
+		t.Fatalf("expected a BulkError with one item, got %v", err)
+	}
+	if errs[0] != nil || errs[1] != nil {
+		t.Fatalf("expected created and conflicting documents to succeed, got %v", errs)
+	}
+	e, ok := errs[2].(*elastic3.Error)
+	if !ok || e.Status != 429 || e.Details == nil || e.Details.Reason != "queue full" {
+		t.Fatalf("expected the rejection, got %#v", errs[2])
+	}
+}
+
+func TestConvertError(t *testing.T) {
+	err := convertError(&elastic.Error{Status: 503, Details: &elastic.ErrorDetails{Type: "unavailable"}})
+	if e, ok := err.(*elastic3.Error); !ok || e.Status != 503 || e.Details.Type != "unavailable" {
+		t.Fatalf("expected a v3 error, got %#v", err)
+	}
+	if err := convertError(elastic.ErrNoClient); err != elastic3.ErrNoClient {
+		t.Fatalf("expected the v3 ErrNoClient, got %v", err)
+	}
+	other := errors.New("other")
+	if err := convertError(other); err != other {
+		t.Fatalf("expected the error unchanged, got %v", err)
+	}
+	if convertError(nil) != nil {
+		t.Fatal("expected no error")
+	}
+}
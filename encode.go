@@ -0,0 +1,265 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// maxPooledBuffer is the largest
+// buffer kept for reuse
+const maxPooledBuffer = 64 << 10
+
+// maxEncodeDepth is the nesting past which
+// the encoder hands over to encoding/json,
+// which reports cycles
+const maxEncodeDepth = 64
+
+var encodeBuffers = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// staticField is a value of the same field in
+// every document, e.g. of WithEnrichment, with
+// its JSON marshaled once
+type staticField struct {
+	value interface{}
+	raw   []byte
+}
+
+// marshalStatic marshals v once
+// for every document
+func marshalStatic(v interface{}) (staticField, error) {
+	raw, err := json.Marshal(v)
+	return staticField{value: v, raw: raw}, err
+}
+
+// holds reports whether v is still the value the
+// field was marshaled from, maps and slices by
+// identity; other values are marshaled again
+func (f staticField) holds(v interface{}) bool {
+	a, b := reflect.ValueOf(v), reflect.ValueOf(f.value)
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Map:
+		return a.Pointer() == b.Pointer()
+	case reflect.Slice:
+		return a.Pointer() == b.Pointer() && a.Len() == b.Len()
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return v == f.value
+	}
+	return false
+}
+
+// encodeDocument marshals doc as json.Marshal does, in a
+// pooled buffer and without reflection for the values
+// logging uses, strings, numbers, bools and maps; the
+// top-level fields still holding their static value
+// are written as marshaled once
+func encodeDocument(doc map[string]interface{}, static map[string]staticField) ([]byte, error) {
+	bp := encodeBuffers.Get().(*[]byte)
+	buf, err := appendFields((*bp)[:0], doc, 0, static)
+	var body []byte
+	if err == nil {
+		body = append([]byte(nil), buf...)
+	}
+	if cap(buf) <= maxPooledBuffer {
+		*bp = buf[:0]
+		encodeBuffers.Put(bp)
+	}
+	return body, err
+}
+
+// encode marshals a document, through
+// encodeDocument for built documents
+func encode(doc interface{}, static map[string]staticField) ([]byte, error) {
+	if m, ok := doc.(map[string]interface{}); ok {
+		return encodeDocument(m, static)
+	}
+	return json.Marshal(doc)
+}
+
+// appendFields appends the map m, its keys
+// sorted, see encodeDocument for static
+func appendFields(buf []byte, m map[string]interface{}, depth int, static map[string]staticField) ([]byte, error) {
+	if m == nil {
+		return append(buf, "null"...), nil
+	}
+	var stack [16]string
+	keys := stack[:0]
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	buf = append(buf, '{')
+	for i, k := range keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendString(buf, k)
+		buf = append(buf, ':')
+		if f, ok := static[k]; ok && f.holds(m[k]) {
+			buf = append(buf, f.raw...)
+			continue
+		}
+		var err error
+		if buf, err = appendValue(buf, m[k], depth+1); err != nil {
+			return buf, err
+		}
+	}
+	return append(buf, '}'), nil
+}
+
+func appendValue(buf []byte, v interface{}, depth int) ([]byte, error) {
+	if depth > maxEncodeDepth {
+		return appendMarshaled(buf, v)
+	}
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case string:
+		return appendString(buf, v), nil
+	case bool:
+		return strconv.AppendBool(buf, v), nil
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10), nil
+	case int8:
+		return strconv.AppendInt(buf, int64(v), 10), nil
+	case int16:
+		return strconv.AppendInt(buf, int64(v), 10), nil
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(buf, v, 10), nil
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10), nil
+	case uint8:
+		return strconv.AppendUint(buf, uint64(v), 10), nil
+	case uint16:
+		return strconv.AppendUint(buf, uint64(v), 10), nil
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10), nil
+	case uint64:
+		return strconv.AppendUint(buf, v, 10), nil
+	case float32:
+		return appendFloat(buf, v, float64(v), 32)
+	case float64:
+		return appendFloat(buf, v, v, 64)
+	case map[string]interface{}:
+		return appendFields(buf, v, depth, nil)
+	case logrus.Fields:
+		return appendFields(buf, v, depth, nil)
+	case map[string]string:
+		if v == nil {
+			return append(buf, "null"...), nil
+		}
+		fields := make(map[string]interface{}, len(v))
+		for k, s := range v {
+			fields[k] = s
+		}
+		return appendFields(buf, fields, depth, nil)
+	}
+	return appendMarshaled(buf, v)
+}
+
+// appendMarshaled appends v
+// encoded by encoding/json
+func appendMarshaled(buf []byte, v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return buf, err
+	}
+	return append(buf, raw...), nil
+}
+
+// appendFloat formats f as encoding/json
+// does, NaN and infinities are refused
+func appendFloat(buf []byte, v interface{}, f float64, bits int) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendMarshaled(buf, v)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// e-09 is written e-9
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf, nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendString quotes s as encoding/json does,
+// escaping HTML and replacing invalid UTF-8
+func appendString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				buf = append(buf, '\\', b)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if c == '\u2028' || c == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
@@ -0,0 +1,72 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestEncodeDocument(t *testing.T) {
+	doc := map[string]interface{}{
+		"Message":   "<b>tom & jerry</b>\n\t\"quoted\" \\ \x01   é",
+		"Timestamp": time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		"Level":     "INFO",
+		"Data": logrus.Fields{
+			"int":    -42,
+			"uint":   uint8(7),
+			"small":  0.0000001,
+			"large":  1e21,
+			"float":  float32(3.14),
+			"zero":   0.0,
+			"bool":   true,
+			"nil":    nil,
+			"nested": map[string]interface{}{"b": 1, "a": []int{1, 2}},
+			"labels": map[string]string{"team": "payments"},
+			"empty":  map[string]interface{}(nil),
+			"struct": struct{ Name string }{"joe"},
+			"raw":    json.RawMessage(`{"x": 1}`),
+		},
+	}
+	got, err := encodeDocument(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(doc)
+	if string(got) != string(want) {
+		t.Errorf("expected the output of json.Marshal\n%s\ngot\n%s", want, got)
+	}
+
+	// invalid UTF-8 is replaced, escaped
+	// or not depending on the Go version
+	got, _ = encodeDocument(map[string]interface{}{"s": "a\xffb"}, nil)
+	var decoded map[string]string
+	if err := json.Unmarshal(got, &decoded); err != nil || decoded["s"] != "a\ufffdb" {
+		t.Errorf("unexpected encoding %q of invalid UTF-8", got)
+	}
+
+	if _, err := encodeDocument(map[string]interface{}{"nan": math.NaN()}, nil); err == nil {
+		t.Error("expected NaN to be refused")
+	}
+}
+
+func TestEncodeStatic(t *testing.T) {
+	labels := map[string]string{"team": "payments"}
+	static := map[string]staticField{}
+	for k, v := range map[string]interface{}{"pid": 1, "labels": labels, "version": "1.2.3"} {
+		static[k], _ = marshalStatic(v)
+	}
+	// the raw JSON tells the static
+	// form from a marshaled one
+	static["pid"] = staticField{value: 1, raw: []byte("1.0")}
+	doc := map[string]interface{}{"pid": 1, "labels": labels, "version": "2.0.0"}
+	got, err := encodeDocument(doc, static)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"labels":{"team":"payments"},"pid":1.0,"version":"2.0.0"}`; string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
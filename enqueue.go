@@ -0,0 +1,94 @@
+package elogrus
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// EnqueuePolicy selects what Fire does
+// when the bulk queue is full
+type EnqueuePolicy int
+
+const (
+	// EnqueueBlock waits for space
+	EnqueueBlock EnqueuePolicy = iota
+	// EnqueueDrop drops the entry
+	EnqueueDrop
+	// EnqueueBlockTimeout waits up to
+	// BlockTimeout, then drops the entry
+	EnqueueBlockTimeout
+	// EnqueueDropOldest drops the oldest
+	// queued entry to make space
+	EnqueueDropOldest
+)
+
+// WithEnqueuePolicy sets the policy applied when the
+// async or bulk queue is full, in place of the one of
+// AsyncConfig or BulkConfig, wherever it is given
+// among the options. EnqueueDropped counts the
+// entries dropped.
+func WithEnqueuePolicy(policy EnqueuePolicy, blockTimeout time.Duration) Option {
+	return func(hook *ElasticHook) {
+		hook.enqueuePolicy = &enqueuePolicy{policy: policy, blockTimeout: blockTimeout}
+	}
+}
+
+type enqueuePolicy struct {
+	policy       EnqueuePolicy
+	blockTimeout time.Duration
+}
+
+// applyEnqueuePolicy sets the policy
+// of WithEnqueuePolicy on the queues
+func (hook *ElasticHook) applyEnqueuePolicy() {
+	p := hook.enqueuePolicy
+	if p == nil {
+		return
+	}
+	if hook.bulk != nil {
+		hook.bulk.policy, hook.bulk.blockTimeout = p.policy, p.blockTimeout
+	}
+	if hook.async != nil {
+		hook.async.config.Policy, hook.async.config.BlockTimeout = p.policy, p.blockTimeout
+	}
+}
+
+// EnqueueDropped returns the number of
+// documents dropped by the enqueue policy
+func (hook *ElasticHook) EnqueueDropped() int64 {
+	var dropped int64
+	if hook.bulk != nil {
+		dropped += atomic.LoadInt64(&hook.bulk.dropped)
+	}
+	if hook.async != nil {
+		dropped += atomic.LoadInt64(&hook.async.dropped)
+	}
+	return dropped
+}
+
+// wait blocks until the queue has space,
+// reporting false when the policy gives up,
+// e.g. at the deadline of EnqueueBlockTimeout
+func (b *batcher) wait(space <-chan struct{}, deadline time.Time) bool {
+	switch b.policy {
+	case EnqueueDrop:
+		return false
+	case EnqueueBlockTimeout:
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		select {
+		case <-space:
+			return true
+		case <-timer.C:
+			return false
+		case <-b.quit:
+			return false
+		}
+	}
+	select {
+	case <-space:
+		return true
+	case <-b.quit:
+		return false
+	}
+}
@@ -0,0 +1,128 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestEnqueuePolicies(t *testing.T) {
+	hook := &ElasticHook{}
+	WithBulk(BulkConfig{Actions: 2, MaxPending: 2, Policy: EnqueueDrop, FlushInterval: time.Hour})(hook)
+	for i, expected := range []bool{true, true, false} {
+		if hook.bulk.add(bulkItem{}) != expected {
+			t.Errorf("add %d should return %t", i, expected)
+		}
+	}
+	if hook.EnqueueDropped() != 1 {
+		t.Errorf("expected 1 dropped, got %d", hook.EnqueueDropped())
+	}
+
+	hook = &ElasticHook{}
+	WithBulk(BulkConfig{Actions: 1, MaxPending: 1, Policy: EnqueueBlockTimeout, BlockTimeout: 10 * time.Millisecond})(hook)
+	hook.bulk.add(bulkItem{})
+	start := time.Now()
+	if hook.bulk.add(bulkItem{}) {
+		t.Error("expected the entry to be dropped after the timeout")
+	}
+	if time.Since(start) < 10*time.Millisecond {
+		t.Error("expected add to wait for the timeout")
+	}
+}
+
+func TestEnqueueBlockTimeoutDeadline(t *testing.T) {
+	hook := &ElasticHook{}
+	WithBulk(BulkConfig{Actions: 1, MaxPending: 1, Policy: EnqueueBlockTimeout, BlockTimeout: 20 * time.Millisecond})(hook)
+	hook.bulk.add(bulkItem{})
+	// wakeups without space, as when
+	// another entry takes it first
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+			}
+			hook.bulk.mu.Lock()
+			close(hook.bulk.space)
+			hook.bulk.space = make(chan struct{})
+			hook.bulk.mu.Unlock()
+		}
+	}()
+	start := time.Now()
+	if hook.bulk.add(bulkItem{}) {
+		t.Error("expected the entry to be dropped after the timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("expected the timeout to span the wakeups, waited %v", elapsed)
+	}
+}
+
+func TestEnqueueBlocks(t *testing.T) {
+	hook := &ElasticHook{}
+	WithBulk(BulkConfig{Actions: 1, MaxPending: 1})(hook)
+	hook.bulk.add(bulkItem{index: "first"})
+
+	added := make(chan bool)
+	go func() {
+		added <- hook.bulk.add(bulkItem{index: "second"})
+	}()
+	select {
+	case <-added:
+		t.Fatal("add should block while the queue is full")
+	case <-time.After(10 * time.Millisecond):
+	}
+	hook.bulk.take()
+	if !<-added {
+		t.Error("add should succeed once space is free")
+	}
+	if len(hook.bulk.pending) != 1 || hook.bulk.pending[0].index != "second" {
+		t.Errorf("unexpected pending %v", hook.bulk.pending)
+	}
+}
+
+func TestEnqueueDropOldest(t *testing.T) {
+	var outcomes []string
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{Actions: 2, MaxPending: 2, FlushInterval: time.Hour}),
+		WithEnqueuePolicy(EnqueueDropOldest, 0),
+	)
+	hook.bulk.workers <- struct{}{} // keep the queue from being flushed
+	for _, msg := range []string{"first", "second", "third"} {
+		msg := msg
+		hook.fire(&logrus.Entry{Message: msg, Data: logrus.Fields{}}, func(err error) {
+			if err == ErrDropped {
+				outcomes = append(outcomes, msg)
+			}
+		})
+	}
+
+	if len(outcomes) != 1 || outcomes[0] != "first" {
+		t.Errorf("expected the oldest entry to be dropped, got %v", outcomes)
+	}
+	items := hook.bulk.take()
+	if len(items) != 2 || items[0].doc.(map[string]interface{})["Message"] != "second" {
+		t.Errorf("unexpected queue %v", items)
+	}
+	if hook.EnqueueDropped() != 1 {
+		t.Errorf("expected one dropped entry, got %d", hook.EnqueueDropped())
+	}
+}
+
+func TestAsyncDropOldest(t *testing.T) {
+	hook := &ElasticHook{}
+	WithAsync(AsyncConfig{Buffer: 1, Policy: EnqueueDropOldest})(hook)
+	var evicted []string
+	hook.async.evict = func(item asyncItem) { evicted = append(evicted, item.index) }
+
+	hook.async.add(asyncItem{index: "old"})
+	if !hook.async.add(asyncItem{index: "new"}) {
+		t.Fatal("expected the new entry to be queued")
+	}
+	if len(evicted) != 1 || evicted[0] != "old" || (<-hook.async.ch).index != "new" {
+		t.Errorf("expected the old entry to be evicted, got %v", evicted)
+	}
+}
@@ -0,0 +1,289 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Enricher provides fields describing the process
+// or its environment, added to every document.
+// It returns no fields outside its environment,
+// e.g. a Kubernetes enricher outside a pod.
+type Enricher interface {
+	Enrich(ctx context.Context) (map[string]interface{}, error)
+}
+
+// EnricherFunc adapts
+// a func to Enricher
+type EnricherFunc func(ctx context.Context) (map[string]interface{}, error)
+
+// Enrich is required to
+// implement Enricher
+func (f EnricherFunc) Enrich(ctx context.Context) (map[string]interface{}, error) {
+	return f(ctx)
+}
+
+// WithEnrichment runs the enrichers once, when the hook
+// is created, and adds their fields to every document,
+// later enrichers overriding earlier ones. Failures are
+// warned about and leave the fields out; the enrichers
+// get 2 seconds in total. Enrichers given to Clone run
+// then, adding to the fields of the cloned hook.
+func WithEnrichment(enrichers ...Enricher) Option {
+	return func(hook *ElasticHook) {
+		hook.enrichers = append(hook.enrichers, enrichers...)
+	}
+}
+
+// enrich runs the enrichers of the hook, once,
+// on top of the enrichment a clone inherited
+func (hook *ElasticHook) enrich() {
+	if len(hook.enrichers) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(hook.parentCtx, 2*time.Second)
+	defer cancel()
+	// copied, clones share the map
+	enrichment := make(map[string]interface{}, len(hook.enrichment))
+	for k, v := range hook.enrichment {
+		enrichment[k] = v
+	}
+	for _, e := range hook.enrichers {
+		fields, err := e.Enrich(ctx)
+		if err != nil {
+			hook.warn(fmt.Sprintf("Enrichment failed: %v", err))
+			continue
+		}
+		for k, v := range fields {
+			enrichment[k] = v
+		}
+	}
+	// the fields are the same in every
+	// document, so marshaled once
+	hook.static = make(map[string]staticField, len(enrichment))
+	for k, v := range enrichment {
+		f, err := marshalStatic(v)
+		if err != nil {
+			hook.warn(fmt.Sprintf("Enrichment field %s dropped: %v", k, err))
+			delete(enrichment, k)
+			continue
+		}
+		hook.static[k] = f
+	}
+	hook.enrichment = enrichment
+}
+
+// ProcessEnricher adds the
+// process.pid and process.name
+func ProcessEnricher() Enricher {
+	return EnricherFunc(func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"process.pid":  os.Getpid(),
+			"process.name": filepath.Base(os.Args[0]),
+		}, nil
+	})
+}
+
+var (
+	containerID      = regexp.MustCompile(`[0-9a-f]{64}`)
+	mountContainerID = regexp.MustCompile(`containers/([0-9a-f]{64})/`)
+)
+
+// ContainerEnricher adds the container.id of Docker,
+// containerd and CRI-O containers, read from the
+// cgroups or, with cgroup v2 namespaces, the mounts
+func ContainerEnricher() Enricher {
+	return containerEnricher{cgroup: "/proc/self/cgroup", mountinfo: "/proc/self/mountinfo"}
+}
+
+type containerEnricher struct {
+	cgroup    string
+	mountinfo string
+}
+
+func (e containerEnricher) Enrich(ctx context.Context) (map[string]interface{}, error) {
+	if data, err := ioutil.ReadFile(e.cgroup); err == nil {
+		if id := containerID.Find(data); id != nil {
+			return map[string]interface{}{"container.id": string(id)}, nil
+		}
+	}
+	if data, err := ioutil.ReadFile(e.mountinfo); err == nil {
+		if m := mountContainerID.FindSubmatch(data); m != nil {
+			return map[string]interface{}{"container.id": string(m[1])}, nil
+		}
+	}
+	return nil, nil
+}
+
+// KubernetesEnricher adds kubernetes.pod.name,
+// kubernetes.namespace and kubernetes.node.name
+// inside pods, from the POD_NAME, POD_NAMESPACE and
+// NODE_NAME variables set with the downward API;
+// the pod name defaults to the hostname and the
+// namespace to the one of the service account
+func KubernetesEnricher() Enricher {
+	return kubernetesEnricher{
+		getenv:    os.Getenv,
+		namespace: "/var/run/secrets/kubernetes.io/serviceaccount/namespace",
+	}
+}
+
+type kubernetesEnricher struct {
+	getenv    func(string) string
+	namespace string
+}
+
+func (e kubernetesEnricher) Enrich(ctx context.Context) (map[string]interface{}, error) {
+	if e.getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return nil, nil
+	}
+	fields := map[string]interface{}{}
+	pod := e.getenv("POD_NAME")
+	if pod == "" {
+		pod = e.getenv("HOSTNAME")
+	}
+	namespace := e.getenv("POD_NAMESPACE")
+	if namespace == "" {
+		if data, err := ioutil.ReadFile(e.namespace); err == nil {
+			namespace = strings.TrimSpace(string(data))
+		}
+	}
+	for k, v := range map[string]string{
+		"kubernetes.pod.name":  pod,
+		"kubernetes.namespace": namespace,
+		"kubernetes.node.name": e.getenv("NODE_NAME"),
+	} {
+		if v != "" {
+			fields[k] = v
+		}
+	}
+	return fields, nil
+}
+
+// CloudEnricher adds cloud.provider, cloud.instance.id,
+// cloud.machine.type, cloud.region and
+// cloud.availability_zone from the instance metadata
+// of AWS or Google Cloud, client defaults to one
+// with a 500 millisecond timeout
+func CloudEnricher(client *http.Client) Enricher {
+	if client == nil {
+		client = &http.Client{Timeout: 500 * time.Millisecond}
+	}
+	return cloudEnricher{
+		client: client,
+		aws:    "http://169.254.169.254",
+		gcp:    "http://metadata.google.internal",
+	}
+}
+
+type cloudEnricher struct {
+	client *http.Client
+	// aws and gcp are the
+	// metadata endpoints
+	aws string
+	gcp string
+}
+
+func (e cloudEnricher) Enrich(ctx context.Context) (map[string]interface{}, error) {
+	if fields := e.awsMetadata(ctx); fields != nil {
+		return fields, nil
+	}
+	return e.gcpMetadata(ctx), nil
+}
+
+// awsMetadata reads the instance identity
+// with an IMDSv2 token, nil off AWS
+func (e cloudEnricher) awsMetadata(ctx context.Context) map[string]interface{} {
+	req, err := http.NewRequest("PUT", e.aws+"/latest/api/token", nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, ok := e.get(ctx, req)
+	if !ok {
+		return nil
+	}
+	req, err = http.NewRequest("GET", e.aws+"/latest/dynamic/instance-identity/document", nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", string(token))
+	body, ok := e.get(ctx, req)
+	if !ok {
+		return nil
+	}
+	var identity struct {
+		InstanceID       string `json:"instanceId"`
+		InstanceType     string `json:"instanceType"`
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+	}
+	if json.Unmarshal(body, &identity) != nil || identity.InstanceID == "" {
+		return nil
+	}
+	return map[string]interface{}{
+		"cloud.provider":          "aws",
+		"cloud.instance.id":       identity.InstanceID,
+		"cloud.machine.type":      identity.InstanceType,
+		"cloud.region":            identity.Region,
+		"cloud.availability_zone": identity.AvailabilityZone,
+	}
+}
+
+// gcpMetadata reads the instance
+// metadata, nil off Google Cloud
+func (e cloudEnricher) gcpMetadata(ctx context.Context) map[string]interface{} {
+	req, err := http.NewRequest("GET", e.gcp+"/computeMetadata/v1/instance/?recursive=true", nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	body, ok := e.get(ctx, req)
+	if !ok {
+		return nil
+	}
+	var instance struct {
+		ID          json.Number `json:"id"`
+		Zone        string      `json:"zone"`
+		MachineType string      `json:"machineType"`
+	}
+	if json.Unmarshal(body, &instance) != nil || instance.ID == "" {
+		return nil
+	}
+	// zones and machine types are paths,
+	// e.g. projects/1/zones/europe-west1-b
+	zone := instance.Zone[strings.LastIndexByte(instance.Zone, '/')+1:]
+	fields := map[string]interface{}{
+		"cloud.provider":          "gcp",
+		"cloud.instance.id":       instance.ID.String(),
+		"cloud.machine.type":      instance.MachineType[strings.LastIndexByte(instance.MachineType, '/')+1:],
+		"cloud.availability_zone": zone,
+	}
+	if i := strings.LastIndexByte(zone, '-'); i > 0 {
+		fields["cloud.region"] = zone[:i]
+	}
+	return fields
+}
+
+// get returns the body of a
+// successful metadata request
+func (e cloudEnricher) get(ctx context.Context, req *http.Request) ([]byte, bool) {
+	resp, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	return body, err == nil
+}
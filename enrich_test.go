@@ -0,0 +1,105 @@
+package elogrus
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestEnrichment(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithEnrichment(
+		ProcessEnricher(),
+		EnricherFunc(func(ctx context.Context) (map[string]interface{}, error) {
+			return map[string]interface{}{"service.version": "1.2.3"}, nil
+		}),
+	))
+	doc := hook.document(&logrus.Entry{Data: logrus.Fields{}})
+	if doc["process.pid"] != os.Getpid() || doc["service.version"] != "1.2.3" {
+		t.Errorf("expected the enrichment in %v", doc)
+	}
+	if hook.Clone().document(&logrus.Entry{Data: logrus.Fields{}})["service.version"] != "1.2.3" {
+		t.Error("expected clones to keep the enrichment")
+	}
+	clone := hook.Clone(WithEnrichment(EnricherFunc(func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"service.name": "api"}, nil
+	})))
+	doc = clone.document(&logrus.Entry{Data: logrus.Fields{}})
+	if doc["service.version"] != "1.2.3" || doc["service.name"] != "api" {
+		t.Errorf("expected the clone to add its enrichment in %v", doc)
+	}
+	if _, ok := hook.document(&logrus.Entry{Data: logrus.Fields{}})["service.name"]; ok {
+		t.Error("expected the hook to keep its enrichment")
+	}
+}
+
+func TestContainerEnricher(t *testing.T) {
+	dir := t.TempDir()
+	id := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	cgroup := filepath.Join(dir, "cgroup")
+	ioutil.WriteFile(cgroup, []byte("0::/system.slice/docker-"+id+".scope\n"), 0644)
+	fields, _ := containerEnricher{cgroup: cgroup}.Enrich(context.Background())
+	if fields["container.id"] != id {
+		t.Errorf("unexpected fields %v", fields)
+	}
+
+	mountinfo := filepath.Join(dir, "mountinfo")
+	ioutil.WriteFile(cgroup, []byte("0::/\n"), 0644)
+	ioutil.WriteFile(mountinfo, []byte("512 500 254:1 /docker/containers/"+id+"/hostname /etc/hostname rw\n"), 0644)
+	fields, _ = containerEnricher{cgroup: cgroup, mountinfo: mountinfo}.Enrich(context.Background())
+	if fields["container.id"] != id {
+		t.Errorf("unexpected fields from the mounts %v", fields)
+	}
+}
+
+func TestKubernetesEnricher(t *testing.T) {
+	env := map[string]string{}
+	e := kubernetesEnricher{getenv: func(k string) string { return env[k] }, namespace: filepath.Join(t.TempDir(), "namespace")}
+	if fields, _ := e.Enrich(context.Background()); len(fields) != 0 {
+		t.Errorf("expected no fields outside a pod, got %v", fields)
+	}
+	env = map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1", "HOSTNAME": "api-7d9f", "NODE_NAME": "node-1"}
+	ioutil.WriteFile(e.namespace, []byte("billing\n"), 0644)
+	fields, _ := e.Enrich(context.Background())
+	if fields["kubernetes.pod.name"] != "api-7d9f" || fields["kubernetes.namespace"] != "billing" || fields["kubernetes.node.name"] != "node-1" {
+		t.Errorf("unexpected fields %v", fields)
+	}
+}
+
+func TestCloudEnricher(t *testing.T) {
+	aws := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/api/token":
+			w.Write([]byte("token"))
+		case "/latest/dynamic/instance-identity/document":
+			if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"instanceId":"i-123","instanceType":"m5.large","region":"eu-west-1","availabilityZone":"eu-west-1a"}`))
+		}
+	}))
+	defer aws.Close()
+	gcp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"id":4520031799277581759,"zone":"projects/1/zones/europe-west1-b","machineType":"projects/1/machineTypes/e2-small"}`))
+	}))
+	defer gcp.Close()
+
+	fields, _ := cloudEnricher{client: http.DefaultClient, aws: aws.URL, gcp: gcp.URL}.Enrich(context.Background())
+	if fields["cloud.provider"] != "aws" || fields["cloud.instance.id"] != "i-123" || fields["cloud.availability_zone"] != "eu-west-1a" {
+		t.Errorf("unexpected AWS fields %v", fields)
+	}
+	fields, _ = cloudEnricher{client: http.DefaultClient, aws: gcp.URL, gcp: gcp.URL}.Enrich(context.Background())
+	if fields["cloud.provider"] != "gcp" || fields["cloud.instance.id"] != "4520031799277581759" || fields["cloud.region"] != "europe-west1" || fields["cloud.machine.type"] != "e2-small" {
+		t.Errorf("unexpected Google Cloud fields %v", fields)
+	}
+}
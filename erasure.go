@@ -0,0 +1,142 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+var (
+	// Fired if an erasure request
+	// names no field or value
+	ErrErasureSubject = fmt.Errorf("Erasure needs a field and a value")
+	// Fired if indices matching an erasure request
+	// are closed, which delete-by-query skips
+	ErrErasureClosed = fmt.Errorf("Erasure cannot search closed indices")
+)
+
+// ErasureRequest selects the documents
+// of a data subject to delete
+type ErasureRequest struct {
+	// Field identifying the subject, e.g.
+	// "Data.user_id" for a logrus field
+	Field string
+	Value string
+	// Indices to search, default the hook
+	// index and every index it prefixes
+	Indices []string
+	// DryRun only counts the documents
+	DryRun bool
+	// RequestsPerSecond throttles the
+	// deletion, 0 means unthrottled
+	RequestsPerSecond float64
+}
+
+// Erase deletes the documents of a data subject
+// with a delete-by-query, for right-to-erasure
+// requests, and returns how many documents were
+// deleted or, on a dry run, matched. The term
+// query matches the exact value. Closed indices
+// fail the request with ErrErasureClosed before
+// anything is deleted, open them first. Erased
+// documents leave gaps in an audit chain, which
+// VerifyAuditChain reports.
+func (hook *ElasticHook) Erase(ctx context.Context, req ErasureRequest) (int64, error) {
+	if req.Field == "" || req.Value == "" {
+		return 0, ErrErasureSubject
+	}
+	if hook.writeOnly {
+		return 0, ErrWriteOnly
+	}
+	client, err := hook.getClient()
+	if err != nil {
+		return 0, err
+	}
+
+	indices := req.Indices
+	if len(indices) == 0 {
+		indices = []string{hook.index + "*"}
+	}
+	path := "/" + strings.Join(indices, ",")
+	body := erasureQuery(req)
+	ctx = internalContext(ctx)
+	closed, err := closedIndices(ctx, client, path)
+	if err != nil {
+		return 0, err
+	}
+	if len(closed) > 0 {
+		return 0, fmt.Errorf("%w: %s", ErrErasureClosed, strings.Join(closed, ", "))
+	}
+
+	if req.DryRun {
+		resp, err := client.PerformRequestC(ctx, "POST", path+"/_count", nil, body)
+		if err != nil {
+			return 0, err
+		}
+		var count struct {
+			Count int64 `json:"count"`
+		}
+		err = json.Unmarshal(resp.Body, &count)
+		return count.Count, err
+	}
+
+	params := url.Values{"conflicts": {"proceed"}}
+	if req.RequestsPerSecond > 0 {
+		params.Set("requests_per_second", strconv.FormatFloat(req.RequestsPerSecond, 'f', -1, 64))
+	}
+	resp, err := client.PerformRequestC(ctx, "POST", path+"/_delete_by_query", params, body)
+	if err != nil {
+		return 0, err
+	}
+	var result struct {
+		Deleted int64 `json:"deleted"`
+	}
+	err = json.Unmarshal(resp.Body, &result)
+	return result.Deleted, err
+}
+
+// closedIndices returns the closed indices
+// among those of path, e.g. /logs*
+func closedIndices(ctx context.Context, client *elastic.Client, path string) ([]string, error) {
+	params := url.Values{"format": {"json"}, "h": {"index,status"}, "expand_wildcards": {"all"}}
+	resp, err := client.PerformRequestC(ctx, "GET", "/_cat/indices"+path, params, nil)
+	if isStatus(err, 404) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseClosed(resp.Body)
+}
+
+// parseClosed returns the closed
+// indices of a _cat/indices response
+func parseClosed(body []byte) ([]string, error) {
+	var indices []struct {
+		Index  string `json:"index"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &indices); err != nil {
+		return nil, err
+	}
+	var closed []string
+	for _, i := range indices {
+		if i.Status == "close" {
+			closed = append(closed, i.Index)
+		}
+	}
+	return closed, nil
+}
+
+func erasureQuery(req ErasureRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{req.Field: req.Value},
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestEraseNeedsSubject(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test")
+	if _, err := hook.Erase(context.Background(), ErasureRequest{Field: "Data.user_id"}); err != ErrErasureSubject {
+		t.Errorf("expected ErrErasureSubject, got %v", err)
+	}
+	hook = newHook(nil, "localhost", logrus.DebugLevel, "test", WithWriteOnly())
+	if _, err := hook.Erase(context.Background(), ErasureRequest{Field: "Data.user_id", Value: "42"}); err != ErrWriteOnly {
+		t.Errorf("expected ErrWriteOnly, got %v", err)
+	}
+}
+
+func TestErasureQuery(t *testing.T) {
+	body, _ := json.Marshal(erasureQuery(ErasureRequest{Field: "Data.user_id", Value: "42"}))
+	if string(body) != `{"query":{"term":{"Data.user_id":"42"}}}` {
+		t.Errorf("unexpected query %s", body)
+	}
+}
+
+func TestParseClosed(t *testing.T) {
+	body := []byte(`[{"index":"logs-2024.01","status":"close"},{"index":"logs-2024.02","status":"open"}]`)
+	closed, err := parseClosed(body)
+	if err != nil || len(closed) != 1 || closed[0] != "logs-2024.01" {
+		t.Errorf("expected the closed index, got %v: %v", closed, err)
+	}
+}
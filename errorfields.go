@@ -0,0 +1,91 @@
+package elogrus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Fields set from the logrus.ErrorKey
+// error with WithErrorDetails
+const (
+	ErrorMessageField = "error.message"
+	ErrorChainField   = "error.chain"
+	ErrorStackField   = "error.stack"
+)
+
+// WithErrorDetails adds the message of the
+// logrus.ErrorKey error, the messages of the
+// errors it wraps and, when stack is set and
+// the error formats one with %+v like those of
+// github.com/pkg/errors, its stack trace
+func WithErrorDetails(stack bool) Option {
+	return func(hook *ElasticHook) {
+		hook.errorDetails = true
+		hook.errorStack = stack
+	}
+}
+
+// hasErrors reports error values in data,
+// which have to be stored as their message
+func hasErrors(data logrus.Fields) bool {
+	for _, v := range data {
+		if _, ok := v.(error); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// errorStrings replaces the error values in
+// data by their message; most error types have
+// no exported fields and would be stored as {}
+func errorStrings(data logrus.Fields) {
+	for k, v := range data {
+		if err, ok := v.(error); ok {
+			data[k] = err.Error()
+		}
+	}
+}
+
+// addErrorDetails fills doc with the
+// details of the entry error
+func (hook *ElasticHook) addErrorDetails(doc map[string]interface{}, entry *logrus.Entry) {
+	if !hook.errorDetails {
+		return
+	}
+	err, ok := entry.Data[logrus.ErrorKey].(error)
+	if !ok || err == nil {
+		return
+	}
+	doc[ErrorMessageField] = err.Error()
+	var chain []string
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		chain = append(chain, cause.Error())
+	}
+	if len(chain) > 0 {
+		doc[ErrorChainField] = chain
+	}
+	if !hook.errorStack {
+		return
+	}
+	if _, ok := err.(fmt.Formatter); !ok {
+		return
+	}
+	// %+v only adds to the message
+	// when there is a stack trace
+	if s := fmt.Sprintf("%+v", err); s != err.Error() {
+		doc[hook.errorStackField()] = strings.TrimSpace(s)
+	}
+}
+
+// errorStackField returns the name of the
+// stack trace field, ECS calls it differently
+func (hook *ElasticHook) errorStackField() string {
+	if hook.ecs {
+		return "error.stack_trace"
+	}
+	return ErrorStackField
+}
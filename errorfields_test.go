@@ -0,0 +1,66 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type stackError struct{ msg string }
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	fmt.Fprint(s, e.msg)
+	if s.Flag('+') {
+		fmt.Fprint(s, "\nmain.handler\n\t/app/main.go:42")
+	}
+}
+
+func TestErrorValuesStoredAsMessage(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test")
+	entry := &logrus.Entry{Data: logrus.Fields{logrus.ErrorKey: errors.New("disk full"), "other": 1}}
+
+	b, err := json.Marshal(hook.document(entry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct{ Data map[string]interface{} }
+	json.Unmarshal(b, &doc)
+	if doc.Data[logrus.ErrorKey] != "disk full" {
+		t.Errorf("expected the error message, got %v", doc.Data[logrus.ErrorKey])
+	}
+	if _, ok := entry.Data[logrus.ErrorKey].(error); !ok {
+		t.Error("the entry data must not be changed")
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithErrorDetails(true))
+	err := fmt.Errorf("saving order: %w", fmt.Errorf("writing row: %w", &stackError{"disk full"}))
+	doc := hook.document(&logrus.Entry{Data: logrus.Fields{logrus.ErrorKey: err}})
+
+	if doc[ErrorMessageField] != "saving order: writing row: disk full" {
+		t.Errorf("unexpected message %v", doc[ErrorMessageField])
+	}
+	chain := []string{"writing row: disk full", "disk full"}
+	if !reflect.DeepEqual(doc[ErrorChainField], chain) {
+		t.Errorf("expected chain %v, got %v", chain, doc[ErrorChainField])
+	}
+	// wrapping with %w does not keep the stack
+	if _, ok := doc[ErrorStackField]; ok {
+		t.Errorf("unexpected stack %v", doc[ErrorStackField])
+	}
+
+	doc = hook.document(&logrus.Entry{Data: logrus.Fields{logrus.ErrorKey: &stackError{"disk full"}}})
+	if doc[ErrorStackField] != "disk full\nmain.handler\n\t/app/main.go:42" {
+		t.Errorf("unexpected stack %q", doc[ErrorStackField])
+	}
+	if _, ok := doc[ErrorChainField]; ok {
+		t.Error("an error wrapping nothing has no chain")
+	}
+}
@@ -0,0 +1,29 @@
+package elogrus
+
+import "encoding/json"
+
+// ErrorHandler receives the errors which cannot be
+// returned from Fire, doc is the document which was
+// not delivered, nil for other errors of the hook
+type ErrorHandler func(err error, doc json.RawMessage)
+
+// WithErrorHandler calls fn instead of printing to stderr
+// or the internal logger, with every document which could
+// not be delivered once retries are exhausted and with
+// background failures (flushes, alerts, the spool), so
+// applications can log, alert or fall back. fn must be
+// safe for concurrent use and must not log to the hook.
+func WithErrorHandler(fn ErrorHandler) Option {
+	return func(hook *ElasticHook) {
+		hook.errorHandler = fn
+	}
+}
+
+// reportFailed reports a failed delivery of a
+// background worker, unless the error handler
+// got the documents already
+func (hook *ElasticHook) reportFailed(err error) {
+	if hook.errorHandler == nil {
+		hook.reportError(err)
+	}
+}
@@ -0,0 +1,49 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestErrorHandler(t *testing.T) {
+	var docs []string
+	var errs []error
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithMaxInFlight(1),
+		WithErrorHandler(func(err error, doc json.RawMessage) {
+			docs = append(docs, string(doc))
+			errs = append(errs, err)
+		}),
+	)
+	hook.inFlight <- struct{}{}
+	hook.cancel()
+
+	err := hook.Fire(&logrus.Entry{Message: "lost", Data: logrus.Fields{}})
+	if len(docs) != 1 || !strings.Contains(docs[0], "lost") || errs[0] != err {
+		t.Fatalf("unexpected handled documents %v, errors %v", docs, errs)
+	}
+
+	hook.reportError(fmt.Errorf("Background failure"))
+	if len(docs) != 2 || docs[1] != "" || errs[1].Error() != "Background failure" {
+		t.Errorf("expected the background error without a document, got %v", errs)
+	}
+}
+
+func TestAsyncErrors(t *testing.T) {
+	errs := make(chan error, 1)
+	hook := &ElasticHook{}
+	WithAsync(AsyncConfig{Errors: errs})(hook)
+
+	failed := errors.New("Failed")
+	hook.async.notify(failed)
+	// the channel is full, the error is dropped
+	hook.async.notify(errors.New("Dropped"))
+	if err := <-errs; err != failed {
+		t.Errorf("expected the delivery error, got %v", err)
+	}
+}
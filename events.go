@@ -0,0 +1,63 @@
+package elogrus
+
+import "time"
+
+// ClusterEventKind is the kind of change
+// the hook made to the cluster
+type ClusterEventKind string
+
+const (
+	// IndexCreated is reported when the
+	// hook creates an index
+	IndexCreated ClusterEventKind = "index_created"
+	// AliasSwitched is reported when SwitchIndex
+	// moves the alias to a new index
+	AliasSwitched ClusterEventKind = "alias_switched"
+	// IndexRetired is reported when index
+	// retention closes or freezes an index
+	IndexRetired ClusterEventKind = "index_retired"
+	// TemplateInstalled is reported when the
+	// hook puts an index or component template
+	TemplateInstalled ClusterEventKind = "template_installed"
+)
+
+// ClusterEvent describes a change
+// the hook made to the cluster
+type ClusterEvent struct {
+	Kind ClusterEventKind
+	// Index is the index, template
+	// or alias target changed
+	Index string
+	// Alias is set for AliasSwitched
+	Alias string
+	Time  time.Time
+}
+
+// WithClusterEventHandler calls fn whenever the hook
+// changes the cluster, so actions of application pods
+// can be audited. The events are also logged at info
+// level to the internal logger, see WithInternalLogger.
+func WithClusterEventHandler(fn func(ClusterEvent)) Option {
+	return func(hook *ElasticHook) {
+		hook.onClusterEvent = fn
+	}
+}
+
+// clusterEvent reports a change
+// made to the cluster
+func (hook *ElasticHook) clusterEvent(event ClusterEvent) {
+	event.Time = time.Now()
+	if hook.internalLogger != nil {
+		entry := hook.internalLogger.
+			WithField(InternalField, true).
+			WithField("event", string(event.Kind)).
+			WithField("index", event.Index)
+		if event.Alias != "" {
+			entry = entry.WithField("alias", event.Alias)
+		}
+		entry.Info("ElasticSearch cluster changed")
+	}
+	if hook.onClusterEvent != nil {
+		hook.onClusterEvent(event)
+	}
+}
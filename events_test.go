@@ -0,0 +1,32 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestClusterEvent(t *testing.T) {
+	logger := logrus.New()
+	rec := &recordingHook{}
+	logger.Hooks.Add(rec)
+
+	var events []ClusterEvent
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithInternalLogger(logger),
+		WithClusterEventHandler(func(e ClusterEvent) {
+			events = append(events, e)
+		}),
+	)
+	hook.clusterEvent(ClusterEvent{Kind: IndexCreated, Index: "test-acme"})
+
+	if len(events) != 1 || events[0].Kind != IndexCreated || events[0].Time.IsZero() {
+		t.Fatalf("unexpected events %v", events)
+	}
+	if len(rec.entries) != 1 || rec.entries[0].Data["index"] != "test-acme" {
+		t.Fatalf("expected an internal log entry, got %v", rec.entries)
+	}
+	if !isReentrant(rec.entries[0]) {
+		t.Error("internal log entry must be tagged")
+	}
+}
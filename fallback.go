@@ -0,0 +1,103 @@
+package elogrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// FallbackSink receives documents which could
+// not be delivered to ElasticSearch. It must
+// be safe for concurrent use.
+type FallbackSink interface {
+	WriteDocument(doc json.RawMessage) error
+}
+
+// FallbackFunc adapts a
+// func to FallbackSink
+type FallbackFunc func(doc json.RawMessage) error
+
+// WriteDocument is required to
+// implement FallbackSink
+func (f FallbackFunc) WriteDocument(doc json.RawMessage) error {
+	return f(doc)
+}
+
+// WithFallback sends documents of entries at level
+// or more severe to sinks when they cannot be
+// delivered, e.g. while the cluster is unreachable.
+// Call it once per level; entries below every
+// configured level are dropped as before. Entries
+// dropped by quotas or the enqueue policy are not
+// passed on.
+func WithFallback(level logrus.Level, sinks ...FallbackSink) Option {
+	return func(hook *ElasticHook) {
+		hook.fallbacks = append(hook.fallbacks, fallbackRoute{level: level, sinks: sinks})
+	}
+}
+
+type fallbackRoute struct {
+	level logrus.Level
+	sinks []FallbackSink
+}
+
+// fallback passes an undelivered document to
+// the sinks configured for its level
+func (hook *ElasticHook) fallback(level logrus.Level, doc interface{}) {
+	var raw json.RawMessage
+	for _, route := range hook.fallbacks {
+		if level > route.level {
+			continue
+		}
+		if raw == nil {
+			var err error
+			if raw, err = json.Marshal(doc); err != nil {
+				hook.reportError(err)
+				return
+			}
+		}
+		for _, sink := range route.sinks {
+			if err := sink.WriteDocument(raw); err != nil {
+				hook.reportError(fmt.Errorf("Fallback failed: %w", err))
+			}
+		}
+	}
+}
+
+// WriterFallback writes documents
+// to w, one JSON document per line
+func WriterFallback(w io.Writer) FallbackSink {
+	var mu sync.Mutex
+	return FallbackFunc(func(doc json.RawMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		_, err := w.Write(append(doc, '\n'))
+		return err
+	})
+}
+
+// WebhookFallback posts every document as JSON
+// to url, client defaults to one with a
+// 5 second timeout
+func WebhookFallback(url string, client *http.Client) FallbackSink {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	return FallbackFunc(func(doc json.RawMessage) error {
+		resp, err := client.Post(url, "application/json", bytes.NewReader(doc))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("Webhook returned %s", resp.Status)
+		}
+		return nil
+	})
+}
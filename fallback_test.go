@@ -0,0 +1,58 @@
+package elogrus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestFallbackPerLevel(t *testing.T) {
+	var posted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		posted = append(posted, string(b))
+	}))
+	defer srv.Close()
+
+	var file, warnings bytes.Buffer
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithFallback(logrus.ErrorLevel, WriterFallback(&file), WebhookFallback(srv.URL, nil)),
+		WithFallback(logrus.WarnLevel, WriterFallback(&warnings)),
+	)
+
+	hook.fallback(logrus.ErrorLevel, map[string]string{"Message": "boom"})
+	hook.fallback(logrus.WarnLevel, map[string]string{"Message": "slow"})
+	hook.fallback(logrus.InfoLevel, map[string]string{"Message": "hello"})
+
+	if file.String() != `{"Message":"boom"}`+"\n" {
+		t.Errorf("unexpected file fallback %q", file.String())
+	}
+	if len(posted) != 1 || posted[0] != `{"Message":"boom"}` {
+		t.Errorf("unexpected webhook fallback %q", posted)
+	}
+	if strings.Count(warnings.String(), "\n") != 2 {
+		t.Errorf("expected error and warning in the warnings sink, got %q", warnings.String())
+	}
+}
+
+func TestFallbackOnFailure(t *testing.T) {
+	var file bytes.Buffer
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithCircuitBreaker(BreakerConfig{FailureThreshold: 1}),
+		WithFallback(logrus.ErrorLevel, WriterFallback(&file)),
+	)
+	hook.breaker.trip()
+
+	err := hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "boom", Data: logrus.Fields{}})
+	if err != ErrBreakerOpen {
+		t.Fatalf("expected ErrBreakerOpen, got %v", err)
+	}
+	if !strings.Contains(file.String(), `"Message":"boom"`) {
+		t.Errorf("expected the document in the fallback, got %q", file.String())
+	}
+}
@@ -0,0 +1,72 @@
+package elogrus
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// FatalConfig configures the delivery of panic and
+// fatal entries, zero values take the defaults
+type FatalConfig struct {
+	// Timeout bounds the delivery of the entry, and
+	// the flush before it, default 2 seconds
+	Timeout time.Duration
+	// FlushPending sends the pending bulk and
+	// async entries before the entry
+	FlushPending bool
+}
+
+// WithFatalDelivery sends panic and fatal entries before
+// Fire returns, as logrus exits or panics once the hooks
+// return: they skip the bulk and async queues, sampling,
+// quotas, budgets and local-only mode, and are delivered
+// up to the timeout; undelivered ones are spooled or go
+// to the fallbacks. Classifier drops still apply.
+func WithFatalDelivery(config FatalConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Timeout <= 0 {
+			config.Timeout = 2 * time.Second
+		}
+		hook.fatal = &config
+	}
+}
+
+// fireFatal delivers a panic or
+// fatal entry before returning
+func (hook *ElasticHook) fireFatal(entry *logrus.Entry, id uint64, done func(error)) error {
+	if hook.fatal.FlushPending && (hook.bulk != nil || hook.async != nil) {
+		flushed := make(chan error, 1)
+		go func() {
+			flushed <- hook.flush()
+		}()
+		timer := time.NewTimer(hook.fatal.Timeout)
+		select {
+		case err := <-flushed:
+			if err != nil {
+				hook.reportError(err)
+			}
+		case <-timer.C:
+			hook.reportError(ErrFlushTimeout)
+		}
+		timer.Stop()
+	}
+	index := hook.indexFor(entry)
+	ctx, cancel := hook.fireContext(entry)
+	defer cancel()
+	ctx, cancelFatal := withTimeout(ctx, hook.fatal.Timeout)
+	defer cancelFatal()
+	docs := hook.stampedDocuments(entry, sample{})
+	return hook.deliver(asyncItem{
+		id:      id,
+		level:   entry.Level,
+		index:   index,
+		routing: hook.routing(entry),
+		docs:    docs,
+		ids:     hook.documentIDs(docs),
+		labels:  hook.labels(entry, index),
+		fired:   time.Now(),
+		ctx:     ctx,
+		done:    done,
+	})
+}
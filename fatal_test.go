@@ -0,0 +1,46 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestFatalDelivery(t *testing.T) {
+	for _, flush := range []bool{false, true} {
+		fwd := &flakyForwarder{}
+		hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test",
+			WithBulk(BulkConfig{Actions: 100, FlushInterval: time.Hour}),
+			WithSampling(SamplingConfig{Every: map[logrus.Level]int{logrus.FatalLevel: 1000}}),
+			WithFatalDelivery(FatalConfig{FlushPending: flush}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "pending", Data: logrus.Fields{}})
+		for i := 0; i < 2; i++ {
+			if err := hook.Fire(&logrus.Entry{Level: logrus.FatalLevel, Message: "dying", Data: logrus.Fields{}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var messages []string
+		for _, doc := range fwd.docs {
+			var body struct{ Message string }
+			json.Unmarshal(doc.Body, &body)
+			messages = append(messages, body.Message)
+		}
+		want := []string{"dying", "dying"}
+		if flush {
+			want = []string{"pending", "dying", "dying"}
+		}
+		if len(messages) != len(want) || messages[0] != want[0] {
+			t.Errorf("expected %v delivered before Fire returned, got %v", want, messages)
+		}
+		if hook.Options().Fatal.Timeout != 2*time.Second {
+			t.Errorf("unexpected options %+v", hook.Options().Fatal)
+		}
+		hook.Close()
+	}
+}
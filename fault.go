@@ -0,0 +1,96 @@
+package elogrus
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// FaultTransport is a http.RoundTripper injecting
+// failures for testing how applications behave when
+// log shipping degrades. Install it on the client
+// passed to the hook. Rates are probabilities
+// between 0 and 1, checked in field order.
+type FaultTransport struct {
+	// Transport used to send the request,
+	// http.DefaultTransport when nil
+	Transport http.RoundTripper
+	// Latency is added to every request
+	Latency time.Duration
+	// ResetRate fails requests with
+	// a connection reset
+	ResetRate float64
+	// TooManyRequestsRate answers requests
+	// with 429 like an overloaded cluster
+	TooManyRequestsRate float64
+	// ServerErrorRate answers requests
+	// with 503 Service Unavailable
+	ServerErrorRate float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// RoundTrip is required to implement
+// http.RoundTripper
+func (t *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Latency > 0 {
+		timer := time.NewTimer(t.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	switch {
+	case t.hit(t.ResetRate):
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	case t.hit(t.TooManyRequestsRate):
+		return faultResponse(req, http.StatusTooManyRequests, "es_rejected_execution_exception"), nil
+	case t.hit(t.ServerErrorRate):
+		return faultResponse(req, http.StatusServiceUnavailable, "unavailable_shards_exception"), nil
+	}
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req)
+}
+
+func (t *FaultTransport) hit(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rand == nil {
+		t.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return t.rand.Float64() < rate
+}
+
+// faultResponse builds an ElasticSearch
+// style error response
+func faultResponse(req *http.Request, status int, typ string) *http.Response {
+	body := fmt.Sprintf(`{"error":{"type":%q,"reason":"injected by elogrus.FaultTransport"},"status":%d}`, typ, status)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,42 @@
+package elogrus
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFaultTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	get := func(ft *FaultTransport) (*http.Response, error) {
+		resp, err := (&http.Client{Transport: ft}).Get(srv.URL)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	if resp, err := get(&FaultTransport{}); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("no faults configured, got %v %v", resp, err)
+	}
+	if resp, _ := get(&FaultTransport{TooManyRequestsRate: 1}); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", resp.StatusCode)
+	}
+	if resp, _ := get(&FaultTransport{ServerErrorRate: 1}); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+	if _, err := get(&FaultTransport{ResetRate: 1}); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("expected connection reset, got %v", err)
+	}
+
+	start := time.Now()
+	get(&FaultTransport{Latency: 20 * time.Millisecond})
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("expected injected latency")
+	}
+}
@@ -0,0 +1,85 @@
+package elogrus
+
+import "github.com/Sirupsen/logrus"
+
+// extraField is a field computed for
+// every document by a typed getter
+type extraField struct {
+	name  string
+	typ   FieldType
+	value func(*logrus.Entry) interface{}
+}
+
+// WithStringField adds the field name to every
+// document, its value returned by get
+func WithStringField(name string, get func(*logrus.Entry) string) Option {
+	return withExtraField(name, StringField, func(entry *logrus.Entry) interface{} {
+		return get(entry)
+	})
+}
+
+// WithIntField adds the field name to every
+// document, its value returned by get
+func WithIntField(name string, get func(*logrus.Entry) int64) Option {
+	return withExtraField(name, IntField, func(entry *logrus.Entry) interface{} {
+		return get(entry)
+	})
+}
+
+// WithFloatField adds the field name to every
+// document, its value returned by get
+func WithFloatField(name string, get func(*logrus.Entry) float64) Option {
+	return withExtraField(name, FloatField, func(entry *logrus.Entry) interface{} {
+		return get(entry)
+	})
+}
+
+// WithBoolField adds the field name to every
+// document, its value returned by get
+func WithBoolField(name string, get func(*logrus.Entry) bool) Option {
+	return withExtraField(name, BoolField, func(entry *logrus.Entry) interface{} {
+		return get(entry)
+	})
+}
+
+// WithStaticFields merges fields into the data of
+// every entry, e.g. the environment, region or build
+// commit, so routers, classifiers and quotas see
+// them too; entry data takes precedence
+func WithStaticFields(fields logrus.Fields) Option {
+	return func(hook *ElasticHook) {
+		// copied, clones share the map
+		merged := make(logrus.Fields, len(hook.staticFields)+len(fields))
+		for k, v := range hook.staticFields {
+			merged[k] = v
+		}
+		for k, v := range fields {
+			merged[k] = v
+		}
+		hook.staticFields = merged
+	}
+}
+
+// addStaticFields returns entry
+// with the static fields merged
+func (hook *ElasticHook) addStaticFields(entry *logrus.Entry) *logrus.Entry {
+	if len(hook.staticFields) == 0 {
+		return entry
+	}
+	data := make(logrus.Fields, len(entry.Data)+len(hook.staticFields))
+	for k, v := range hook.staticFields {
+		data[k] = v
+	}
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	merged := *entry
+	merged.Data = data
+	return &merged
+}
+
+func withExtraField(name string, typ FieldType, value func(*logrus.Entry) interface{}) Option {
+	return func(hook *ElasticHook) {
+		hook.extraFields = append(hook.extraFields, extraField{name: name, typ: typ, value: value})
+	}
+}
@@ -0,0 +1,39 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestExtraFields(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithStringField("service", func(*logrus.Entry) string { return "billing" }),
+		WithIntField("message_length", func(e *logrus.Entry) int64 { return int64(len(e.Message)) }),
+		WithBoolField("error", func(e *logrus.Entry) bool { return e.Level <= logrus.ErrorLevel }),
+	)
+	doc := hook.document(&logrus.Entry{Level: logrus.ErrorLevel, Message: "boom", Data: logrus.Fields{}})
+
+	if doc["service"] != "billing" || doc["message_length"] != int64(4) || doc["error"] != true {
+		t.Errorf("unexpected document %v", doc)
+	}
+}
+
+func TestStaticFields(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithStaticFields(logrus.Fields{"env": "prod", "region": "eu-west-1"}),
+		WithFlatOrigin(),
+	)
+	clone := hook.Clone(WithStaticFields(logrus.Fields{"kind": "audit"}))
+
+	hook.Fire(&logrus.Entry{Message: "override", Data: logrus.Fields{"env": "staging"}})
+	data := hook.bulk.take()[0].doc.(map[string]interface{})["Data"].(logrus.Fields)
+	if data["env"] != "staging" || data["region"] != "eu-west-1" {
+		t.Errorf("expected entry data to take precedence, got %v", data)
+	}
+	if _, ok := hook.staticFields["kind"]; ok || clone.staticFields["env"] != "prod" {
+		t.Errorf("clone fields leaked, %v and %v", hook.staticFields, clone.staticFields)
+	}
+}
@@ -0,0 +1,66 @@
+package elogrus
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// FilebeatConfig describes the Filebeat instance
+// whose documents the hook imitates
+type FilebeatConfig struct {
+	// Version of Filebeat, default "7.17.0"; 6.x
+	// versions get the beat block instead of agent
+	Version string
+	// Name of the beat, default the host
+	Name string
+}
+
+// WithFilebeatFormat sends documents shaped like those of
+// Filebeat shipping JSON logs: @timestamp, message, the
+// host, the agent (or beat) metadata block and the entry
+// fields and level under fields.*, so teams moving from
+// Filebeat keep their dashboards. Fields the hook adds,
+// like trace.id, stay at the top level.
+func WithFilebeatFormat(config FilebeatConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Version == "" {
+			config.Version = "7.17.0"
+		}
+		hook.filebeat = &config
+	}
+}
+
+// filebeatDocument fills doc with
+// the Filebeat fields of entry
+func (hook *ElasticHook) filebeatDocument(doc map[string]interface{}, entry *logrus.Entry) {
+	config := hook.filebeat
+	name := config.Name
+	if name == "" {
+		name = hook.host
+	}
+	fields := logrus.Fields{}
+	for k, v := range hook.fields(entry) {
+		fields[k] = v
+	}
+	fields["level"] = strings.ToLower(entry.Level.String())
+
+	doc["@timestamp"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	doc["message"] = entry.Message
+	doc["fields"] = fields
+	doc["host"] = map[string]interface{}{"name": hook.host}
+	doc["input"] = map[string]interface{}{"type": "log"}
+	beat := map[string]interface{}{
+		"name":     name,
+		"hostname": hook.host,
+		"version":  config.Version,
+	}
+	if strings.HasPrefix(config.Version, "6.") {
+		doc["beat"] = beat
+		return
+	}
+	beat["type"] = "filebeat"
+	doc["agent"] = beat
+	doc["ecs"] = map[string]interface{}{"version": ECSVersion}
+}
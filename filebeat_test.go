@@ -0,0 +1,44 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestFilebeatDocument(t *testing.T) {
+	hook := newHook(nil, "web-1", logrus.DebugLevel, "test", WithFilebeatFormat(FilebeatConfig{Name: "billing"}))
+	doc := hook.document(&logrus.Entry{
+		Time:    time.Date(2024, 5, 17, 13, 0, 0, 0, time.UTC),
+		Level:   logrus.WarnLevel,
+		Message: "slow charge",
+		Data:    logrus.Fields{"order": 42},
+	})
+	b, _ := json.Marshal(doc)
+
+	var fb struct {
+		Timestamp string `json:"@timestamp"`
+		Message   string
+		Fields    map[string]interface{}
+		Agent     map[string]string
+		Host      map[string]string
+	}
+	json.Unmarshal(b, &fb)
+	if fb.Timestamp != "2024-05-17T13:00:00Z" || fb.Message != "slow charge" || fb.Host["name"] != "web-1" {
+		t.Errorf("unexpected document %s", b)
+	}
+	if fb.Fields["order"] != 42.0 || fb.Fields["level"] != "warning" {
+		t.Errorf("expected the entry fields under fields, got %v", fb.Fields)
+	}
+	if fb.Agent["type"] != "filebeat" || fb.Agent["name"] != "billing" || fb.Agent["version"] != "7.17.0" {
+		t.Errorf("unexpected agent %v", fb.Agent)
+	}
+
+	hook = newHook(nil, "web-1", logrus.DebugLevel, "test", WithFilebeatFormat(FilebeatConfig{Version: "6.8.0"}))
+	doc = hook.document(&logrus.Entry{Data: logrus.Fields{}})
+	if _, ok := doc["beat"]; !ok {
+		t.Error("expected the beat block for Filebeat 6")
+	}
+}
@@ -0,0 +1,25 @@
+package elogrus
+
+import "github.com/Sirupsen/logrus"
+
+// WithFilter skips the entries for which fn returns
+// false before their document is built, e.g. health
+// check and readiness probe lines. Filters added by
+// several calls must all pass. fn must be safe for
+// concurrent use and must not modify the entry.
+func WithFilter(fn func(*logrus.Entry) bool) Option {
+	return func(hook *ElasticHook) {
+		hook.filters = append(hook.filters, fn)
+	}
+}
+
+// filtered reports whether
+// a filter skips entry
+func (hook *ElasticHook) filtered(entry *logrus.Entry) bool {
+	for _, fn := range hook.filters {
+		if !fn(entry) {
+			return true
+		}
+	}
+	return false
+}
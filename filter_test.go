@@ -0,0 +1,25 @@
+package elogrus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestFilter(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithFilter(func(e *logrus.Entry) bool { return !strings.HasPrefix(e.Message, "GET /healthz") }),
+		WithFilter(func(e *logrus.Entry) bool { return e.Data["probe"] != "readiness" }),
+	)
+	hook.Fire(&logrus.Entry{Message: "GET /healthz 200", Data: logrus.Fields{}})
+	hook.Fire(&logrus.Entry{Message: "ready", Data: logrus.Fields{"probe": "readiness"}})
+	hook.Fire(&logrus.Entry{Message: "GET /orders 200", Data: logrus.Fields{}})
+
+	items := hook.bulk.take()
+	if len(items) != 1 || items[0].doc.(map[string]interface{})["Message"] != "GET /orders 200" {
+		t.Errorf("expected only the unfiltered entry, got %v", items)
+	}
+}
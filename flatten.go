@@ -0,0 +1,152 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// FlattenConfig configures the flattening of
+// nested entry data, see WithFlattening
+type FlattenConfig struct {
+	// Separator joins the keys of
+	// nested values, default "."
+	Separator string
+	// MaxDepth is the deepest level flattened,
+	// default 5; deeper values are stored as
+	// JSON strings
+	MaxDepth int
+	// MaxFields bounds the flat fields of an
+	// entry, default 100; the others are left
+	// out in key order, see FieldsDropped
+	MaxFields int
+	// Sanitize replaces characters of keys other
+	// than letters, digits, '_', '-' and '@' by '_'
+	Sanitize bool
+}
+
+// WithFlattening converts nested maps and structs in
+// the entry data into flat keys, e.g. user.address.city,
+// so deeply nested values cannot explode the mapping.
+// Values marshalled as JSON strings or numbers, such as
+// time.Time, are kept as they are.
+func WithFlattening(config FlattenConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Separator == "" {
+			config.Separator = "."
+		}
+		if config.MaxDepth <= 0 {
+			config.MaxDepth = 5
+		}
+		if config.MaxFields <= 0 {
+			config.MaxFields = 100
+		}
+		hook.flattener = &flattener{FlattenConfig: config}
+	}
+}
+
+// FieldsDropped returns the number of
+// fields left out by the flattening
+func (hook *ElasticHook) FieldsDropped() int64 {
+	if hook.flattener == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&hook.flattener.dropped)
+}
+
+type flattener struct {
+	// dropped is first to be 64-bit
+	// aligned for atomic access
+	dropped int64
+	FlattenConfig
+}
+
+// flattenEntry returns a copy of
+// entry with flat data
+func (hook *ElasticHook) flattenEntry(entry *logrus.Entry) *logrus.Entry {
+	f := hook.flattener
+	if f == nil {
+		return entry
+	}
+	flat := *entry
+	flat.Data = make(logrus.Fields, len(entry.Data))
+	f.flatten(flat.Data, "", map[string]interface{}(entry.Data), 1)
+	return &flat
+}
+
+func (f *flattener) flatten(dst logrus.Fields, prefix string, m map[string]interface{}, depth int) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key := f.key(k)
+		if prefix != "" {
+			key = prefix + f.Separator + key
+		}
+		v := m[k]
+		nested, ok := asObject(v)
+		switch {
+		case ok && depth < f.MaxDepth:
+			f.flatten(dst, key, nested, depth+1)
+			continue
+		case ok:
+			b, _ := json.Marshal(v)
+			v = string(b)
+		}
+		if len(dst) >= f.MaxFields {
+			atomic.AddInt64(&f.dropped, 1)
+			continue
+		}
+		dst[key] = v
+	}
+}
+
+// key sanitizes a key
+// when configured
+func (f *flattener) key(k string) string {
+	if !f.Sanitize {
+		return k
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '@':
+			return r
+		}
+		return '_'
+	}, k)
+}
+
+// asObject returns v as a map when
+// it is encoded as a JSON object
+func asObject(v interface{}) (map[string]interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, true
+	case logrus.Fields:
+		return v, true
+	case nil, string, bool, error, json.Marshaler:
+		return nil, false
+	}
+	kind := reflect.TypeOf(v).Kind()
+	if kind == reflect.Ptr {
+		kind = reflect.TypeOf(v).Elem().Kind()
+	}
+	if kind != reflect.Struct && kind != reflect.Map {
+		return nil, false
+	}
+	b, err := json.Marshal(v)
+	if err != nil || len(b) == 0 || b[0] != '{' {
+		return nil, false
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, false
+	}
+	return m, true
+}
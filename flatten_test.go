@@ -0,0 +1,43 @@
+package elogrus
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type testAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+func TestFlattening(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithFlattening(FlattenConfig{Separator: "_", MaxDepth: 2, MaxFields: 4, Sanitize: true}),
+	)
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entry := hook.flattenEntry(&logrus.Entry{Data: logrus.Fields{
+		"user": map[string]interface{}{
+			"address": &testAddress{City: "Ghent", Zip: "9000"},
+			"name":    "bob",
+		},
+		"a.b c": 1,
+		"at":    at,
+		"zz":    true,
+	}})
+
+	expected := logrus.Fields{
+		"a_b_c":        1,
+		"at":           at,
+		"user_address": `{"city":"Ghent","zip":"9000"}`,
+		"user_name":    "bob",
+	}
+	if !reflect.DeepEqual(entry.Data, expected) {
+		t.Errorf("expected %v, got %v", expected, entry.Data)
+	}
+	if hook.FieldsDropped() != 1 {
+		t.Errorf("expected one dropped field, got %d", hook.FieldsDropped())
+	}
+}
@@ -0,0 +1,166 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// ForwardedDocument is a
+// document given to a Forwarder
+type ForwardedDocument struct {
+	Index string
+	// ID and Pipeline are empty unless
+	// document IDs or a pipeline are set
+	ID       string
+	Pipeline string
+	// Routing is the routing key,
+	// empty unless WithRoutingFunc
+	Routing string
+	// Create is set for data streams,
+	// which only accept new documents
+	Create bool
+	Body   json.RawMessage
+}
+
+// Forwarder delivers documents in place of the
+// ElasticSearch client, e.g. to Logstash or
+// OpenSearch. It returns the error of each
+// document, when known, and an error if any
+// failed. It must be safe for concurrent use.
+type Forwarder interface {
+	Forward(ctx context.Context, docs []ForwardedDocument) ([]error, error)
+}
+
+// WithForwarder delivers the documents through f
+// instead of the client, with the same retries,
+// fallbacks and bulk batching. Indices are not
+// created or checked.
+func WithForwarder(f Forwarder) Option {
+	return func(hook *ElasticHook) {
+		hook.forwarder = f
+	}
+}
+
+// NewForwardingHook creates a hook delivering entries
+// through f, without ElasticSearch client. The other
+// parameters are the same as for NewElasticHook.
+func NewForwardingHook(f Forwarder, host string, level logrus.Level, index string, opts ...Option) (*ElasticHook, error) {
+	hook := newHook(nil, host, level, index, append(opts, WithForwarder(f))...)
+	if hook.err != nil {
+		return nil, hook.err
+	}
+	return hook, nil
+}
+
+// forward delivers the documents of an entry with
+// ids, returning how many were delivered in order
+func (hook *ElasticHook) forward(ctx context.Context, index, routing string, docs []map[string]interface{}, ids []string) (int, error) {
+	fwd := make([]ForwardedDocument, 0, len(docs))
+	for i, doc := range docs {
+		body, err := encodeDocument(doc, hook.static)
+		if err != nil {
+			return 0, err
+		}
+		fwd = append(fwd, ForwardedDocument{Index: index, ID: ids[i], Pipeline: hook.pipeline, Routing: routing, Create: hook.dataStream, Body: body})
+	}
+	errs, err := hook.forwarder.Forward(ctx, fwd)
+	hook.available(err)
+	if err == nil {
+		for _, doc := range fwd {
+			hook.shipped(index, len(doc.Body))
+		}
+		return len(fwd), nil
+	}
+	n := 0
+	for n < len(errs) && errs[n] == nil {
+		hook.shipped(index, len(fwd[n].Body))
+		n++
+	}
+	if n < len(errs) {
+		err = errs[n]
+	}
+	return n, err
+}
+
+// forward delivers items through the
+// forwarder, retrying transient errors
+func (b *batcher) forward(items []bulkItem) error {
+	sent := make([]bulkItem, 0, len(items))
+	docs := make([]ForwardedDocument, 0, len(items))
+	var encodeErr error
+	for _, item := range items {
+		body := item.body
+		if body == nil {
+			var err error
+			if body, err = encode(item.doc, b.hook.static); err != nil {
+				encodeErr = err
+				b.finish(item, err)
+				continue
+			}
+		}
+		sent = append(sent, item)
+		docs = append(docs, ForwardedDocument{Index: item.index, ID: item.docID, Pipeline: item.pipeline, Routing: item.routing, Create: item.create, Body: body})
+	}
+	if len(sent) == 0 {
+		return encodeErr
+	}
+	for _, item := range sent {
+		b.hook.trace(item.id, "sent", nil)
+	}
+	var failed error
+	for attempt := 0; len(sent) > 0; attempt++ {
+		b.hook.pace(b.hook.ctx, len(docs))
+		start := time.Now()
+		ctx, cancel := b.hook.dataContext()
+		errs, err := b.hook.forwarder.Forward(ctx, docs)
+		cancel()
+		b.hook.flushed(len(docs), time.Since(start), err)
+		b.hook.throttled(err)
+		b.hook.available(err)
+
+		var retry []int
+		var retryErr error
+		for i, item := range sent {
+			itemErr := err
+			if errs != nil {
+				itemErr = errs[i]
+			}
+			if isTransient(itemErr) {
+				retry = append(retry, i)
+				retryErr = itemErr
+				continue
+			}
+			if itemErr == nil {
+				b.hook.shipped(item.index, len(docs[i].Body))
+			} else {
+				failed = err
+			}
+			b.finish(item, itemErr)
+		}
+		if len(retry) == 0 {
+			break
+		}
+		if !b.hook.backoff(attempt, retryErr) {
+			for _, i := range retry {
+				b.finish(sent[i], retryErr)
+			}
+			failed = retryErr
+			break
+		}
+		var next []bulkItem
+		var nextDocs []ForwardedDocument
+		for _, i := range retry {
+			b.hook.trace(sent[i].id, "retried", retryErr)
+			next = append(next, sent[i])
+			nextDocs = append(nextDocs, docs[i])
+		}
+		sent, docs = next, nextDocs
+	}
+	if failed != nil {
+		return failed
+	}
+	return encodeErr
+}
@@ -0,0 +1,184 @@
+package elogrus
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// GapLevelsField holds the lost
+// documents per level of a gap report
+const GapLevelsField = "gap.levels"
+
+// states of the gap reports
+const (
+	gapsNone int32 = iota
+	gapsPending
+	gapsReporting
+)
+
+// GapReportConfig configures gap reports,
+// zero values fall back to the defaults
+type GapReportConfig struct {
+	// Bucket is the time span summarized
+	// by a report, default 1 minute
+	Bucket time.Duration
+	// MaxBuckets bounds the buckets kept until
+	// delivery resumes, the oldest are forgotten
+	// first; default 1440, a day of minutes
+	MaxBuckets int
+}
+
+// WithGapReports counts the documents lost while the
+// cluster fails, dropped, discarded or written locally
+// only, per time bucket and level. Once delivery
+// resumes, a report document per bucket, timestamped
+// at its start, shows where data is missing.
+func WithGapReports(config GapReportConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Bucket <= 0 {
+			config.Bucket = time.Minute
+		}
+		if config.MaxBuckets <= 0 {
+			config.MaxBuckets = 1440
+		}
+		hook.gaps = &gapReports{
+			config:  config,
+			buckets: map[int64]*gapBucket{},
+			now:     time.Now,
+		}
+	}
+}
+
+type gapReports struct {
+	// state is first to be 32-bit
+	// aligned for atomic access
+	state int32
+
+	mu      sync.Mutex
+	config  GapReportConfig
+	buckets map[int64]*gapBucket
+	now     func() time.Time
+}
+
+type gapBucket struct {
+	start   time.Time
+	entries int64
+	levels  map[string]int64
+}
+
+// add counts n documents
+// lost at level in bucket
+func (g *gapReports) add(start time.Time, level string, n int64) {
+	key := start.UnixNano()
+	b, ok := g.buckets[key]
+	if !ok {
+		if len(g.buckets) >= g.config.MaxBuckets {
+			oldest := key
+			for k := range g.buckets {
+				if k < oldest {
+					oldest = k
+				}
+			}
+			if oldest == key {
+				return
+			}
+			delete(g.buckets, oldest)
+		}
+		b = &gapBucket{start: start, levels: map[string]int64{}}
+		g.buckets[key] = b
+	}
+	b.entries += n
+	b.levels[level] += n
+}
+
+// take removes the buckets,
+// oldest first
+func (g *gapReports) take() []*gapBucket {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	buckets := make([]*gapBucket, 0, len(g.buckets))
+	for _, b := range g.buckets {
+		buckets = append(buckets, b)
+	}
+	g.buckets = map[int64]*gapBucket{}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].start.Before(buckets[j].start)
+	})
+	return buckets
+}
+
+// restore puts back buckets which
+// could not be reported
+func (g *gapReports) restore(buckets []*gapBucket) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, b := range buckets {
+		for level, n := range b.levels {
+			g.add(b.start, level, n)
+		}
+	}
+}
+
+// lost counts n documents lost at level
+func (hook *ElasticHook) lost(level logrus.Level, n int) {
+	g := hook.root().gaps
+	if g == nil || n == 0 {
+		return
+	}
+	g.mu.Lock()
+	g.add(g.now().Truncate(g.config.Bucket), level.String(), int64(n))
+	g.mu.Unlock()
+	atomic.CompareAndSwapInt32(&g.state, gapsNone, gapsPending)
+}
+
+// delivered reports the gaps, if any,
+// after a document was delivered
+func (hook *ElasticHook) delivered() {
+	g := hook.root().gaps
+	if g == nil || !atomic.CompareAndSwapInt32(&g.state, gapsPending, gapsReporting) {
+		return
+	}
+	go hook.root().reportGaps()
+}
+
+// reportGaps sends a report per bucket,
+// keeping them for later on failure
+func (hook *ElasticHook) reportGaps() {
+	g := hook.gaps
+	buckets := g.take()
+	docs := make([]map[string]interface{}, len(buckets))
+	for i, b := range buckets {
+		levels := make(map[string]interface{}, len(b.levels))
+		for level, n := range b.levels {
+			levels[level] = n
+		}
+		docs[i] = hook.document(&logrus.Entry{
+			Level:   logrus.WarnLevel,
+			Time:    b.start,
+			Message: fmt.Sprintf("%d documents lost between %s and %s", b.entries, b.start.Format("15:04:05"), b.start.Add(g.config.Bucket).Format("15:04:05")),
+			Data: logrus.Fields{
+				GapStartField:   b.start.UTC().Format(time.RFC3339Nano),
+				GapEndField:     b.start.Add(g.config.Bucket).UTC().Format(time.RFC3339Nano),
+				GapEntriesField: b.entries,
+				GapLevelsField:  levels,
+			},
+		})
+	}
+	if err := hook.sendNow(docs); err != nil {
+		g.restore(buckets)
+		atomic.StoreInt32(&g.state, gapsPending)
+		return
+	}
+	g.mu.Lock()
+	state := gapsNone
+	if len(g.buckets) > 0 {
+		state = gapsPending
+	}
+	atomic.StoreInt32(&g.state, state)
+	g.mu.Unlock()
+}
@@ -0,0 +1,55 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestGapReports(t *testing.T) {
+	fwd := &flakyForwarder{err: &elastic.Error{Status: 503}}
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test", WithGapReports(GapReportConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	now := time.Date(2024, 5, 1, 12, 0, 30, 0, time.UTC)
+	hook.gaps.now = func() time.Time { return now }
+
+	hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "lost", Data: logrus.Fields{}})
+	hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "lost", Data: logrus.Fields{}})
+	now = now.Add(time.Minute)
+	hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "lost", Data: logrus.Fields{}})
+
+	fwd.mu.Lock()
+	fwd.err = nil
+	fwd.mu.Unlock()
+	hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "back", Data: logrus.Fields{}})
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		fwd.mu.Lock()
+		n := len(fwd.docs)
+		fwd.mu.Unlock()
+		if n == 3 {
+			break
+		}
+	}
+
+	fwd.mu.Lock()
+	defer fwd.mu.Unlock()
+	if len(fwd.docs) != 3 {
+		t.Fatalf("expected the entry and two gap reports, got %d documents", len(fwd.docs))
+	}
+	var report struct {
+		Timestamp string
+		Data      map[string]interface{}
+	}
+	json.Unmarshal(fwd.docs[1].Body, &report)
+	levels, _ := report.Data[GapLevelsField].(map[string]interface{})
+	if report.Timestamp != "2024-05-01T12:00:00Z" || report.Data[GapEntriesField] != 2.0 || levels["error"] != 1.0 || levels["info"] != 1.0 {
+		t.Errorf("unexpected first report %s", fwd.docs[1].Body)
+	}
+}
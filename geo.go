@@ -0,0 +1,51 @@
+package elogrus
+
+import "github.com/Sirupsen/logrus"
+
+// GeoConfig names the fields of a geo point,
+// zero values fall back to the defaults
+type GeoConfig struct {
+	// Lat and Lon are the entry fields holding the
+	// coordinates, default "latitude" and "longitude"
+	Lat string
+	Lon string
+	// Field is the geo_point document
+	// field, default "client.geo.location"
+	Field string
+}
+
+// WithGeoPoint combines the latitude and longitude
+// fields of entries into a geo_point field, mapped as
+// such by WithIndexTemplate, so Kibana maps work on
+// log data. Entries without both valid coordinates
+// get no point.
+func WithGeoPoint(config GeoConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Lat == "" {
+			config.Lat = "latitude"
+		}
+		if config.Lon == "" {
+			config.Lon = "longitude"
+		}
+		if config.Field == "" {
+			config.Field = "client.geo.location"
+		}
+		hook.geoPoints = append(hook.geoPoints, config)
+	}
+}
+
+// addGeoPoints sets the configured
+// geo points of entry on doc
+func (hook *ElasticHook) addGeoPoints(doc map[string]interface{}, entry *logrus.Entry) {
+	for _, g := range hook.geoPoints {
+		lat, ok := toFloat(entry.Data[g.Lat])
+		if !ok || lat < -90 || lat > 90 {
+			continue
+		}
+		lon, ok := toFloat(entry.Data[g.Lon])
+		if !ok || lon < -180 || lon > 180 {
+			continue
+		}
+		doc[g.Field] = map[string]float64{"lat": lat, "lon": lon}
+	}
+}
@@ -0,0 +1,31 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestGeoPoint(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithGeoPoint(GeoConfig{}))
+
+	doc := hook.document(&logrus.Entry{Data: logrus.Fields{"latitude": 52.37, "longitude": "4.89"}})
+	point, ok := doc["client.geo.location"].(map[string]float64)
+	if !ok || point["lat"] != 52.37 || point["lon"] != 4.89 {
+		t.Errorf("unexpected geo point %v", doc["client.geo.location"])
+	}
+
+	for _, data := range []logrus.Fields{
+		{"latitude": 52.37},
+		{"latitude": 91, "longitude": 4.89},
+		{"latitude": "north", "longitude": 4.89},
+	} {
+		if doc := hook.document(&logrus.Entry{Data: data}); doc["client.geo.location"] != nil {
+			t.Errorf("expected no geo point for %v", data)
+		}
+	}
+
+	if m := fieldMapping("client.geo.location", hook.emittedFields()["client.geo.location"], false); m["type"] != "geo_point" {
+		t.Errorf("expected a geo_point mapping, got %v", m)
+	}
+}
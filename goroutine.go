@@ -0,0 +1,48 @@
+package elogrus
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// WithGoroutineInfo adds the ID of the goroutine
+// calling Fire ("Goroutine") and the pprof labels
+// of the entry context ("Labels") to documents
+func WithGoroutineInfo() Option {
+	return func(hook *ElasticHook) {
+		hook.goroutineInfo = true
+	}
+}
+
+// addGoroutineInfo adds the goroutine
+// fields of entry to doc
+func addGoroutineInfo(doc map[string]interface{}, entry *logrus.Entry) {
+	if id := goroutineID(); id != 0 {
+		doc["Goroutine"] = id
+	}
+	if labels := pprofLabels(entry.Context); len(labels) > 0 {
+		doc["Labels"] = labels
+	}
+}
+
+var goroutinePrefix = []byte("goroutine ")
+
+// goroutineID parses the ID of the current
+// goroutine from its stack header, which is
+// the only way the runtime exposes it
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, goroutinePrefix)
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
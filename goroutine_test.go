@@ -0,0 +1,29 @@
+package elogrus
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestGoroutineInfo(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithGoroutineInfo())
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("worker", "ingest"))
+
+	ids := make(chan interface{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			doc := hook.document(&logrus.Entry{Context: ctx})
+			if labels, _ := doc["Labels"].(map[string]string); labels["worker"] != "ingest" {
+				t.Errorf("expected pprof labels, got %v", doc["Labels"])
+			}
+			ids <- doc["Goroutine"]
+		}()
+	}
+	a, b := <-ids, <-ids
+	if a == nil || a == b {
+		t.Errorf("expected distinct goroutine ids, got %v and %v", a, b)
+	}
+}
@@ -0,0 +1,60 @@
+package elogrus
+
+import "net/http"
+
+// HeaderTransport is a http.RoundTripper
+// adding custom headers (tenant, tracing,
+// chargeback tags) to every request sent
+// to ElasticSearch. Install it with
+// elastic.SetHttpClient(&http.Client{Transport: t})
+type HeaderTransport struct {
+	// Transport used to send the request,
+	// http.DefaultTransport when nil
+	Transport http.RoundTripper
+	// Header is added to every request
+	Header http.Header
+	// HeaderFunc is called per request and
+	// its result is added after Header
+	HeaderFunc func(*http.Request) http.Header
+}
+
+// RoundTrip is required to implement
+// http.RoundTripper
+func (t *HeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var extra http.Header
+	if t.HeaderFunc != nil {
+		extra = t.HeaderFunc(req)
+	}
+	if len(t.Header) > 0 || len(extra) > 0 {
+		// RoundTrippers must not modify the caller's request
+		req = cloneRequest(req)
+		for _, h := range []http.Header{t.Header, extra} {
+			for k, vs := range h {
+				req.Header.Del(k)
+				for _, v := range vs {
+					req.Header.Add(k, v)
+				}
+			}
+		}
+	}
+	return t.transport().RoundTrip(req)
+}
+
+func (t *HeaderTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+// cloneRequest returns a shallow copy
+// of req with its own header map
+func cloneRequest(req *http.Request) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	return r
+}
@@ -0,0 +1,38 @@
+package elogrus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeaderTransport(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &HeaderTransport{
+		Header: http.Header{"X-Tenant": {"acme"}},
+		HeaderFunc: func(r *http.Request) http.Header {
+			return http.Header{"X-Path": {r.URL.Path}}
+		},
+	}}
+	req, _ := http.NewRequest("GET", srv.URL+"/logs/_bulk", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got.Get("X-Tenant") != "acme" {
+		t.Errorf("expected static header, got %q", got.Get("X-Tenant"))
+	}
+	if got.Get("X-Path") != "/logs/_bulk" {
+		t.Errorf("expected dynamic header, got %q", got.Get("X-Path"))
+	}
+	if req.Header.Get("X-Tenant") != "" {
+		t.Error("original request must not be modified")
+	}
+}
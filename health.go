@@ -0,0 +1,88 @@
+package elogrus
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+var (
+	// Fired if a lazy bootstrap is
+	// in progress on another request
+	ErrBootstrapPending = fmt.Errorf("Bootstrap in progress")
+)
+
+// minBootstrapBackoff and maxBootstrapBackoff
+// bound the wait after a failed bootstrap
+const (
+	minBootstrapBackoff = time.Second
+	maxBootstrapBackoff = time.Minute
+)
+
+// WithLazyBootstrap returns the hook without touching the
+// cluster, so services start while ElasticSearch is down:
+// the type is detected, templates installed and the index
+// created on the first request reaching the cluster, and
+// again after the client finds no node, for a recreated
+// cluster. One attempt runs at a time, failed ones are
+// retried after a backoff of up to a minute; entries
+// meanwhile fail as with any outage, so retries, the
+// spool and fallbacks apply. The self-test runs once.
+func WithLazyBootstrap() Option {
+	return func(hook *ElasticHook) {
+		hook.lazyBootstrap = true
+	}
+}
+
+// Ping checks that the cluster is reachable, and
+// bootstraps it for lazy hooks. Forwarding hooks
+// return the error of their last request.
+func (hook *ElasticHook) Ping() error {
+	root := hook.root()
+	if root.forwarder != nil {
+		last, _ := root.lastErr.Load().(lastError)
+		return last.err
+	}
+	return hook.do(func(client *elastic.Client) error {
+		ctx, cancel := hook.controlContext()
+		defer cancel()
+		_, err := client.PerformRequestC(ctx, "HEAD", "/", nil, nil)
+		return err
+	})
+}
+
+// Healthy reports whether the last request
+// reached the cluster, without a request
+func (hook *ElasticHook) Healthy() bool {
+	return atomic.LoadInt32(&hook.root().unhealthy) == 0
+}
+
+// unreachable reports errors telling
+// the cluster cannot be reached
+func unreachable(err error) bool {
+	return err == ErrBreakerOpen || isFatalClientError(err) || isTransient(err)
+}
+
+// recordHealth tracks whether the cluster
+// is reachable, warning when it is again
+func (hook *ElasticHook) recordHealth(err error) {
+	root := hook.root()
+	if !unreachable(err) {
+		if atomic.CompareAndSwapInt32(&root.unhealthy, 1, 0) {
+			root.lastErr.Store(lastError{})
+			hook.warn("ElasticSearch is reachable again")
+		}
+		return
+	}
+	root.lastErr.Store(lastError{err: err})
+	atomic.StoreInt32(&root.unhealthy, 1)
+}
+
+// lastError is stored in lastErr and
+// lastFailure, which need a single type
+type lastError struct {
+	err error
+	at  time.Time
+}
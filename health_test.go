@@ -0,0 +1,56 @@
+package elogrus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestLazyBootstrap(t *testing.T) {
+	// nothing is requested from the nil client
+	hook, err := NewElasticHook(nil, "localhost", logrus.DebugLevel, "test", WithLazyBootstrap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hook.Options().LazyBootstrap || hook.bootstrapped {
+		t.Errorf("expected a pending bootstrap, got %+v", hook.Options())
+	}
+}
+
+func TestHealthy(t *testing.T) {
+	logger := logrus.New()
+	rec := &recordingHook{}
+	logger.Hooks.Add(rec)
+	fwd := &flakyForwarder{err: &elastic.Error{Status: 503}}
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test", WithInternalLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hook.Healthy() || hook.Ping() != nil {
+		t.Error("expected a new hook to be healthy")
+	}
+	hook.Fire(&logrus.Entry{Message: "lost", Data: logrus.Fields{}})
+	if hook.Clone().Healthy() || hook.Ping() == nil {
+		t.Error("expected the hook to be unhealthy")
+	}
+
+	// errors of the documents do not
+	// tell the cluster is unreachable
+	fwd.err = errors.New("mapper_parsing_exception")
+	hook.Fire(&logrus.Entry{Message: "rejected", Data: logrus.Fields{}})
+	if !hook.Healthy() || hook.Ping() != nil {
+		t.Error("expected the hook to be healthy again")
+	}
+	var recovered int
+	for _, e := range rec.entries {
+		if e.Message == "ElasticSearch is reachable again" {
+			recovered++
+		}
+	}
+	if recovered != 1 {
+		t.Errorf("expected a warning on recovery, got %v", rec.entries)
+	}
+}
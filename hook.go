@@ -1,8 +1,14 @@
 package elogrus
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -14,15 +20,213 @@ var (
 	// Fired if the
 	// index is not created
 	ErrCannotCreateIndex = fmt.Errorf("Cannot create index")
+	// Fired if New is
+	// given no index
+	ErrNoIndex = fmt.Errorf("No index given")
 )
 
 // ElasticHook is a logrus
 // hook for ElasticSearch
 type ElasticHook struct {
-	client *elastic.Client
-	host   string
-	index  string
-	levels []logrus.Level
+	// counters are first to be 64-bit
+	// aligned for atomic access
+	deliveries uint64
+	sequence   uint64
+	// lastSequence is the last
+	// value of SequenceField
+	lastSequence int64
+	// delivery counters, see Stats
+	sent       int64
+	failed     int64
+	dropped    int64
+	retries    int64
+	flushes    int64
+	flushNanos int64
+	// schema violations, see WithSchema
+	schemaViolations int64
+	// lastFlush is when a document was
+	// last shipped, in Unix nanoseconds
+	lastFlush int64
+
+	// parent is the hook a clone
+	// shares the engine with
+	parent     *ElasticHook
+	mu         sync.Mutex
+	client     *elastic.Client
+	clientFunc ClientFunc
+	// lazyBootstrap is set by WithLazyBootstrap,
+	// bootstrapped once the cluster is prepared;
+	// booting while an attempt runs, failed ones
+	// are retried from bootRetry on, bootErr
+	// returned meanwhile
+	lazyBootstrap bool
+	bootstrapped  bool
+	booting       bool
+	bootBackoff   time.Duration
+	bootRetry     time.Time
+	bootErr       error
+	// selfTested is set once the
+	// self-test passed, see bootstrap
+	selfTested int32
+	// unhealthy is set while the cluster is
+	// unreachable, lastErr holds the error
+	unhealthy int32
+	lastErr   atomic.Value
+	// lastFailure is the last delivery
+	// error, see LastError
+	lastFailure atomic.Value
+	// flushing is the flush in flight, which
+	// may outlive a timed out Flush
+	flushMu  sync.Mutex
+	flushing *flushCall
+	// switchMu is held for reading by every
+	// request and for writing by SwitchIndex
+	switchMu sync.RWMutex
+	// parentCtx is the parent of ctx
+	// and controlCtx, set by WithContext
+	parentCtx context.Context
+	// ctx marks document writes made by the
+	// hook, cancel aborts them on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+	// controlCtx marks management requests,
+	// controlCancel aborts them on Close
+	controlCtx    context.Context
+	controlCancel context.CancelFunc
+	timeouts      TimeoutConfig
+	// quit stops background
+	// work on Close
+	quit     chan struct{}
+	quitOnce sync.Once
+	// err is the first invalid option
+	err error
+	// inFlight holds a token per request
+	inFlight chan struct{}
+
+	host  string
+	index string
+	typ   string
+	// typeless is set by WithTypeless
+	typeless bool
+	// dataStream is set by WithDataStream
+	dataStream bool
+	// levels is the mask of the
+	// levels shipped, see SetLevels
+	levels uint32
+
+	levelField       string
+	timestampField   string
+	timestampFormat  string
+	severityField    string
+	levelValueField  string
+	correlationField string
+	fieldTypes       map[string]FieldType
+	quarantineSuffix string
+	strictFields     map[string]bool
+	filters          []func(*logrus.Entry) bool
+	schema           *Schema
+	schemaConfig     SchemaConfig
+	goroutineInfo    bool
+	eventSequence    bool
+	// enrichment holds the fields of the enrichers,
+	// run at creation, and static the same fields
+	// marshaled once for encodeDocument
+	enrichers       []Enricher
+	enrichment      map[string]interface{}
+	static          map[string]staticField
+	originNamespace string
+	extraFields     []extraField
+	messageTemplate bool
+	instanceID      string
+	maxMessage      int
+	overflow        Overflow
+	ecs             bool
+	filebeat        *FilebeatConfig
+	errorDetails    bool
+	errorStack      bool
+	eventCategory   *EventCategory
+	caller          *CallerConfig
+	batchIDs        bool
+	createMappings  bool
+	pipeline        string
+	budget          *budget
+	sampler         *sampler
+	alertRules      []*alertRule
+	classifiers     []Classifier
+	staticFields    logrus.Fields
+	sizeWarnings    *sizeWarnings
+	idFunc          func(map[string]interface{}) string
+	sizing          *Sizing
+	enqueuePolicy   *enqueuePolicy
+	requestTimeout  time.Duration
+	// entryCancellation is set by
+	// WithEntryCancellation
+	entryCancellation bool
+	customMappings    json.RawMessage
+	pii               *piiScanner
+	fieldFilters      []FieldFilter
+	fieldLimits       *fieldLimiter
+	slo               *sloTracker
+	routingFunc       func(*logrus.Entry) string
+	// clusters are set by WithClusters
+	clusters       *clusterSet
+	docLimit       *docLimiter
+	flattener      *flattener
+	geoPoints      []GeoConfig
+	userAgentField string
+	messageFunc    MessageFunc
+	retry          *RetryConfig
+	onDiscard      func(json.RawMessage, error)
+	errorHandler   ErrorHandler
+	forwarder      Forwarder
+	spool          *spool
+	audit          *auditChain
+
+	breaker          *breaker
+	breakerStateFile string
+
+	bulk         *batcher
+	async        *asyncQueue
+	adaptive     *AdaptiveConfig
+	flushLevel   logrus.Level
+	flushOnLevel bool
+
+	router  func(*logrus.Entry) string
+	indices *indexCache
+	warmup  *WarmupConfig
+	// runtimeMetrics is set
+	// by WithRuntimeMetrics
+	runtimeMetrics *RuntimeMetricsConfig
+	// pattern is set by WithIndexPattern
+	pattern       indexPattern
+	patternSource string
+	retention     *retention
+	lease         *LeaseConfig
+	quota         *quota
+
+	writeOnly      bool
+	selfTest       bool
+	selfTestRemove bool
+	mappingCheck   bool
+	indexTemplate  *TemplateConfig
+
+	internalLogger *logrus.Logger
+	deliveryTrace  bool
+	localOutput    io.Writer
+	residue        io.Writer
+	stopTimeout    time.Duration
+	// fatal is set by WithFatalDelivery
+	fatal          *FatalConfig
+	onClusterEvent func(ClusterEvent)
+
+	fallbacks    []fallbackRoute
+	alerts       *alerter
+	throttle     *healthThrottle
+	localOnly    *localOnly
+	gaps         *gapReports
+	observer     Observer
+	metricLabels []string
+	volume       volume
 }
 
 // NewElasticHook creates new hook
@@ -30,77 +234,439 @@ type ElasticHook struct {
 // host - host of system
 // level - log level
 // index - name of the index in ElasticSearch
-func NewElasticHook(client *elastic.Client, host string, level logrus.Level, index string) (*ElasticHook, error) {
-	levels := []logrus.Level{}
-	for _, l := range []logrus.Level{
-		logrus.PanicLevel,
-		logrus.FatalLevel,
-		logrus.ErrorLevel,
-		logrus.WarnLevel,
-		logrus.InfoLevel,
-		logrus.DebugLevel,
-	} {
-		if l <= level {
-			levels = append(levels, l)
+// opts - optional settings, see Option
+func NewElasticHook(client *elastic.Client, host string, level logrus.Level, index string, opts ...Option) (*ElasticHook, error) {
+	return setupHook(client, newHook(client, host, level, index, opts...))
+}
+
+// New creates new hook configured by options,
+// WithIndex is required. The host defaults to
+// the hostname and the levels to all levels up
+// to debug, see WithHost and WithLevels.
+func New(client *elastic.Client, opts ...Option) (*ElasticHook, error) {
+	host, _ := os.Hostname()
+	hook := newHook(client, host, logrus.DebugLevel, "", opts...)
+	if hook.index == "" {
+		hook.optionErr(ErrNoIndex)
+	}
+	return setupHook(client, hook)
+}
+
+// setupHook prepares the
+// cluster for a new hook
+func setupHook(client *elastic.Client, hook *ElasticHook) (*ElasticHook, error) {
+	if hook.err != nil {
+		return nil, hook.err
+	}
+	if hook.lazyBootstrap {
+		return hook, nil
+	}
+	if err := hook.bootstrap(client); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// bootstrap prepares the cluster
+// for the documents of the hook
+func (hook *ElasticHook) bootstrap(client *elastic.Client) error {
+	hook.detectType(client)
+	if err := hook.installTemplate(client); err != nil {
+		return err
+	}
+	if err := hook.ensureIndex(client, hook.index); err != nil {
+		return err
+	}
+	if atomic.LoadInt32(&hook.selfTested) == 0 {
+		if err := hook.runSelfTest(client); err != nil {
+			return err
 		}
+		atomic.StoreInt32(&hook.selfTested, 1)
 	}
+	hook.checkMapping(client)
+	return nil
+}
 
+// ensureIndex creates the index if it does not
+// exist yet, data streams are created on write
+func (hook *ElasticHook) ensureIndex(client *elastic.Client, index string) error {
+	if hook.writeOnly || hook.dataStream {
+		return nil
+	}
+	ctx, cancel := hook.controlContext()
+	defer cancel()
 	// Use the IndexExists service to check if a specified index exists.
-	exists, err := client.IndexExists(index).Do()
+	exists, err := client.IndexExists(index).DoC(ctx)
 	if err != nil {
 		// Handle error
-		return nil, err
+		return err
 	}
 	if !exists {
-		createIndex, err := client.CreateIndex(index).Do()
+		create := client.CreateIndex(index)
+		if body := hook.createBody(); body != nil {
+			create = create.BodyJson(body)
+		}
+		createIndex, err := create.DoC(ctx)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if !createIndex.Acknowledged {
-			return nil, ErrCannotCreateIndex
+			return ErrCannotCreateIndex
 		}
+		hook.clusterEvent(ClusterEvent{Kind: IndexCreated, Index: index})
 	}
+	return nil
+}
+
+// newHook sets up the hook
+// without touching the cluster
+func newHook(client *elastic.Client, host string, level logrus.Level, index string, opts ...Option) *ElasticHook {
+	hook := &ElasticHook{
+		parentCtx:      context.Background(),
+		quit:           make(chan struct{}),
+		client:         client,
+		host:           host,
+		index:          index,
+		levels:         levelMask(levelsUpTo(level)),
+		levelField:     "Level",
+		timestampField: "Timestamp",
+		indices:        newIndexCache(time.Hour, time.Minute),
 
-	return &ElasticHook{
-		client: client,
-		host:   host,
-		index:  index,
-		levels: levels,
-	}, nil
+		originNamespace: DefaultOriginNamespace,
+	}
+	for _, opt := range opts {
+		opt(hook)
+	}
+	parent := internalContext(hook.parentCtx)
+	hook.ctx, hook.cancel = context.WithCancel(parent)
+	hook.controlCtx, hook.controlCancel = context.WithCancel(parent)
+	hook.restoreBreaker()
+	hook.enrich()
+	if hook.spool != nil {
+		if err := hook.spool.open(); err != nil {
+			hook.optionErr(err)
+		}
+	}
+	if hook.retention != nil {
+		if err := hook.retention.setup(hook.pattern); err != nil {
+			hook.optionErr(err)
+		}
+	}
+	hook.applyEnqueuePolicy()
+	if hook.bulk != nil {
+		hook.bulk.start()
+	}
+	if hook.async != nil {
+		hook.async.start(hook)
+	}
+	if hook.warmup != nil {
+		go hook.runWarmup()
+	}
+	if hook.retention != nil && hook.err == nil && !hook.writeOnly {
+		go hook.runRetention()
+	}
+	if hook.spool != nil && hook.err == nil {
+		go hook.runSpool()
+	}
+	if hook.runtimeMetrics != nil && hook.err == nil {
+		go hook.runRuntimeMetrics()
+	}
+	if hook.throttle != nil && hook.err == nil && hook.forwarder == nil && !hook.writeOnly {
+		go hook.runHealthThrottle()
+	}
+	return hook
 }
 
 // Fire is required to implement
 // Logrus hook
 func (hook *ElasticHook) Fire(entry *logrus.Entry) error {
+	return hook.fire(entry, nil)
+}
 
-	level := entry.Level.String()
+// fire ships entry, done is called
+// with its outcome when it is known
+func (hook *ElasticHook) fire(entry *logrus.Entry, done func(error)) error {
+	if hook.err != nil {
+		resolve(done, hook.err)
+		return hook.err
+	}
+	if !hook.levelEnabled(entry.Level) {
+		resolve(done, ErrDropped)
+		return nil
+	}
+	if isReentrant(entry) {
+		hook.printLocal(entry)
+		resolve(done, ErrDropped)
+		return nil
+	}
+	entry = addContextFields(entry)
+	if hook.filtered(entry) {
+		resolve(done, ErrDropped)
+		return nil
+	}
+	schemaErr := hook.checkSchema(entry)
+	entry = hook.checkAgainstSchema(entry)
+	entry = hook.addStaticFields(entry)
+	entry, verdict := hook.classify(entry)
+	hook.evaluateRules(entry)
+	id := hook.deliveryID()
+	if schemaErr != nil {
+		hook.reject(entry, id, schemaErr)
+		resolve(done, schemaErr)
+		return schemaErr
+	}
+	if hook.fatal != nil && entry.Level <= logrus.FatalLevel && !verdict.Drop {
+		return hook.fireFatal(entry, id, done)
+	}
+	s, kept := sample{}, true
+	if !verdict.Keep {
+		s, kept = hook.sampledIn(entry)
+	}
+	if verdict.Drop || !kept || !hook.withinQuota(entry) || !hook.withinBudget(entry) {
+		hook.trace(id, "dropped", ErrDropped)
+		resolve(done, ErrDropped)
+		return nil
+	}
+	if hook.writeLocal(entry, id, done) {
+		return nil
+	}
+	if hook.bulk != nil {
+		return hook.fireBulk(entry, id, s, done)
+	}
+	if hook.async != nil {
+		return hook.fireAsync(entry, id, s, done)
+	}
+	index := hook.indexFor(entry)
+	ctx, cancel := hook.fireContext(entry)
+	defer cancel()
+	docs := hook.stampedDocuments(entry, s)
+	return hook.deliver(asyncItem{
+		id:      id,
+		level:   entry.Level,
+		index:   index,
+		routing: hook.routing(entry),
+		docs:    docs,
+		ids:     hook.documentIDs(docs),
+		labels:  hook.labels(entry, index),
+		fired:   time.Now(),
+		ctx:     ctx,
+		done:    done,
+	})
+}
+
+// deliver indexes the documents of an entry
+// and reports the outcome
+func (hook *ElasticHook) deliver(item asyncItem) error {
+	if item.ctx == nil {
+		var cancel context.CancelFunc
+		item.ctx, cancel = hook.fireContext(nil)
+		defer cancel()
+	}
+	sent := 0
+	hook.trace(item.id, "sent", nil)
+	var err error
+	for attempt := 0; ; attempt++ {
+		hook.pace(item.ctx, len(item.docs)-sent)
+		if hook.forwarder != nil {
+			var n int
+			n, err = hook.forward(item.ctx, item.index, item.routing, item.docs[sent:], item.ids[sent:])
+			sent += n
+		} else {
+			err = hook.do(func(client *elastic.Client) error {
+				n, err := hook.send(item.ctx, client, item.index, item.routing, item.docs[sent:], item.ids[sent:])
+				sent += n
+				return err
+			})
+		}
+		hook.throttled(err)
+		if !hook.backoffContext(item.ctx, item.id, attempt, err) {
+			break
+		}
+	}
+	if hook.forwarder == nil {
+		hook.mirror(item.ctx, item.index, item.routing, item.docs, item.ids)
+	}
+	if err != nil {
+		lost := 0
+		for i, doc := range item.docs[sent:] {
+			r := spoolRecord{Index: item.index, ID: item.ids[sent+i], Pipeline: hook.pipeline, Routing: item.routing, Create: hook.dataStream, Level: uint32(item.level)}
+			if hook.spoolDoc(r, doc, err) {
+				continue
+			}
+			hook.fallback(item.level, doc)
+			hook.discard(doc, err)
+			lost++
+		}
+		hook.lost(item.level, lost)
+	} else {
+		hook.delivered()
+	}
+	hook.observe(item.labels, err)
+	hook.recordSLO(err, item.fired)
+	hook.traceOutcome(item.id, err)
+	resolve(item.done, err)
+	return err
+}
 
-	msg := struct {
-		Host      string
-		Timestamp string
-		Message   string
-		Data      logrus.Fields
-		Level     string
-	}{
-		hook.host,
-		entry.Time.UTC().Format(time.RFC3339Nano),
-		entry.Message,
-		entry.Data,
-		strings.ToUpper(level),
-	}
-
-	_, err := hook.client.
-		Index().
-		Index(hook.index).
-		Type("log").
-		BodyJson(msg).
-		Do()
+// do runs fn with the client,
+// guarded by the circuit breaker
+func (hook *ElasticHook) do(fn func(*elastic.Client) error) error {
+	if hook.parent != nil {
+		return hook.parent.do(fn)
+	}
+	if hook.breaker != nil && !hook.breaker.allow() {
+		hook.available(ErrBreakerOpen)
+		return ErrBreakerOpen
+	}
 
+	client, err := hook.clusterClient()
+	if err == nil {
+		err = hook.acquire()
+	}
+	if err == nil {
+		hook.switchMu.RLock()
+		err = fn(client)
+		hook.switchMu.RUnlock()
+		hook.release()
+		if s := hook.clusters; s != nil {
+			s.done(client, err)
+		}
+		if s := hook.clusters; s == nil || s.find(client) == s.clusters[0] {
+			hook.checkClient(client, err)
+		}
+	}
+	if hook.breaker != nil {
+		hook.breaker.done(err)
+	}
+	hook.available(err)
 	return err
 }
 
-// Required for logrus
-// hook implementation
+// send indexes the documents of a single entry
+// with ids, returning how many were indexed
+func (hook *ElasticHook) send(ctx context.Context, client *elastic.Client, index, routing string, docs []map[string]interface{}, ids []string) (int, error) {
+	if err := hook.ensureRouted(client, index); err != nil {
+		return 0, err
+	}
+
+	for i, doc := range docs {
+		body, err := encodeDocument(doc, hook.static)
+		if err != nil {
+			return i, err
+		}
+		ctx, cancel := withTimeout(ctx, hook.timeouts.Data)
+		id := ids[i]
+		if hook.pipeline != "" {
+			err = hook.indexPipelined(ctx, client, index, routing, hook.pipeline, body, id, hook.creates(id))
+		} else {
+			req := client.
+				Index().
+				Index(index).
+				Type(hook.docType()).
+				BodyString(string(body))
+			if id != "" {
+				req.Id(id)
+			}
+			if routing != "" {
+				req.Routing(routing)
+			}
+			if hook.creates(id) {
+				req.OpType("create")
+			}
+			_, err = req.DoC(ctx)
+		}
+		cancel()
+		if err != nil && !isStatus(err, 409) {
+			return i, err
+		}
+		hook.shipped(index, len(body))
+	}
+
+	return len(docs), nil
+}
+
+// document builds the body indexed
+// for a single entry
+func (hook *ElasticHook) document(entry *logrus.Entry) map[string]interface{} {
+	level := entry.Level.String()
+
+	// sized up front so optional and
+	// registered fields do not grow it
+	doc := make(map[string]interface{}, 8+len(hook.extraFields))
+	if hook.filebeat != nil {
+		hook.filebeatDocument(doc, entry)
+	} else if hook.ecs {
+		hook.ecsDocument(doc, entry)
+	} else {
+		doc[hook.originField("Host", "host")] = hook.host
+		doc[hook.timestampField] = hook.timestamp(entry.Time)
+		doc["Message"] = entry.Message
+		doc["Data"] = hook.fields(entry)
+		doc[hook.levelField] = strings.ToUpper(level)
+	}
+	if t, ok := hook.template(entry); ok {
+		doc["MessageTemplate"] = t
+	}
+	if hook.severityField != "" {
+		doc[hook.severityField] = Severity(entry.Level)
+	}
+	if hook.levelValueField != "" {
+		doc[hook.levelValueField] = uint32(entry.Level)
+	}
+	if hook.correlationField != "" {
+		doc[hook.correlationField] = hook.correlationID(entry)
+	}
+	hook.addErrorDetails(doc, entry)
+	hook.limitErrorDetails(doc)
+	hook.addEventCategory(doc, entry)
+	hook.addCaller(doc, entry)
+	hook.addOriginFields(doc)
+	addTraceFields(doc, entry)
+	hook.addGeoPoints(doc, entry)
+	hook.addUserAgent(doc, entry)
+	if hook.goroutineInfo {
+		addGoroutineInfo(doc, entry)
+	}
+	if hook.eventSequence {
+		doc[SequenceField] = hook.nextSequence(entry.Time)
+	}
+	for k, v := range hook.enrichment {
+		doc[k] = v
+	}
+	for _, f := range hook.extraFields {
+		doc[f.name] = f.value(entry)
+	}
+	return doc
+}
+
+// fields returns the entry data, copied when
+// it is changed for the document or, in bulk and
+// async mode, only serialized when it is sent
+func (hook *ElasticHook) fields(entry *logrus.Entry) logrus.Fields {
+	_, template := hook.template(entry)
+	namespaced := hook.namespace() != "" && len(hook.staticFields) > 0
+	if hook.bulk == nil && hook.async == nil && hook.fieldTypes == nil && !template && !namespaced && !hasErrors(entry.Data) {
+		return entry.Data
+	}
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	if template {
+		delete(data, TemplateField)
+	}
+	if namespaced {
+		hook.removeStaticFields(data)
+	}
+	errorStrings(data)
+	hook.coerceFields(data)
+	return data
+}
+
+// Levels is required to implement Logrus hook,
+// it returns all levels since logrus reads them
+// once; entries at levels not enabled with
+// WithLevels or SetLevels are skipped
 func (hook *ElasticHook) Levels() []logrus.Level {
-	return hook.levels
+	return append([]logrus.Level(nil), allLevels...)
 }
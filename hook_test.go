@@ -15,7 +15,11 @@ func TestHook(t *testing.T) {
 		log.Panic(err)
 	}
 
-	logrus.AddHook(NewElasticHook(client, "localhost", logrus.DebugLevel, "goplag"))
+	hook, err := NewElasticHook(client, "localhost", logrus.DebugLevel, "goplag")
+	if err != nil {
+		log.Panic(err)
+	}
+	logrus.AddHook(hook)
 
 	for index := 0; index < 1000; index++ {
 		logrus.Infof("Hustej msg %d", time.Now().Unix())
@@ -0,0 +1,215 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// TemplateConfig configures the index
+// template installed by the hook
+type TemplateConfig struct {
+	// Name of the index template, the component
+	// template holding the hook mapping is
+	// Name + "-mappings"; default the hook index
+	Name string
+	// Patterns the template applies to,
+	// default the hook index followed by *
+	Patterns []string
+	// Components are component templates
+	// composed after the hook mapping, e.g.
+	// with settings or mappings of your fields
+	Components []string
+	// Priority of the index template
+	Priority int
+	// Legacy installs a legacy template (clusters
+	// before 7.8) instead of composable ones
+	Legacy bool
+	// Body is a custom template, put as is
+	// instead of the generated ones; Patterns,
+	// Components and Priority are not used
+	Body json.RawMessage
+}
+
+// WithIndexTemplate installs the mapping of the
+// fields the hook sends before the index is
+// created: as a component template combined with
+// config.Components in a composable index template
+// (ElasticSearch 7.8+), or as a legacy template
+func WithIndexTemplate(config TemplateConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Name == "" {
+			config.Name = hook.index
+		}
+		if len(config.Patterns) == 0 {
+			config.Patterns = []string{hook.index + "*"}
+		}
+		hook.indexTemplate = &config
+	}
+}
+
+// installTemplate puts the
+// configured templates
+func (hook *ElasticHook) installTemplate(client *elastic.Client) error {
+	if hook.indexTemplate == nil || hook.writeOnly {
+		return hook.ensureStreamTemplate(client)
+	}
+	return hook.putTemplate(client, hook.indexTemplate)
+}
+
+// putTemplate puts the templates of t
+func (hook *ElasticHook) putTemplate(client *elastic.Client, t *TemplateConfig) error {
+	ctx, cancel := hook.controlContext()
+	defer cancel()
+	if t.Body != nil {
+		path := "/_index_template/"
+		if t.Legacy {
+			path = "/_template/"
+		}
+		_, err := client.PerformRequestC(ctx, "PUT", path+t.Name, nil, t.Body)
+		if err != nil {
+			return err
+		}
+		hook.clusterEvent(ClusterEvent{Kind: TemplateInstalled, Index: t.Name})
+		return nil
+	}
+	if t.Legacy {
+		if hook.dataStream {
+			return ErrLegacyDataStream
+		}
+		body := map[string]interface{}{
+			"template": strings.Join(t.Patterns, ","),
+			"order":    t.Priority,
+			"mappings": map[string]interface{}{
+				hook.docType(): map[string]interface{}{"properties": hook.templateProperties(true)},
+			},
+		}
+		if hook.typeless {
+			body = map[string]interface{}{
+				"index_patterns": t.Patterns,
+				"order":          t.Priority,
+				"mappings":       map[string]interface{}{"properties": hook.templateProperties(false)},
+			}
+		}
+		_, err := client.PerformRequestC(ctx, "PUT", "/_template/"+t.Name, nil, body)
+		if err != nil {
+			return err
+		}
+		hook.clusterEvent(ClusterEvent{Kind: TemplateInstalled, Index: t.Name})
+		return nil
+	}
+
+	component := t.Name + "-mappings"
+	_, err := client.PerformRequestC(ctx, "PUT", "/_component_template/"+component, nil, map[string]interface{}{
+		"template": map[string]interface{}{
+			"mappings": map[string]interface{}{"properties": hook.templateProperties(false)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	hook.clusterEvent(ClusterEvent{Kind: TemplateInstalled, Index: component})
+
+	body := map[string]interface{}{
+		"index_patterns": t.Patterns,
+		"composed_of":    append([]string{component}, t.Components...),
+		"priority":       t.Priority,
+	}
+	if hook.dataStream {
+		body["data_stream"] = map[string]interface{}{}
+	}
+	_, err = client.PerformRequestC(ctx, "PUT", "/_index_template/"+t.Name, nil, body)
+	if err != nil {
+		return err
+	}
+	hook.clusterEvent(ClusterEvent{Kind: TemplateInstalled, Index: t.Name})
+	return nil
+}
+
+// WithIndexMappings creates the indices of the hook
+// with explicit mappings, for clusters where templates
+// cannot be installed: custom, or when nil the mapping
+// of the fields the hook sends, so Timestamp is a date
+// and Level and the host are keywords
+func WithIndexMappings(custom json.RawMessage) Option {
+	return func(hook *ElasticHook) {
+		hook.createMappings = true
+		hook.customMappings = custom
+	}
+}
+
+// createBody returns the body creating
+// an index, nil without mappings
+func (hook *ElasticHook) createBody() interface{} {
+	if !hook.createMappings {
+		return nil
+	}
+	if hook.customMappings != nil {
+		return hook.customMappings
+	}
+	if hook.typeless {
+		return map[string]interface{}{
+			"mappings": map[string]interface{}{"properties": hook.templateProperties(false)},
+		}
+	}
+	return map[string]interface{}{
+		"mappings": map[string]interface{}{
+			hook.docType(): map[string]interface{}{"properties": hook.templateProperties(true)},
+		},
+	}
+}
+
+// templateProperties returns the mapping of the
+// emitted fields, dotted names become objects.
+// legacy selects ElasticSearch 2.x string types.
+func (hook *ElasticHook) templateProperties(legacy bool) map[string]interface{} {
+	props := map[string]interface{}{}
+	for name, kind := range hook.emittedFields() {
+		parent := props
+		parts := strings.Split(name, ".")
+		for _, part := range parts[:len(parts)-1] {
+			obj, ok := parent[part].(map[string]interface{})
+			if !ok {
+				obj = map[string]interface{}{}
+				parent[part] = obj
+			}
+			sub, ok := obj["properties"].(map[string]interface{})
+			if !ok {
+				sub = map[string]interface{}{}
+				obj["properties"] = sub
+			}
+			parent = sub
+		}
+		leaf := parts[len(parts)-1]
+		if existing, ok := parent[leaf].(map[string]interface{}); ok && kind == "object" {
+			// already holds properties
+			existing["type"] = "object"
+			continue
+		}
+		parent[leaf] = fieldMapping(name, kind, legacy)
+	}
+	return props
+}
+
+func fieldMapping(name, kind string, legacy bool) map[string]interface{} {
+	switch {
+	case kind == "string" && isMessage(name) && legacy:
+		return map[string]interface{}{"type": "string"}
+	case kind == "string" && isMessage(name):
+		return map[string]interface{}{"type": "text"}
+	case kind == "string" && legacy:
+		return map[string]interface{}{"type": "string", "index": "not_analyzed"}
+	case kind == "string":
+		return map[string]interface{}{"type": "keyword"}
+	case kind == "geo":
+		return map[string]interface{}{"type": "geo_point"}
+	}
+	return map[string]interface{}{"type": kind}
+}
+
+// isMessage reports fields holding
+// free text, analyzed for search
+func isMessage(name string) bool {
+	return name == "Message" || name == "message" || name == "error.message"
+}
@@ -0,0 +1,67 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestTemplateProperties(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "logs",
+		WithSeverityField("severity"),
+		WithFieldTypes(map[string]FieldType{"user_id": StringField}),
+		WithIndexTemplate(TemplateConfig{Components: []string{"logs-settings"}}),
+	)
+	if hook.indexTemplate.Name != "logs" || hook.indexTemplate.Patterns[0] != "logs*" {
+		t.Errorf("unexpected defaults %+v", hook.indexTemplate)
+	}
+
+	b, _ := json.Marshal(hook.templateProperties(false))
+	var props map[string]map[string]interface{}
+	json.Unmarshal(b, &props)
+	for name, typ := range map[string]string{"Message": "text", "Level": "keyword", "Timestamp": "date", "severity": "long", "Data": "object"} {
+		if props[name]["type"] != typ {
+			t.Errorf("expected %s to be mapped as %s, got %v", name, typ, props[name])
+		}
+	}
+	data := props["Data"]["properties"].(map[string]interface{})
+	if data["user_id"].(map[string]interface{})["type"] != "keyword" {
+		t.Errorf("unexpected Data mapping %v", data)
+	}
+	trace := props["trace"]["properties"].(map[string]interface{})
+	if trace["id"].(map[string]interface{})["type"] != "keyword" {
+		t.Errorf("unexpected trace mapping %v", trace)
+	}
+
+	legacy := hook.templateProperties(true)
+	if m := legacy["Level"].(map[string]interface{}); m["type"] != "string" || m["index"] != "not_analyzed" {
+		t.Errorf("unexpected legacy mapping %v", m)
+	}
+}
+
+func TestCreateBody(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "logs")
+	if hook.createBody() != nil {
+		t.Error("indices are created bare by default")
+	}
+
+	hook = newHook(nil, "localhost", logrus.DebugLevel, "logs", WithIndexMappings(nil), WithDocumentType("log"), WithFlatOrigin())
+	b, _ := json.Marshal(hook.createBody())
+	var body struct {
+		Mappings map[string]struct {
+			Properties map[string]map[string]interface{}
+		}
+	}
+	json.Unmarshal(b, &body)
+	props := body.Mappings["log"].Properties
+	if props["Timestamp"]["type"] != "date" || props["Host"]["index"] != "not_analyzed" {
+		t.Errorf("unexpected mappings %s", b)
+	}
+
+	custom := json.RawMessage(`{"settings":{"number_of_shards":1}}`)
+	hook = newHook(nil, "localhost", logrus.DebugLevel, "logs", WithIndexMappings(custom))
+	if b, _ := json.Marshal(hook.createBody()); string(b) != string(custom) {
+		t.Errorf("expected the custom body, got %s", b)
+	}
+}
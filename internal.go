@@ -0,0 +1,115 @@
+package elogrus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// InternalField tags entries
+// generated by the hook itself
+const InternalField = "elogrus_internal"
+
+// WithInternalLogger reports errors which cannot be
+// returned from Fire (background flushes) to logger
+// instead of stderr. The entries are tagged with
+// InternalField and carry the error wrapped so that
+// a hook receiving them, or an application logging
+// the error again, is detected and the entry dropped.
+func WithInternalLogger(logger *logrus.Logger) Option {
+	return func(hook *ElasticHook) {
+		hook.internalLogger = logger
+	}
+}
+
+// WithLocalOutput prints re-entrant entries
+// to w instead of silently dropping them
+func WithLocalOutput(w io.Writer) Option {
+	return func(hook *ElasticHook) {
+		hook.localOutput = w
+	}
+}
+
+// internalError marks errors
+// produced by the hook
+type internalError struct {
+	err error
+}
+
+func (e *internalError) Error() string { return e.err.Error() }
+func (e *internalError) Unwrap() error { return e.err }
+
+// reportError handles errors which
+// cannot be returned from Fire
+func (hook *ElasticHook) reportError(err error) {
+	if hook.errorHandler != nil {
+		hook.errorHandler(err, nil)
+		return
+	}
+	if hook.internalLogger == nil {
+		fmt.Fprintf(os.Stderr, "Failed to send logs to ElasticSearch: %v\n", err)
+		return
+	}
+	hook.internalLogger.
+		WithField(InternalField, true).
+		WithError(&internalError{err: err}).
+		Error("Failed to send logs to ElasticSearch")
+}
+
+// warn reports a problem which
+// does not stop the hook
+func (hook *ElasticHook) warn(msg string) {
+	if hook.internalLogger == nil {
+		fmt.Fprintln(os.Stderr, msg)
+		return
+	}
+	hook.internalLogger.WithField(InternalField, true).Warn(msg)
+}
+
+// isReentrant reports entries generated by the
+// hook or carrying one of its errors, shipping
+// them could start a feedback loop
+func isReentrant(entry *logrus.Entry) bool {
+	if _, ok := entry.Data[InternalField]; ok {
+		return true
+	}
+	for _, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			var ie *internalError
+			if errors.As(err, &ie) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// printLocal writes a dropped
+// entry to the local output
+func (hook *ElasticHook) printLocal(entry *logrus.Entry) {
+	if hook.localOutput == nil {
+		return
+	}
+	fmt.Fprintf(hook.localOutput, "%s [%s] %s %v\n",
+		entry.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		entry.Level, entry.Message, entry.Data)
+}
+
+type internalKey struct{}
+
+// internalContext marks ctx as belonging to
+// a request made by the hook itself
+func internalContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalKey{}, true)
+}
+
+// isInternal reports whether ctx
+// belongs to the hook's own traffic
+func isInternal(ctx context.Context) bool {
+	internal, _ := ctx.Value(internalKey{}).(bool)
+	return internal
+}
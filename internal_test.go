@@ -0,0 +1,45 @@
+package elogrus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestReentrantEntriesDropped(t *testing.T) {
+	var local bytes.Buffer
+	logger := logrus.New()
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithInternalLogger(logger),
+		WithLocalOutput(&local),
+	)
+	logger.Hooks.Add(hook)
+
+	// the hook must not touch the (nil) client for its own errors
+	hook.reportError(errors.New("bulk failed"))
+	if !strings.Contains(local.String(), "Failed to send logs") {
+		t.Errorf("expected the internal entry to be printed locally, got %q", local.String())
+	}
+}
+
+func TestIsReentrant(t *testing.T) {
+	wrapped := fmt.Errorf("shipping: %w", &internalError{err: errors.New("timeout")})
+	cases := []struct {
+		data logrus.Fields
+		want bool
+	}{
+		{logrus.Fields{"user": "joe"}, false},
+		{logrus.Fields{InternalField: true}, true},
+		{logrus.Fields{logrus.ErrorKey: wrapped}, true},
+		{logrus.Fields{logrus.ErrorKey: errors.New("app error")}, false},
+	}
+	for _, c := range cases {
+		if got := isReentrant(&logrus.Entry{Data: c.data}); got != c.want {
+			t.Errorf("isReentrant(%v) = %v, want %v", c.data, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// QueueDepth returns the number of documents
+// waiting in the bulk queue, or entries in
+// the async one, as in Stats
+func (hook *ElasticHook) QueueDepth() int {
+	root := hook.root()
+	depth := 0
+	if root.bulk != nil {
+		root.bulk.mu.Lock()
+		depth += len(root.bulk.pending)
+		root.bulk.mu.Unlock()
+	}
+	if root.async != nil {
+		depth += len(root.async.ch)
+	}
+	return depth
+}
+
+// LastFlushTime returns when a document was last
+// accepted by ElasticSearch or the forwarder,
+// zero if none was yet
+func (hook *ElasticHook) LastFlushTime() time.Time {
+	nanos := atomic.LoadInt64(&hook.root().lastFlush)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// LastError returns when the last entry
+// which failed to be delivered failed, and
+// its error, nil if none did
+func (hook *ElasticHook) LastError() (time.Time, error) {
+	last, _ := hook.root().lastFailure.Load().(lastError)
+	return last.at, last.err
+}
+
+// recordFailure records the error
+// of an undelivered entry
+func (hook *ElasticHook) recordFailure(err error) {
+	hook.root().lastFailure.Store(lastError{err: err, at: time.Now()})
+}
+
+// Introspection is the state of a
+// hook served by IntrospectionHandler
+type Introspection struct {
+	QueueDepth int        `json:"queue_depth"`
+	LastFlush  *time.Time `json:"last_flush,omitempty"`
+	// LastError is the message of
+	// the last delivery error
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorTime *time.Time `json:"last_error_time,omitempty"`
+	Healthy       bool       `json:"healthy"`
+	Stats         Stats      `json:"stats"`
+}
+
+// Introspect returns the state of the hook
+func (hook *ElasticHook) Introspect() Introspection {
+	i := Introspection{
+		QueueDepth: hook.QueueDepth(),
+		Healthy:    hook.Healthy(),
+		Stats:      hook.Stats(),
+	}
+	if t := hook.LastFlushTime(); !t.IsZero() {
+		i.LastFlush = &t
+	}
+	if t, err := hook.LastError(); err != nil {
+		i.LastError = err.Error()
+		i.LastErrorTime = &t
+	}
+	return i
+}
+
+// IntrospectionHandler serves the Introspection
+// of hook as JSON, e.g. for debugging dashboards;
+// it exposes error messages, so mount it on
+// an internal listener only
+func IntrospectionHandler(hook *ElasticHook) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hook.Introspect())
+	})
+}
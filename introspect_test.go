@@ -0,0 +1,56 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestIntrospection(t *testing.T) {
+	fwd := &flakyForwarder{}
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{Actions: 100, FlushInterval: time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	if !hook.LastFlushTime().IsZero() {
+		t.Error("expected no flush yet")
+	}
+	hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "queued", Data: logrus.Fields{}})
+	if hook.QueueDepth() != 1 {
+		t.Errorf("expected 1 queued document, got %d", hook.QueueDepth())
+	}
+	hook.Flush()
+	if hook.QueueDepth() != 0 || hook.LastFlushTime().IsZero() {
+		t.Errorf("expected the flush to be recorded, depth %d", hook.QueueDepth())
+	}
+
+	fwd.mu.Lock()
+	fwd.err = errors.New("refused")
+	fwd.mu.Unlock()
+	hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "lost", Data: logrus.Fields{}})
+	hook.Flush()
+	if at, err := hook.LastError(); err == nil || at.IsZero() {
+		t.Errorf("expected the last error, got %v at %v", err, at)
+	}
+
+	rec := httptest.NewRecorder()
+	IntrospectionHandler(hook).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	var body struct {
+		QueueDepth int        `json:"queue_depth"`
+		LastFlush  *time.Time `json:"last_flush"`
+		LastError  string     `json:"last_error"`
+		Stats      Stats      `json:"stats"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.LastFlush == nil || body.LastError == "" || body.Stats.Sent != 1 || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected introspection %s", rec.Body)
+	}
+}
@@ -0,0 +1,23 @@
+package elogrus
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// pprofLabels returns the
+// profiler labels of ctx
+func pprofLabels(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	var labels map[string]string
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = value
+		return true
+	})
+	return labels
+}
@@ -0,0 +1,144 @@
+package elogrus
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// ClientFunc creates the ElasticSearch
+// client used by a lazy hook
+type ClientFunc func() (*elastic.Client, error)
+
+// NewLazyElasticHook creates new hook whose
+// client is obtained from factory on the
+// first Fire instead of at construction.
+// The index is checked once the client is
+// created, and factory is invoked again
+// after the client reports that no node
+// is available. Invalid options are
+// returned by Fire.
+// factory - creates the ElasticSearch client
+// host - host of system
+// level - log level
+// index - name of the index in ElasticSearch
+// opts - optional settings, see Option
+func NewLazyElasticHook(factory ClientFunc, host string, level logrus.Level, index string, opts ...Option) *ElasticHook {
+	hook := newHook(nil, host, level, index, opts...)
+	hook.clientFunc = factory
+	return hook
+}
+
+// getClient returns the client,
+// creating it when the hook is lazy
+func (hook *ElasticHook) getClient() (*elastic.Client, error) {
+	if hook.parent != nil {
+		return hook.parent.getClient()
+	}
+	hook.mu.Lock()
+	pending := hook.lazyBootstrap && !hook.bootstrapped
+	if (hook.client != nil && !pending) || (hook.client == nil && hook.clientFunc == nil) {
+		client := hook.client
+		hook.mu.Unlock()
+		return client, nil
+	}
+	if hook.booting {
+		hook.mu.Unlock()
+		return nil, ErrBootstrapPending
+	}
+	if time.Now().Before(hook.bootRetry) {
+		err := hook.bootErr
+		hook.mu.Unlock()
+		return nil, err
+	}
+	// the others fail fast meanwhile
+	hook.booting = true
+	client := hook.client
+	hook.mu.Unlock()
+
+	var err error
+	created := client == nil
+	if created {
+		client, err = hook.clientFunc()
+	}
+	if err == nil {
+		err = hook.bootstrap(client)
+	}
+	if err != nil && created && client != nil {
+		// the next attempt invokes the factory again
+		if !releaseShared(client) {
+			client.Stop()
+		}
+	}
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+	hook.booting = false
+	if err != nil {
+		if client != nil {
+			hook.bootFailed(err)
+		}
+		return nil, err
+	}
+	hook.client = client
+	hook.bootstrapped = true
+	hook.bootBackoff, hook.bootRetry, hook.bootErr = 0, time.Time{}, nil
+	return client, nil
+}
+
+// bootFailed delays the next bootstrap after err,
+// doubling the backoff; factory errors are not
+// delayed. It needs hook.mu.
+func (hook *ElasticHook) bootFailed(err error) {
+	hook.bootBackoff *= 2
+	if hook.bootBackoff < minBootstrapBackoff {
+		hook.bootBackoff = minBootstrapBackoff
+	}
+	if hook.bootBackoff > maxBootstrapBackoff {
+		hook.bootBackoff = maxBootstrapBackoff
+	}
+	hook.bootRetry = time.Now().Add(hook.bootBackoff)
+	hook.bootErr = err
+}
+
+// checkClient drops a lazily created client
+// after a fatal error so the next Fire
+// invokes the factory again, clients of
+// SharedClientFunc are released
+func (hook *ElasticHook) checkClient(client *elastic.Client, err error) {
+	if !isFatalClientError(err) {
+		return
+	}
+	if hook.lazyBootstrap {
+		// bootstrapped again once the
+		// cluster is reachable
+		hook.mu.Lock()
+		hook.bootstrapped = false
+		hook.mu.Unlock()
+	}
+	if hook.clientFunc == nil {
+		return
+	}
+	hook.mu.Lock()
+	dropped := hook.client == client
+	if dropped {
+		hook.client = nil
+	}
+	hook.mu.Unlock()
+	if !dropped {
+		return
+	}
+	// shared clients are only stopped
+	// once their users released them
+	if !releaseShared(client) {
+		client.Stop()
+	}
+}
+
+// isFatalClientError reports errors after
+// which the client will not recover
+func isFatalClientError(err error) bool {
+	return err == elastic.ErrNoClient || err == elastic.ErrRetry
+}
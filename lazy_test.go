@@ -0,0 +1,53 @@
+package elogrus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestLazyHookFactoryError(t *testing.T) {
+	calls := 0
+	factoryErr := errors.New("no socket yet")
+	hook := NewLazyElasticHook(func() (*elastic.Client, error) {
+		calls++
+		return nil, factoryErr
+	}, "localhost", logrus.InfoLevel, "lazy")
+
+	if calls != 0 {
+		t.Fatal("factory must not be called at construction")
+	}
+	for i := 0; i < 2; i++ {
+		if err := hook.Fire(&logrus.Entry{Message: "hello"}); err != factoryErr {
+			t.Fatalf("expected factory error, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("factory should be retried on every Fire until it succeeds, got %d calls", calls)
+	}
+}
+
+func TestLazyBootstrapBackoff(t *testing.T) {
+	calls := 0
+	hook := NewLazyElasticHook(func() (*elastic.Client, error) {
+		calls++
+		return &elastic.Client{}, nil
+	}, "localhost", logrus.InfoLevel, "lazy", WithLazyBootstrap())
+
+	_, err := hook.getClient()
+	if err == nil {
+		t.Fatal("expected the bootstrap to fail")
+	}
+	if _, again := hook.getClient(); again != err || calls != 1 {
+		t.Errorf("expected the error until the backoff passed, got %v after %d calls", again, calls)
+	}
+
+	hook.bootRetry = time.Time{}
+	hook.booting = true
+	if _, err := hook.getClient(); err != ErrBootstrapPending || calls != 1 {
+		t.Errorf("expected to fail fast during an attempt, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// LeaseConfig configures the lease
+// document guarding cluster maintenance
+type LeaseConfig struct {
+	// Index holds the lease
+	// document, default ".elogrus"
+	Index string
+	// ID of the lease document,
+	// default "maintenance"
+	ID string
+	// TTL is how long a lease is held,
+	// default 10 minutes
+	TTL time.Duration
+	// Owner identifies this instance,
+	// default host and process ID
+	Owner string
+}
+
+// WithMaintenanceLease makes instances running the
+// same maintenance (index warm-up and retention)
+// take turns: before each run an instance takes a
+// lease document in ElasticSearch, using versioned
+// writes, and skips the run while another instance
+// holds an unexpired lease.
+func WithMaintenanceLease(config LeaseConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Index == "" {
+			config.Index = ".elogrus"
+		}
+		if config.ID == "" {
+			config.ID = "maintenance"
+		}
+		if config.TTL <= 0 {
+			config.TTL = 10 * time.Minute
+		}
+		if config.Owner == "" {
+			config.Owner = fmt.Sprintf("%s/%d", hook.host, os.Getpid())
+		}
+		hook.lease = &config
+	}
+}
+
+type leaseDocument struct {
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+// maintain runs fn if this
+// instance holds the lease
+func (hook *ElasticHook) maintain(fn func(*elastic.Client) error) error {
+	return hook.do(func(client *elastic.Client) error {
+		if hook.lease != nil {
+			held, err := hook.takeLease(client, time.Now())
+			if err != nil || !held {
+				return err
+			}
+		}
+		return fn(client)
+	})
+}
+
+// takeLease takes or renews the lease, it reports
+// false when another instance holds it
+func (hook *ElasticHook) takeLease(client *elastic.Client, now time.Time) (bool, error) {
+	l := hook.lease
+	ctx, cancel := hook.controlContext()
+	defer cancel()
+	index := client.Index().
+		Index(l.Index).
+		Type(hook.docType()).
+		Id(l.ID).
+		BodyJson(leaseDocument{Owner: l.Owner, Expires: now.Add(l.TTL)})
+
+	current, err := client.Get().Index(l.Index).Type(hook.docType()).Id(l.ID).DoC(ctx)
+	switch {
+	case isStatus(err, 404) || (err == nil && !current.Found):
+		index.OpType("create")
+	case err != nil:
+		return false, err
+	default:
+		var doc leaseDocument
+		if current.Source != nil {
+			if err := json.Unmarshal(*current.Source, &doc); err != nil {
+				return false, err
+			}
+		}
+		if doc.Owner != l.Owner && now.Before(doc.Expires) {
+			return false, nil
+		}
+		if current.Version != nil {
+			index.Version(*current.Version)
+		}
+	}
+
+	_, err = index.DoC(ctx)
+	if isStatus(err, 409) {
+		// another instance
+		// was faster
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func isStatus(err error, status int) bool {
+	e, ok := err.(*elastic.Error)
+	return ok && e.Status == status
+}
@@ -0,0 +1,33 @@
+package elogrus
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestMaintenanceLeaseDefaults(t *testing.T) {
+	hook := newHook(nil, "web-1", logrus.DebugLevel, "test", WithMaintenanceLease(LeaseConfig{}))
+	l := hook.lease
+	if l.Index != ".elogrus" || l.ID != "maintenance" || l.TTL != 10*time.Minute {
+		t.Errorf("unexpected defaults %+v", l)
+	}
+	if l.Owner != "web-1/"+strconv.Itoa(os.Getpid()) {
+		t.Errorf("unexpected owner %q", l.Owner)
+	}
+}
+
+func TestIsStatus(t *testing.T) {
+	if !isStatus(&elastic.Error{Status: 409}, 409) {
+		t.Error("expected a conflict")
+	}
+	if isStatus(errors.New("409"), 409) || isStatus(nil, 404) {
+		t.Error("only ElasticSearch errors carry a status")
+	}
+}
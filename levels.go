@@ -0,0 +1,74 @@
+package elogrus
+
+import (
+	"sync/atomic"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// allLevels are the levels the hook
+// is registered for with logrus
+var allLevels = []logrus.Level{
+	logrus.PanicLevel,
+	logrus.FatalLevel,
+	logrus.ErrorLevel,
+	logrus.WarnLevel,
+	logrus.InfoLevel,
+	logrus.DebugLevel,
+}
+
+// levelsUpTo returns level
+// and the more severe ones
+func levelsUpTo(level logrus.Level) []logrus.Level {
+	var levels []logrus.Level
+	for _, l := range allLevels {
+		if l <= level {
+			levels = append(levels, l)
+		}
+	}
+	return levels
+}
+
+func levelMask(levels []logrus.Level) uint32 {
+	var mask uint32
+	for _, l := range levels {
+		if l < 32 {
+			mask |= 1 << l
+		}
+	}
+	return mask
+}
+
+// SetLevel enables level and the more severe ones,
+// e.g. to flip to debug from an admin endpoint
+// without re-creating the hook. The logger must
+// log the level too, see logrus.SetLevel.
+// It is safe for concurrent use.
+func (hook *ElasticHook) SetLevel(level logrus.Level) {
+	hook.SetLevels(levelsUpTo(level))
+}
+
+// SetLevels enables exactly levels,
+// it is safe for concurrent use
+func (hook *ElasticHook) SetLevels(levels []logrus.Level) {
+	atomic.StoreUint32(&hook.levels, levelMask(levels))
+}
+
+// enabledLevels returns the
+// levels the hook ships
+func (hook *ElasticHook) enabledLevels() []logrus.Level {
+	mask := atomic.LoadUint32(&hook.levels)
+	var levels []logrus.Level
+	for _, l := range allLevels {
+		if mask&(1<<l) != 0 {
+			levels = append(levels, l)
+		}
+	}
+	return levels
+}
+
+// levelEnabled reports
+// whether level is shipped
+func (hook *ElasticHook) levelEnabled(level logrus.Level) bool {
+	return level < 32 && atomic.LoadUint32(&hook.levels)&(1<<level) != 0
+}
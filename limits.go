@@ -0,0 +1,155 @@
+package elogrus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// FieldLimits bounds the length in bytes
+// of string fields, see WithFieldLimits
+type FieldLimits struct {
+	// Fields maps field names, matched
+	// case-insensitively, to their limit;
+	// "message" limits the entry message
+	Fields map[string]int
+	// Default limits the other fields of the
+	// entry data and the error details of
+	// WithErrorDetails, 0 leaves them
+	Default int
+	// Marker ends truncated values,
+	// default "...[truncated]"
+	Marker string
+}
+
+// WithFieldLimits truncates long string and error
+// values per field, e.g. a stack trace to 32 KB and
+// everything else to 1 KB, ending them with the
+// marker within the limit. Characters are never
+// split. See FieldsTruncated.
+func WithFieldLimits(limits FieldLimits) Option {
+	return func(hook *ElasticHook) {
+		if limits.Marker == "" {
+			limits.Marker = "...[truncated]"
+		}
+		fields := make(map[string]int, len(limits.Fields))
+		for name, max := range limits.Fields {
+			fields[strings.ToLower(name)] = max
+		}
+		limits.Fields = fields
+		hook.fieldLimits = &fieldLimiter{FieldLimits: limits}
+	}
+}
+
+// FieldsTruncated returns the number
+// of values truncated by the limits
+func (hook *ElasticHook) FieldsTruncated() int64 {
+	if hook.fieldLimits == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&hook.fieldLimits.truncated)
+}
+
+type fieldLimiter struct {
+	// truncated is first to be 64-bit
+	// aligned for atomic access
+	truncated int64
+	FieldLimits
+}
+
+// limit returns s cut to
+// the limit of the field
+func (l *fieldLimiter) limit(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	atomic.AddInt64(&l.truncated, 1)
+	if max <= len(l.Marker) {
+		return truncate(s, max)
+	}
+	return truncate(s, max-len(l.Marker)) + l.Marker
+}
+
+// limitEntry returns a copy of entry
+// with long values truncated
+func (hook *ElasticHook) limitEntry(entry *logrus.Entry) *logrus.Entry {
+	l := hook.fieldLimits
+	if l == nil {
+		return entry
+	}
+	limited := *entry
+	if max, ok := l.Fields["message"]; ok {
+		limited.Message = l.limit(entry.Message, max)
+	}
+	limited.Data = make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		max, ok := l.Fields[strings.ToLower(k)]
+		if !ok {
+			max = l.Default
+		}
+		switch s := v.(type) {
+		case string:
+			v = l.limit(s, max)
+		case error:
+			if max > 0 && len(s.Error()) > max {
+				v = limitedError{s, l.limit(s.Error(), max)}
+			}
+		}
+		limited.Data[k] = v
+	}
+	return &limited
+}
+
+// limitErrorDetails truncates the error
+// details of doc, once they are built
+func (hook *ElasticHook) limitErrorDetails(doc map[string]interface{}) {
+	l := hook.fieldLimits
+	if l == nil || !hook.errorDetails {
+		return
+	}
+	max := func(field string) int {
+		if max, ok := l.Fields[field]; ok {
+			return max
+		}
+		return l.Default
+	}
+	for _, field := range []string{ErrorMessageField, hook.errorStackField()} {
+		if s, ok := doc[field].(string); ok {
+			doc[field] = l.limit(s, max(field))
+		}
+	}
+	if chain, ok := doc[ErrorChainField].([]string); ok {
+		m := max(ErrorChainField)
+		for i, s := range chain {
+			chain[i] = l.limit(s, m)
+		}
+	}
+}
+
+// limitedError is an error with its message
+// truncated, which still unwraps and formats
+// its stack trace like the original
+type limitedError struct {
+	err     error
+	message string
+}
+
+func (e limitedError) Error() string {
+	return e.message
+}
+
+func (e limitedError) Unwrap() error {
+	return errors.Unwrap(e.err)
+}
+
+func (e limitedError) Format(s fmt.State, verb rune) {
+	if f, ok := e.err.(fmt.Formatter); ok && verb == 'v' && s.Flag('+') {
+		f.Format(s, verb)
+		return
+	}
+	io.WriteString(s, e.message)
+}
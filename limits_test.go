@@ -0,0 +1,82 @@
+package elogrus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestFieldLimits(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithFieldLimits(FieldLimits{Fields: map[string]int{"StackTrace": 20, "message": 16}, Default: 8, Marker: "..."}))
+	entry := &logrus.Entry{
+		Message: "a message too long to keep",
+		Data: logrus.Fields{
+			"stacktrace": strings.Repeat("x", 30),
+			"user":       "ööööö",
+			"short":      "ok",
+			"count":      12345678901,
+			"error":      errors.New("connection refused"),
+		},
+	}
+	doc := hook.documents(entry)[0]
+	data := doc["Data"].(logrus.Fields)
+
+	if doc["Message"] != "a message too..." {
+		t.Errorf("unexpected message %q", doc["Message"])
+	}
+	if data["stacktrace"] != strings.Repeat("x", 17)+"..." {
+		t.Errorf("unexpected stacktrace %q", data["stacktrace"])
+	}
+	// ö takes two bytes and is not split
+	if data["user"] != "öö..." || data["error"] != "conne..." {
+		t.Errorf("unexpected defaults %q, %q", data["user"], data["error"])
+	}
+	if data["short"] != "ok" || data["count"] != 12345678901 {
+		t.Errorf("short and non-string values must be kept, got %v", data)
+	}
+	if hook.FieldsTruncated() != 4 {
+		t.Errorf("expected 4 truncated values, got %d", hook.FieldsTruncated())
+	}
+}
+
+func TestFieldLimitsErrorDetails(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithErrorDetails(true),
+		WithFieldLimits(FieldLimits{Fields: map[string]int{"error": 8, "error.stack": 20}, Marker: "..."}))
+	doc := hook.documents(&logrus.Entry{Data: logrus.Fields{logrus.ErrorKey: &stackError{"disk full forever"}}})[0]
+
+	if doc[ErrorMessageField] != "disk ..." || doc["Data"].(logrus.Fields)[logrus.ErrorKey] != "disk ..." {
+		t.Errorf("expected the error message limited, got %v", doc)
+	}
+	if doc[ErrorStackField] != "disk full forever..." {
+		t.Errorf("expected the stack limited, got %q", doc[ErrorStackField])
+	}
+}
+
+func TestMaxDocumentSize(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithMaxDocumentSize(1<<10))
+	body := strings.Repeat("<b>", 2<<10)
+	data := logrus.Fields{"body": body, "user": "someone"}
+	doc := hook.documents(&logrus.Entry{Message: "request", Data: data})[0]
+
+	if size := encodedSize(doc); size > 1<<10 {
+		t.Errorf("expected at most 1024 bytes, got %d", size)
+	}
+	if doc[TruncatedField] != true {
+		t.Errorf("expected the truncated marker, got %v", doc)
+	}
+	limited := doc["Data"].(logrus.Fields)
+	if !strings.HasSuffix(limited["body"].(string), "...[truncated]") || limited["user"] != "someone" {
+		t.Errorf("expected only the body to be cut, got %v", limited)
+	}
+	if data["body"] != body {
+		t.Error("the entry data must not be changed")
+	}
+
+	small := hook.documents(&logrus.Entry{Message: "small", Data: logrus.Fields{}})[0]
+	if _, ok := small[TruncatedField]; ok || hook.DocumentsTruncated() != 1 {
+		t.Errorf("expected 1 truncated document, got %d", hook.DocumentsTruncated())
+	}
+}
@@ -0,0 +1,160 @@
+package elogrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LineFormat is the format of the
+// lines written by a writer fallback
+type LineFormat int
+
+// Line formats
+const (
+	// JSONLines writes the documents
+	// as is, one per line
+	JSONLines LineFormat = iota
+	// LogfmtLines writes key=value pairs
+	LogfmtLines
+	// W3CLines writes the W3C extended log
+	// format, one column per field
+	W3CLines
+)
+
+// defaultW3CFields are the columns of W3C
+// lines when no fields are given
+var defaultW3CFields = []string{"Timestamp", "Host", "Level", "Message"}
+
+// FormattedWriterFallback writes documents to w in format,
+// so file based tooling keeps working while ElasticSearch
+// is unreachable. Nested values are named with dots, e.g.
+// Data.user. fields are the columns of W3C lines, default
+// Timestamp, Host, Level and Message, and limit logfmt
+// lines to those keys in that order.
+func FormattedWriterFallback(w io.Writer, format LineFormat, fields ...string) FallbackSink {
+	if format == JSONLines {
+		return WriterFallback(w)
+	}
+	if format == W3CLines && len(fields) == 0 {
+		fields = defaultW3CFields
+	}
+	fields = append([]string(nil), fields...)
+	var mu sync.Mutex
+	header := format == W3CLines
+	return FallbackFunc(func(doc json.RawMessage) error {
+		values, err := flattenDocument(doc)
+		if err != nil {
+			return err
+		}
+		var line []byte
+		if format == W3CLines {
+			line = w3cLine(values, fields)
+		} else {
+			line = logfmtLine(values, fields)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if header {
+			if _, err := fmt.Fprintf(w, "#Version: 1.0\n#Fields: %s\n", strings.Join(fields, " ")); err != nil {
+				return err
+			}
+			header = false
+		}
+		_, err = w.Write(line)
+		return err
+	})
+}
+
+// flattenDocument decodes doc, naming
+// nested values with dotted keys
+func flattenDocument(doc json.RawMessage) (map[string]string, error) {
+	d := json.NewDecoder(bytes.NewReader(doc))
+	d.UseNumber()
+	var m map[string]interface{}
+	if err := d.Decode(&m); err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	flattenInto(values, "", m)
+	return values, nil
+}
+
+func flattenInto(values map[string]string, prefix string, m map[string]interface{}) {
+	for k, v := range m {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			flattenInto(values, prefix+k+".", v)
+		case string:
+			values[prefix+k] = v
+		case nil:
+		case json.Number, bool:
+			values[prefix+k] = fmt.Sprint(v)
+		default:
+			b, _ := json.Marshal(v)
+			values[prefix+k] = string(b)
+		}
+	}
+}
+
+// logfmtLine formats values, all of
+// them sorted when fields is empty
+func logfmtLine(values map[string]string, fields []string) []byte {
+	if len(fields) == 0 {
+		for k := range values {
+			fields = append(fields, k)
+		}
+		sort.Strings(fields)
+	}
+	var b bytes.Buffer
+	for _, k := range fields {
+		v, ok := values[k]
+		if !ok {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		if v == "" || strings.ContainsAny(v, " =\"\\") || strings.IndexFunc(v, isControl) >= 0 {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(v)
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+// w3cLine formats the fields of values,
+// a dash standing for missing ones
+func w3cLine(values map[string]string, fields []string) []byte {
+	var b bytes.Buffer
+	for i, k := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		v, ok := values[k]
+		switch {
+		case !ok || v == "":
+			b.WriteByte('-')
+		case strings.ContainsAny(v, " \"") || strings.IndexFunc(v, isControl) >= 0:
+			v = strings.NewReplacer(`"`, `""`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(v)
+			b.WriteString(`"` + v + `"`)
+		default:
+			b.WriteString(v)
+		}
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+func isControl(r rune) bool {
+	return r < ' ' || r == 0x7f
+}
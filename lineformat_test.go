@@ -0,0 +1,42 @@
+package elogrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestFormattedWriterFallback(t *testing.T) {
+	doc := json.RawMessage(`{"Timestamp":"2024-05-17T10:00:00Z","Host":"web 1","Level":"ERROR","Message":"say \"hi\"","Data":{"user":"joe","n":3}}`)
+	for _, c := range []struct {
+		format LineFormat
+		fields []string
+		expect string
+	}{
+		{JSONLines, nil, string(doc) + "\n"},
+		{LogfmtLines, nil, `Data.n=3 Data.user=joe Host="web 1" Level=ERROR Message="say \"hi\"" Timestamp=2024-05-17T10:00:00Z` + "\n"},
+		{LogfmtLines, []string{"Level", "Data.user"}, "Level=ERROR Data.user=joe\n"},
+		{W3CLines, []string{"Timestamp", "Host", "Message", "Data.ip"}, "#Version: 1.0\n#Fields: Timestamp Host Message Data.ip\n" +
+			`2024-05-17T10:00:00Z "web 1" "say ""hi""" -` + "\n"},
+	} {
+		var buf bytes.Buffer
+		sink := FormattedWriterFallback(&buf, c.format, c.fields...)
+		if err := sink.WriteDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != c.expect {
+			t.Errorf("format %d: expected\n%s\ngot\n%s", c.format, c.expect, buf.String())
+		}
+	}
+}
+
+func TestW3CHeaderOnce(t *testing.T) {
+	var buf bytes.Buffer
+	sink := FormattedWriterFallback(&buf, W3CLines)
+	sink.WriteDocument(json.RawMessage(`{"Level":"INFO"}`))
+	sink.WriteDocument(json.RawMessage(`{"Level":"WARNING"}`))
+	expect := "#Version: 1.0\n#Fields: Timestamp Host Level Message\n- - INFO -\n- - WARNING -\n"
+	if buf.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, buf.String())
+	}
+}
@@ -0,0 +1,263 @@
+package elogrus
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+var (
+	// Fired if a Lumberjack sink
+	// is created without address
+	ErrNoLumberjackAddress = fmt.Errorf("Lumberjack address is required")
+	// Fired if Logstash acknowledged
+	// a window with an unexpected frame
+	ErrLumberjackProtocol = fmt.Errorf("Unexpected Lumberjack response")
+)
+
+// LumberjackIndexField holds the target index
+// in the @metadata of documents forwarded to
+// Logstash, for its elasticsearch output
+const LumberjackIndexField = "index"
+
+// LumberjackConfig configures a
+// Lumberjack (Beats protocol) sink
+type LumberjackConfig struct {
+	// Address is the host:port of the
+	// Logstash beats input
+	Address string
+	// TLS enables TLS when set
+	TLS *tls.Config
+	// Timeout bounds connecting and the
+	// acknowledgement of a window,
+	// default 30 seconds
+	Timeout time.Duration
+	// Compression is the zlib level of
+	// the windows, 0 sends them as is
+	Compression int
+}
+
+// LumberjackSink sends documents to Logstash with
+// the Lumberjack v2 protocol spoken by the Beats,
+// waiting for every window to be acknowledged. It
+// reconnects after errors and is safe for
+// concurrent use.
+type LumberjackSink struct {
+	config LumberjackConfig
+	mu     sync.Mutex
+	conn   net.Conn
+	r      *bufio.Reader
+}
+
+// NewLumberjackSink creates a sink for config,
+// the connection is made on the first write
+func NewLumberjackSink(config LumberjackConfig) (*LumberjackSink, error) {
+	if config.Address == "" {
+		return nil, ErrNoLumberjackAddress
+	}
+	if config.Compression < zlib.HuffmanOnly || config.Compression > zlib.BestCompression {
+		return nil, fmt.Errorf("Invalid Lumberjack compression level %d", config.Compression)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &LumberjackSink{config: config}, nil
+}
+
+// WithLumberjack forwards the documents to Logstash
+// through sink instead of indexing them, for setups
+// where logs must pass through Logstash pipelines.
+// The target index is set in @metadata. See
+// NewLumberjackHook for a hook without client.
+func WithLumberjack(sink *LumberjackSink) Option {
+	return WithForwarder(sink)
+}
+
+// NewLumberjackHook creates a hook forwarding entries to
+// Logstash through sink, without ElasticSearch client;
+// index management is left to Logstash. Close the sink
+// after the hook. The other parameters are the same
+// as for NewElasticHook.
+func NewLumberjackHook(sink *LumberjackSink, host string, level logrus.Level, index string, opts ...Option) (*ElasticHook, error) {
+	return NewForwardingHook(sink, host, level, index, opts...)
+}
+
+// WriteDocument is required to implement
+// FallbackSink, Logstash can be the
+// fallback of a hook as well
+func (s *LumberjackSink) WriteDocument(doc json.RawMessage) error {
+	return s.Send(context.Background(), []json.RawMessage{doc})
+}
+
+// Send sends docs in a single window and waits
+// until Logstash acknowledged all of them
+func (s *LumberjackSink) Send(ctx context.Context, docs []json.RawMessage) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.connect(ctx); err != nil {
+		return err
+	}
+	err := s.window(ctx, docs)
+	if err != nil {
+		s.reset()
+	}
+	return err
+}
+
+// Close closes the connection
+func (s *LumberjackSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn, s.r = nil, nil
+	return err
+}
+
+// connect dials Logstash
+// unless connected, under mu
+func (s *LumberjackSink) connect(ctx context.Context) error {
+	if s.conn != nil {
+		return nil
+	}
+	d := &net.Dialer{Timeout: s.config.Timeout}
+	conn, err := d.DialContext(ctx, "tcp", s.config.Address)
+	if err != nil {
+		return err
+	}
+	if s.config.TLS != nil {
+		tconn := tls.Client(conn, s.config.TLS)
+		tconn.SetDeadline(time.Now().Add(s.config.Timeout))
+		if err := tconn.Handshake(); err != nil {
+			conn.Close()
+			return err
+		}
+		conn = tconn
+	}
+	s.conn, s.r = conn, bufio.NewReader(conn)
+	return nil
+}
+
+// reset drops the connection
+// after an error, under mu
+func (s *LumberjackSink) reset() {
+	s.conn.Close()
+	s.conn, s.r = nil, nil
+}
+
+// window writes docs as a window and
+// reads the acknowledgements, under mu
+func (s *LumberjackSink) window(ctx context.Context, docs []json.RawMessage) error {
+	deadline := time.Now().Add(s.config.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	s.conn.SetDeadline(deadline)
+	done := make(chan struct{})
+	defer close(done)
+	go func(conn net.Conn) {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}(s.conn)
+
+	var buf bytes.Buffer
+	buf.Write([]byte{'2', 'W'})
+	binary.Write(&buf, binary.BigEndian, uint32(len(docs)))
+	frames := lumberjackFrames(docs)
+	if s.config.Compression == 0 {
+		buf.Write(frames)
+	} else {
+		var z bytes.Buffer
+		w, _ := zlib.NewWriterLevel(&z, s.config.Compression)
+		w.Write(frames)
+		w.Close()
+		buf.Write([]byte{'2', 'C'})
+		binary.Write(&buf, binary.BigEndian, uint32(z.Len()))
+		buf.Write(z.Bytes())
+	}
+	if _, err := s.conn.Write(buf.Bytes()); err != nil {
+		return s.failed(ctx, err)
+	}
+
+	// Logstash may acknowledge part of the
+	// window, e.g. as keepalive, before the
+	// sequence of the last document
+	for {
+		var ack [6]byte
+		if _, err := io.ReadFull(s.r, ack[:]); err != nil {
+			return s.failed(ctx, err)
+		}
+		if ack[0] != '2' || ack[1] != 'A' {
+			return ErrLumberjackProtocol
+		}
+		if binary.BigEndian.Uint32(ack[2:]) >= uint32(len(docs)) {
+			return nil
+		}
+	}
+}
+
+// failed returns the context error
+// when ctx interrupted the window
+func (s *LumberjackSink) failed(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// lumberjackFrames encodes docs as JSON
+// frames with sequences counted from 1
+func lumberjackFrames(docs []json.RawMessage) []byte {
+	var buf bytes.Buffer
+	for i, doc := range docs {
+		buf.Write([]byte{'2', 'J'})
+		binary.Write(&buf, binary.BigEndian, uint32(i+1))
+		binary.Write(&buf, binary.BigEndian, uint32(len(doc)))
+		buf.Write(doc)
+	}
+	return buf.Bytes()
+}
+
+// Forward is required to implement Forwarder,
+// the index is set in the @metadata of the
+// documents
+func (s *LumberjackSink) Forward(ctx context.Context, docs []ForwardedDocument) ([]error, error) {
+	raw := make([]json.RawMessage, len(docs))
+	for i, doc := range docs {
+		raw[i] = lumberjackDoc(doc.Index, doc.Body)
+	}
+	return nil, s.Send(ctx, raw)
+}
+
+// lumberjackDoc adds the
+// index to the @metadata
+func lumberjackDoc(index string, body json.RawMessage) json.RawMessage {
+	if len(body) < 2 || body[0] != '{' {
+		return body
+	}
+	meta := []byte(`{"@metadata":{"` + LumberjackIndexField + `":` + strconv.Quote(index) + `}`)
+	if len(body) > 2 {
+		meta = append(meta, ',')
+	}
+	return append(meta, body[1:]...)
+}
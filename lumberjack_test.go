@@ -0,0 +1,108 @@
+package elogrus
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// logstash accepts one connection, acknowledges
+// every window and sends the JSON frames to docs
+func logstash(t *testing.T, docs chan<- string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			var header [6]byte
+			if _, err := io.ReadFull(r, header[:]); err != nil {
+				return
+			}
+			count := binary.BigEndian.Uint32(header[2:])
+			frames := io.Reader(r)
+			if b, _ := r.Peek(2); string(b) == "2C" {
+				var size [6]byte
+				io.ReadFull(r, size[:])
+				z, _ := zlib.NewReader(io.LimitReader(r, int64(binary.BigEndian.Uint32(size[2:]))))
+				frames = z
+			}
+			for i := uint32(0); i < count; i++ {
+				var frame [10]byte
+				io.ReadFull(frames, frame[:])
+				doc := make([]byte, binary.BigEndian.Uint32(frame[6:]))
+				io.ReadFull(frames, doc)
+				docs <- string(doc)
+			}
+			// a keepalive first, then the window
+			var ack bytes.Buffer
+			ack.WriteString("2A")
+			binary.Write(&ack, binary.BigEndian, uint32(0))
+			ack.WriteString("2A")
+			binary.Write(&ack, binary.BigEndian, count)
+			conn.Write(ack.Bytes())
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestLumberjackSink(t *testing.T) {
+	for _, level := range []int{0, zlib.BestSpeed} {
+		docs := make(chan string, 2)
+		sink, err := NewLumberjackSink(LumberjackConfig{Address: logstash(t, docs), Compression: level})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = sink.Send(context.Background(), []json.RawMessage{json.RawMessage(`{"a":1}`), json.RawMessage(`{"b":2}`)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a, b := <-docs, <-docs; a != `{"a":1}` || b != `{"b":2}` {
+			t.Errorf("unexpected documents %s and %s with compression %d", a, b, level)
+		}
+		sink.Close()
+	}
+}
+
+func TestLumberjackHook(t *testing.T) {
+	docs := make(chan string, 1)
+	sink, _ := NewLumberjackSink(LumberjackConfig{Address: logstash(t, docs)})
+	defer sink.Close()
+	hook, err := NewLumberjackHook(sink, "localhost", logrus.DebugLevel, "mylog")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := hook.Fire(&logrus.Entry{Message: "forwarded", Level: logrus.InfoLevel, Data: logrus.Fields{}}); err != nil {
+		t.Fatal(err)
+	}
+	doc := <-docs
+	if !strings.HasPrefix(doc, `{"@metadata":{"index":"mylog"},`) || !strings.Contains(doc, "forwarded") {
+		t.Errorf("unexpected document %s", doc)
+	}
+	if s := hook.Stats().Indices["mylog"]; s.Documents != 1 {
+		t.Errorf("expected 1 document shipped, got %d", s.Documents)
+	}
+}
+
+func TestLumberjackAddress(t *testing.T) {
+	if _, err := NewLumberjackSink(LumberjackConfig{}); err != ErrNoLumberjackAddress {
+		t.Errorf("expected ErrNoLumberjackAddress, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package elogrus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// WithMappingCheck fetches the mapping of the hook
+// index when the client is set up and warns, via
+// the internal logger, about fields the hook sends
+// with a type the mapping does not fit, e.g. a
+// numeric severity mapped as text. The check never
+// fails the constructor.
+func WithMappingCheck() Option {
+	return func(hook *ElasticHook) {
+		hook.mappingCheck = true
+	}
+}
+
+// compatibleTypes lists the mapping types
+// accepting each kind of emitted value
+var compatibleTypes = map[string][]string{
+	"string":  {"string", "text", "keyword"},
+	"date":    {"date", "date_nanos"},
+	"long":    {"long", "integer", "short", "byte", "double", "float", "half_float", "scaled_float"},
+	"double":  {"double", "float", "half_float", "scaled_float"},
+	"boolean": {"boolean"},
+	"object":  {"object", "nested"},
+	"geo":     {"geo_point"},
+}
+
+// kinds maps field types
+// to the emitted kind
+var kinds = map[FieldType]string{
+	StringField: "string",
+	IntField:    "long",
+	FloatField:  "double",
+	BoolField:   "boolean",
+}
+
+// checkMapping warns about
+// mapping conflicts
+func (hook *ElasticHook) checkMapping(client *elastic.Client) {
+	if !hook.mappingCheck || hook.writeOnly {
+		return
+	}
+	ctx, cancel := hook.controlContext()
+	defer cancel()
+	get := client.GetMapping().Index(hook.index)
+	if !hook.typeless {
+		get.Type(hook.docType())
+	}
+	mapping, err := get.DoC(ctx)
+	if err != nil {
+		hook.reportError(fmt.Errorf("Mapping check failed: %w", err))
+		return
+	}
+	for _, conflict := range hook.mappingConflicts(mapping) {
+		hook.warn(conflict)
+	}
+}
+
+// emittedFields returns the kind of
+// every field the hook may send
+func (hook *ElasticHook) emittedFields() map[string]string {
+	fields := map[string]string{
+		"Host":              "string",
+		hook.timestampField: "date",
+		"Message":           "string",
+		"Data":              "object",
+		hook.levelField:     "string",
+		"trace.id":          "string",
+		"span.id":           "string",
+	}
+	dataPrefix := "Data."
+	if hook.ecs {
+		fields = map[string]string{
+			"@timestamp":    "date",
+			"message":       "string",
+			"log.level":     "string",
+			"host.name":     "string",
+			"ecs.version":   "string",
+			"error.message": "string",
+			"trace.id":      "string",
+			"span.id":       "string",
+		}
+		dataPrefix = ""
+	}
+	if hook.filebeat != nil {
+		fields = map[string]string{
+			"@timestamp":     "date",
+			"message":        "string",
+			"fields":         "object",
+			"fields.level":   "string",
+			"host.name":      "string",
+			"input.type":     "string",
+			"agent.name":     "string",
+			"agent.hostname": "string",
+			"agent.version":  "string",
+			"agent.type":     "string",
+			"ecs.version":    "string",
+			"trace.id":       "string",
+			"span.id":        "string",
+		}
+		if strings.HasPrefix(hook.filebeat.Version, "6.") {
+			for _, name := range []string{"name", "hostname", "version", "type"} {
+				delete(fields, "agent."+name)
+			}
+			delete(fields, "ecs.version")
+			fields["beat.name"] = "string"
+			fields["beat.hostname"] = "string"
+			fields["beat.version"] = "string"
+		}
+		dataPrefix = "fields."
+	}
+	if hook.namespace() != "" {
+		delete(fields, "Host")
+		delete(fields, "host.name")
+		fields[hook.originField("", "host")] = "string"
+	}
+	if hook.severityField != "" {
+		fields[hook.severityField] = "long"
+	}
+	if hook.levelValueField != "" {
+		fields[hook.levelValueField] = "long"
+	}
+	if hook.messageTemplate {
+		fields["MessageTemplate"] = "string"
+	}
+	if hook.correlationField != "" {
+		fields[hook.correlationField] = "string"
+	}
+	if hook.errorDetails {
+		fields[ErrorMessageField] = "string"
+		fields[ErrorChainField] = "string"
+		if hook.errorStack {
+			fields[hook.errorStackField()] = "string"
+		}
+	}
+	if hook.eventCategory != nil {
+		fields[EventKindField] = "string"
+		fields[EventCategoryField] = "string"
+		fields[EventTypeField] = "string"
+	}
+	if hook.sampler != nil {
+		fields[SamplingRateField] = "double"
+		fields[SamplingDroppedField] = "long"
+	}
+	if hook.batchIDs {
+		fields[BatchIDField] = "string"
+	}
+	if hook.caller != nil {
+		fields[hook.originField(CallerFileField, "file")] = "string"
+		fields[hook.originField(CallerLineField, "line")] = "long"
+		fields[hook.originField(CallerFunctionField, "function")] = "string"
+	}
+	if hook.goroutineInfo {
+		fields["Goroutine"] = "long"
+		fields["Labels"] = "object"
+	}
+	if hook.eventSequence {
+		fields[SequenceField] = "long"
+	}
+	switch {
+	case hook.maxMessage > 0 && hook.overflow == OverflowField:
+		fields["message_overflow"] = "string"
+	case hook.maxMessage > 0 && hook.overflow == OverflowDocuments:
+		fields["message_chunk.id"] = "string"
+		fields["message_chunk.index"] = "long"
+		fields["message_chunk.count"] = "long"
+	}
+	if hook.audit != nil {
+		fields[AuditInstanceField] = "string"
+		fields[AuditSeqField] = "long"
+		fields[AuditPrevField] = "string"
+		fields[AuditHashField] = "string"
+	}
+	if hook.userAgentField != "" {
+		for _, name := range []string{"original", "name", "version", "os.name", "os.version", "device.name"} {
+			fields["user_agent."+name] = "string"
+		}
+	}
+	for _, g := range hook.geoPoints {
+		fields[g.Field] = "geo"
+	}
+	for name, typ := range hook.fieldTypes {
+		fields[dataPrefix+name] = kinds[typ]
+	}
+	for _, f := range hook.extraFields {
+		fields[f.name] = kinds[f.typ]
+	}
+	return fields
+}
+
+// mappingConflicts compares a get mapping
+// response to the emitted fields
+func (hook *ElasticHook) mappingConflicts(mapping map[string]interface{}) []string {
+	emitted := hook.emittedFields()
+	names := make([]string, 0, len(emitted))
+	for name := range emitted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var conflicts []string
+	for index, m := range mapping {
+		path := []string{"mappings", hook.docType(), "properties"}
+		if hook.typeless {
+			path = []string{"mappings", "properties"}
+		}
+		props, ok := lookupMap(m, path...)
+		if !ok {
+			continue
+		}
+		for _, name := range names {
+			mapped, ok := mappedType(props, name)
+			if !ok || contains(compatibleTypes[emitted[name]], mapped) {
+				continue
+			}
+			conflicts = append(conflicts, fmt.Sprintf(
+				"Field %s of index %s is mapped as %s, but the hook sends %s", name, index, mapped, emitted[name]))
+		}
+	}
+	return conflicts
+}
+
+// mappedType returns the mapping type of
+// the field at path, a dotted path is also
+// looked up through object properties
+func mappedType(props map[string]interface{}, path string) (string, bool) {
+	if f, ok := props[path].(map[string]interface{}); ok {
+		return fieldMappingType(f), true
+	}
+	i := strings.IndexByte(path, '.')
+	if i < 0 {
+		return "", false
+	}
+	f, ok := props[path[:i]].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	if sub, ok := f["properties"].(map[string]interface{}); ok {
+		return mappedType(sub, path[i+1:])
+	}
+	return fieldMappingType(f), true
+}
+
+func fieldMappingType(f map[string]interface{}) string {
+	if typ, ok := f["type"].(string); ok {
+		return typ
+	}
+	return "object"
+}
+
+func lookupMap(v interface{}, keys ...string) (map[string]interface{}, bool) {
+	m, ok := v.(map[string]interface{})
+	for _, key := range keys {
+		if !ok {
+			return nil, false
+		}
+		m, ok = m[key].(map[string]interface{})
+	}
+	return m, ok
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
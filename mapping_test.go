@@ -0,0 +1,60 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestMappingConflicts(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithDocumentType("log"),
+		WithSeverityField("severity"),
+		WithFieldTypes(map[string]FieldType{"user_id": StringField, "duration": FloatField}),
+	)
+	var mapping map[string]interface{}
+	err := json.Unmarshal([]byte(`{"test-v1": {"mappings": {"log": {"properties": {
+		"Timestamp": {"type": "date"},
+		"Message": {"type": "text"},
+		"severity": {"type": "text"},
+		"trace": {"properties": {"id": {"type": "long"}}},
+		"Data": {"properties": {
+			"user_id": {"type": "keyword"},
+			"duration": {"type": "long"}
+		}}
+	}}}}}`), &mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts := hook.mappingConflicts(mapping)
+	expected := []string{
+		"Field Data.duration of index test-v1 is mapped as long, but the hook sends double",
+		"Field severity of index test-v1 is mapped as text, but the hook sends long",
+		"Field trace.id of index test-v1 is mapped as long, but the hook sends string",
+	}
+	if strings.Join(conflicts, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected conflicts:\n%s", strings.Join(conflicts, "\n"))
+	}
+}
+
+func TestTypelessMappingConflicts(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithTypeless())
+	var mapping map[string]interface{}
+	err := json.Unmarshal([]byte(`{"test": {"mappings": {"properties": {
+		"Timestamp": {"type": "keyword"}
+	}}}}`), &mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts := hook.mappingConflicts(mapping)
+	if len(conflicts) != 1 || !strings.Contains(conflicts[0], "Timestamp") {
+		t.Errorf("unexpected conflicts %v", conflicts)
+	}
+	if hook.docType() != "_doc" {
+		t.Errorf("expected the _doc type, got %q", hook.docType())
+	}
+}
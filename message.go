@@ -0,0 +1,114 @@
+package elogrus
+
+import (
+	"unicode/utf8"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Overflow selects what is done with the
+// part of a message above the limit
+type Overflow int
+
+const (
+	// OverflowTruncate drops the overflow
+	OverflowTruncate Overflow = iota
+	// OverflowField moves the overflow
+	// to the message_overflow field
+	OverflowField
+	// OverflowDocuments splits the message over
+	// several documents linked by message_chunk.id
+	// with message_chunk.index and message_chunk.count
+	OverflowDocuments
+)
+
+// WithMaxMessageLength limits messages to max
+// bytes, longer ones are handled as overflow
+// selects. Characters are never split.
+func WithMaxMessageLength(max int, overflow Overflow) Option {
+	return func(hook *ElasticHook) {
+		hook.maxMessage = max
+		hook.overflow = overflow
+	}
+}
+
+// documents returns the documents of
+// entry, more than one only when a long
+// message is split with OverflowDocuments
+func (hook *ElasticHook) documents(entry *logrus.Entry) []map[string]interface{} {
+	entry = hook.limitEntry(hook.flattenEntry(hook.maskEntry(hook.filterEntry(entry))))
+	var docs []map[string]interface{}
+	if hook.messageFunc != nil {
+		if doc, ok := hook.customDocument(entry); ok {
+			docs = []map[string]interface{}{doc}
+		}
+	}
+	if docs == nil {
+		docs = hook.split(entry)
+	}
+	docs = hook.limitDocuments(docs)
+	hook.checkSizes(entry, docs)
+	return docs
+}
+
+// stampedDocuments returns the documents of entry
+// stamped with s, linked into the audit chain last
+// so the hash covers the sampling fields
+func (hook *ElasticHook) stampedDocuments(entry *logrus.Entry, s sample) []map[string]interface{} {
+	return hook.chain(s.stamp(hook.documents(entry)))
+}
+
+// split builds the document of entry,
+// split as configured for long messages
+func (hook *ElasticHook) split(entry *logrus.Entry) []map[string]interface{} {
+	doc := hook.document(entry)
+	if hook.maxMessage <= 0 || len(entry.Message) <= hook.maxMessage {
+		return []map[string]interface{}{doc}
+	}
+
+	chunks := splitMessage(entry.Message, hook.maxMessage)
+	doc[hook.messageField()] = chunks[0]
+	switch hook.overflow {
+	case OverflowField:
+		doc["message_overflow"] = entry.Message[len(chunks[0]):]
+	case OverflowDocuments:
+		id, _ := doc[hook.correlationField].(string)
+		if id == "" {
+			id = NewULID()
+		}
+		docs := make([]map[string]interface{}, len(chunks))
+		for i, chunk := range chunks {
+			d := doc
+			if i > 0 {
+				d = make(map[string]interface{}, len(doc)+3)
+				for k, v := range doc {
+					d[k] = v
+				}
+				d[hook.messageField()] = chunk
+			}
+			d["message_chunk.id"] = id
+			d["message_chunk.index"] = i
+			d["message_chunk.count"] = len(chunks)
+			docs[i] = d
+		}
+		return docs
+	}
+	return []map[string]interface{}{doc}
+}
+
+// splitMessage cuts s in chunks of at
+// most max bytes, a chunk holds at least
+// one character even if it is longer
+func splitMessage(s string, max int) []string {
+	var chunks []string
+	for len(s) > 0 {
+		chunk := truncate(s, max)
+		if chunk == "" {
+			_, n := utf8.DecodeRuneInString(s)
+			chunk = s[:n]
+		}
+		chunks = append(chunks, chunk)
+		s = s[len(chunk):]
+	}
+	return chunks
+}
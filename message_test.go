@@ -0,0 +1,55 @@
+package elogrus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestSplitMessage(t *testing.T) {
+	chunks := splitMessage("ab€cd", 3)
+	if strings.Join(chunks, "|") != "ab|€|cd" {
+		t.Errorf("unexpected chunks %q", chunks)
+	}
+	chunks = splitMessage("€€", 2)
+	if strings.Join(chunks, "|") != "€|€" {
+		t.Errorf("chunks should hold at least one character, got %q", chunks)
+	}
+}
+
+func TestMessageOverflow(t *testing.T) {
+	entry := &logrus.Entry{Message: "0123456789", Data: logrus.Fields{}}
+
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithMaxMessageLength(4, OverflowTruncate))
+	docs := hook.documents(entry)
+	if len(docs) != 1 || docs[0]["Message"] != "0123" || docs[0]["message_overflow"] != nil {
+		t.Errorf("unexpected truncated documents %v", docs)
+	}
+
+	hook = newHook(nil, "localhost", logrus.DebugLevel, "test", WithMaxMessageLength(4, OverflowField))
+	docs = hook.documents(entry)
+	if len(docs) != 1 || docs[0]["Message"] != "0123" || docs[0]["message_overflow"] != "456789" {
+		t.Errorf("unexpected overflow documents %v", docs)
+	}
+
+	hook = newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithMaxMessageLength(4, OverflowDocuments),
+		WithCorrelationID("request_id"),
+	)
+	entry.Data["request_id"] = "req-1"
+	docs = hook.documents(entry)
+	if len(docs) != 3 {
+		t.Fatalf("expected 3 documents, got %d", len(docs))
+	}
+	var message string
+	for i, doc := range docs {
+		if doc["message_chunk.id"] != "req-1" || doc["message_chunk.index"] != i || doc["message_chunk.count"] != 3 {
+			t.Errorf("unexpected chunk fields in %v", doc)
+		}
+		message += doc["Message"].(string)
+	}
+	if message != entry.Message {
+		t.Errorf("chunks do not add up to the message: %q", message)
+	}
+}
@@ -0,0 +1,47 @@
+package elogrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// MessageFunc builds the document of an entry,
+// any value serialized to a JSON object
+type MessageFunc func(*logrus.Entry, *ElasticHook) interface{}
+
+// WithMessageFunc replaces the built-in document
+// with the one returned by fn, e.g. to rename or
+// drop fields. Long messages are not split. When
+// the value does not encode to a JSON object the
+// error is reported and the built-in document is
+// sent instead.
+func WithMessageFunc(fn MessageFunc) Option {
+	return func(hook *ElasticHook) {
+		hook.messageFunc = fn
+	}
+}
+
+// customDocument returns the document
+// built by the message function
+func (hook *ElasticHook) customDocument(entry *logrus.Entry) (map[string]interface{}, bool) {
+	v := hook.messageFunc(entry, hook)
+	if doc, ok := v.(map[string]interface{}); ok {
+		return doc, true
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		hook.reportError(fmt.Errorf("Message function failed: %w", err))
+		return nil, false
+	}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	var doc map[string]interface{}
+	if err := d.Decode(&doc); err != nil || doc == nil {
+		hook.reportError(fmt.Errorf("Message function returned %s, not an object", body))
+		return nil, false
+	}
+	return doc, true
+}
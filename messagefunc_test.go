@@ -0,0 +1,37 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestMessageFunc(t *testing.T) {
+	type event struct {
+		Text  string `json:"text"`
+		Count int    `json:"count"`
+	}
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithMessageFunc(func(entry *logrus.Entry, hook *ElasticHook) interface{} {
+			return event{Text: entry.Message, Count: 3}
+		}))
+	docs := hook.documents(&logrus.Entry{Message: "hello", Data: logrus.Fields{}})
+	if len(docs) != 1 || docs[0]["text"] != "hello" || docs[0]["count"] != json.Number("3") {
+		t.Errorf("unexpected documents %v", docs)
+	}
+	if _, ok := docs[0]["Message"]; ok {
+		t.Error("the built-in fields must not be added")
+	}
+}
+
+func TestMessageFuncNotObject(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithMessageFunc(func(*logrus.Entry, *ElasticHook) interface{} {
+			return "just text"
+		}))
+	docs := hook.documents(&logrus.Entry{Message: "hello", Data: logrus.Fields{}})
+	if len(docs) != 1 || docs[0]["Message"] != "hello" {
+		t.Errorf("expected the built-in document, got %v", docs)
+	}
+}
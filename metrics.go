@@ -0,0 +1,110 @@
+package elogrus
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Observer receives the delivery outcome of
+// every document, e.g. to export metrics.
+// Implementations must be safe for
+// concurrent use.
+type Observer interface {
+	// Delivered is called once per document, err
+	// is nil when ElasticSearch accepted it. labels
+	// holds the fields set up with WithMetricLabels.
+	Delivered(labels map[string]string, err error)
+}
+
+// WithObserver reports delivery
+// outcomes to observer
+func WithObserver(observer Observer) Option {
+	return func(hook *ElasticHook) {
+		hook.observer = observer
+	}
+}
+
+// WithMetricLabels passes the given fields to the
+// Observer as labels. Keep the list to fields of
+// low cardinality; "level" and "index" are the
+// entry level and target index, other names are
+// taken from the entry data.
+func WithMetricLabels(fields ...string) Option {
+	return func(hook *ElasticHook) {
+		hook.metricLabels = fields
+	}
+}
+
+// labels returns the metric
+// labels of entry
+func (hook *ElasticHook) labels(entry *logrus.Entry, index string) map[string]string {
+	if hook.observer == nil || len(hook.metricLabels) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(hook.metricLabels))
+	for _, name := range hook.metricLabels {
+		switch name {
+		case "level":
+			labels[name] = entry.Level.String()
+		case "index":
+			labels[name] = index
+		default:
+			if v, ok := entry.Data[name]; ok && v != nil {
+				labels[name] = fmt.Sprint(v)
+			} else {
+				labels[name] = ""
+			}
+		}
+	}
+	return labels
+}
+
+// observe reports the outcome
+// of a single document
+func (hook *ElasticHook) observe(labels map[string]string, err error) {
+	root := hook.root()
+	switch err {
+	case nil:
+		atomic.AddInt64(&root.sent, 1)
+	case ErrDropped:
+		atomic.AddInt64(&root.dropped, 1)
+	default:
+		atomic.AddInt64(&root.failed, 1)
+		hook.recordFailure(err)
+	}
+	if hook.observer != nil {
+		hook.observer.Delivered(labels, err)
+	}
+	if hook.alerts != nil {
+		hook.alerts.record(err, time.Now())
+	}
+}
+
+// OperationObserver is an optional extension of
+// Observer, it is called on retries and with
+// the outcome of every bulk flush
+type OperationObserver interface {
+	Retried(err error)
+	Flushed(documents int, latency time.Duration, err error)
+}
+
+// retried accounts a retry after err
+func (hook *ElasticHook) retried(err error) {
+	atomic.AddInt64(&hook.root().retries, 1)
+	if o, ok := hook.observer.(OperationObserver); ok {
+		o.Retried(err)
+	}
+}
+
+// flushed accounts a bulk request
+func (hook *ElasticHook) flushed(documents int, latency time.Duration, err error) {
+	root := hook.root()
+	atomic.AddInt64(&root.flushes, 1)
+	atomic.AddInt64(&root.flushNanos, int64(latency))
+	if o, ok := hook.observer.(OperationObserver); ok {
+		o.Flushed(documents, latency, err)
+	}
+}
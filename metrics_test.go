@@ -0,0 +1,50 @@
+package elogrus
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type countingObserver struct {
+	mu        sync.Mutex
+	delivered map[string]int
+	failed    map[string]int
+}
+
+func newCountingObserver() *countingObserver {
+	return &countingObserver{delivered: map[string]int{}, failed: map[string]int{}}
+}
+
+func (o *countingObserver) Delivered(labels map[string]string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	key := labels["level"] + "/" + labels["service"]
+	if err != nil {
+		o.failed[key]++
+	} else {
+		o.delivered[key]++
+	}
+}
+
+func TestMetricLabels(t *testing.T) {
+	obs := newCountingObserver()
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithObserver(obs),
+		WithMetricLabels("level", "service", "index"),
+	)
+	entry := &logrus.Entry{Level: logrus.WarnLevel, Data: logrus.Fields{"service": "billing", "user": "joe"}}
+
+	labels := hook.labels(entry, "test")
+	if len(labels) != 3 || labels["level"] != "warning" || labels["service"] != "billing" || labels["index"] != "test" {
+		t.Errorf("unexpected labels %v", labels)
+	}
+
+	hook.observe(labels, nil)
+	hook.observe(labels, errors.New("rejected"))
+	if obs.delivered["warning/billing"] != 1 || obs.failed["warning/billing"] != 1 {
+		t.Errorf("unexpected counts %v %v", obs.delivered, obs.failed)
+	}
+}
@@ -0,0 +1,136 @@
+package elogrus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+var (
+	// Fired if an OpenSearch indexer
+	// is created without URL
+	ErrNoOpenSearchURL = fmt.Errorf("OpenSearch URL is required")
+)
+
+// OpenSearchConfig configures an OpenSearchIndexer
+type OpenSearchConfig struct {
+	// URL of the cluster,
+	// e.g. https://localhost:9200
+	URL string
+	// Username and Password enable
+	// basic authentication
+	Username string
+	Password string
+	// Client defaults to one with
+	// a 30 second timeout
+	Client *http.Client
+	// Compression gzips the bulk requests, its
+	// Transport defaults to the one of Client
+	Compression *CompressTransport
+}
+
+// OpenSearchIndexer sends documents to the bulk API
+// of OpenSearch, or any cluster speaking the typeless
+// ElasticSearch 7 API, over plain HTTP
+type OpenSearchIndexer struct {
+	config OpenSearchConfig
+}
+
+// NewOpenSearchIndexer creates an indexer for config
+func NewOpenSearchIndexer(config OpenSearchConfig) (*OpenSearchIndexer, error) {
+	if config.URL == "" {
+		return nil, ErrNoOpenSearchURL
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if c := config.Compression; c != nil {
+		if c.Transport == nil {
+			c.Transport = config.Client.Transport
+		}
+		client := *config.Client
+		client.Transport = c
+		config.Client = &client
+	}
+	return &OpenSearchIndexer{config: config}, nil
+}
+
+// NewOpenSearchHook creates a hook indexing entries in
+// OpenSearch, whose product the ElasticSearch clients
+// may reject. Indices are created by OpenSearch on
+// first use, see index templates for their mappings.
+// The other parameters are the same as for
+// NewElasticHook.
+func NewOpenSearchHook(config OpenSearchConfig, host string, level logrus.Level, index string, opts ...Option) (*ElasticHook, error) {
+	indexer, err := NewOpenSearchIndexer(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewForwardingHook(indexer, host, level, index, opts...)
+}
+
+// Forward is required to implement Forwarder,
+// documents with ID are created, so retries
+// do not duplicate them
+func (i *OpenSearchIndexer) Forward(ctx context.Context, docs []ForwardedDocument) ([]error, error) {
+	var body bytes.Buffer
+	for _, doc := range docs {
+		meta := map[string]string{"_index": doc.Index}
+		op := "index"
+		if doc.ID != "" {
+			meta["_id"] = doc.ID
+		}
+		if doc.ID != "" || doc.Create {
+			op = "create"
+		}
+		if doc.Pipeline != "" {
+			meta["pipeline"] = doc.Pipeline
+		}
+		if doc.Routing != "" {
+			meta["routing"] = doc.Routing
+		}
+		action, err := json.Marshal(map[string]interface{}{op: meta})
+		if err != nil {
+			return nil, err
+		}
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(doc.Body)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest("POST", i.config.URL+"/_bulk", &body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if i.config.Username != "" {
+		req.SetBasicAuth(i.config.Username, i.config.Password)
+	}
+	resp, err := i.config.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil, &elastic.Error{Status: resp.StatusCode}
+	}
+	var result elastic.BulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return bulkErrors(&result, len(docs))
+}
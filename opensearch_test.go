@@ -0,0 +1,72 @@
+package elogrus
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestOpenSearchHook(t *testing.T) {
+	var lines []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		s := bufio.NewScanner(r.Body)
+		for s.Scan() {
+			lines = append(lines, s.Text())
+		}
+		w.Write([]byte(`{"errors":true,"items":[{"create":{"status":201}},{"create":{"status":400,"error":{"type":"mapper_parsing_exception"}}}]}`))
+	}))
+	defer srv.Close()
+
+	var failed []error
+	hook, err := NewOpenSearchHook(OpenSearchConfig{URL: srv.URL + "/"}, "localhost", logrus.DebugLevel, "mylog",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithDocumentIDs("web-1"),
+		WithErrorHandler(func(err error, doc json.RawMessage) { failed = append(failed, err) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook.Fire(&logrus.Entry{Message: "indexed", Data: logrus.Fields{}})
+	hook.Fire(&logrus.Entry{Message: "rejected", Data: logrus.Fields{}})
+	hook.Flush()
+
+	if len(lines) != 4 || lines[0] != `{"create":{"_id":"web-1-1","_index":"mylog"}}` || !strings.Contains(lines[1], "indexed") {
+		t.Fatalf("unexpected bulk body %v", lines)
+	}
+	if len(failed) != 1 {
+		t.Errorf("expected the rejected document to fail, got %v", failed)
+	}
+	if s := hook.Stats(); s.Sent != 1 || s.Failed != 1 {
+		t.Errorf("expected 1 sent and 1 failed, got %d and %d", s.Sent, s.Failed)
+	}
+}
+
+func TestOpenSearchCompression(t *testing.T) {
+	var encoding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		w.Write([]byte(`{"errors":false,"items":[{"index":{"status":201}}]}`))
+	}))
+	defer srv.Close()
+
+	compression := &CompressTransport{Threshold: 1}
+	hook, err := NewOpenSearchHook(OpenSearchConfig{URL: srv.URL, Compression: compression}, "localhost", logrus.DebugLevel, "mylog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hook.Fire(&logrus.Entry{Message: "compressed", Data: logrus.Fields{}}); err != nil {
+		t.Fatal(err)
+	}
+	if encoding != "gzip" || compression.Stats().CompressedRequests != 1 {
+		t.Errorf("expected a compressed request, got %q and %+v", encoding, compression.Stats())
+	}
+}
@@ -0,0 +1,38 @@
+package elogrus
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// TimeOperation starts timing the operation name and
+// returns a func logging its completion through entry
+// with event.action, event.duration (nanoseconds) and
+// event.outcome "success":
+//
+//	defer elogrus.TimeOperation(log.WithField("order", id), "checkout")()
+func TimeOperation(entry *logrus.Entry, name string) func() {
+	done := TimeOperationError(entry, name)
+	return func() {
+		done(nil)
+	}
+}
+
+// TimeOperationError is like TimeOperation, the returned
+// func takes the result of the operation: a non-nil err
+// gives event.outcome "failure" and is logged at error level
+func TimeOperationError(entry *logrus.Entry, name string) func(err error) {
+	start := time.Now()
+	return func(err error) {
+		e := entry.WithFields(logrus.Fields{
+			"event.action":   name,
+			"event.duration": time.Since(start).Nanoseconds(),
+		})
+		if err != nil {
+			e.WithError(err).WithField("event.outcome", "failure").Error(name + " failed")
+			return
+		}
+		e.WithField("event.outcome", "success").Info(name + " completed")
+	}
+}
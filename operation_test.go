@@ -0,0 +1,34 @@
+package elogrus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestTimeOperation(t *testing.T) {
+	logger := logrus.New()
+	rec := &recordingHook{}
+	logger.Hooks.Add(rec)
+
+	done := TimeOperation(logrus.NewEntry(logger), "checkout")
+	time.Sleep(time.Millisecond)
+	done()
+	TimeOperationError(logrus.NewEntry(logger), "refund")(errors.New("declined"))
+
+	if len(rec.entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(rec.entries))
+	}
+	ok, failed := rec.entries[0], rec.entries[1]
+	if ok.Data["event.outcome"] != "success" || ok.Data["event.action"] != "checkout" {
+		t.Errorf("unexpected success fields %v", ok.Data)
+	}
+	if d, _ := ok.Data["event.duration"].(int64); d < int64(time.Millisecond) {
+		t.Errorf("expected duration of at least 1ms, got %v", ok.Data["event.duration"])
+	}
+	if failed.Level != logrus.ErrorLevel || failed.Data["event.outcome"] != "failure" {
+		t.Errorf("unexpected failure entry %v %v", failed.Level, failed.Data)
+	}
+}
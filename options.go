@@ -0,0 +1,505 @@
+package elogrus
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Option configures optional
+// behaviour of the ElasticHook
+type Option func(*ElasticHook)
+
+// optionErr records an invalid option,
+// returned by the constructor
+func (hook *ElasticHook) optionErr(err error) {
+	if hook.err == nil {
+		hook.err = err
+	}
+}
+
+// WithHost sets the host of
+// the system, see New
+func WithHost(host string) Option {
+	return func(hook *ElasticHook) {
+		hook.host = host
+	}
+}
+
+// WithIndex sets the index of the hook,
+// e.g. to give a clone its own index
+func WithIndex(index string) Option {
+	return func(hook *ElasticHook) {
+		hook.index = index
+	}
+}
+
+// WithLevels sets the levels
+// the hook fires for
+func WithLevels(levels ...logrus.Level) Option {
+	return func(hook *ElasticHook) {
+		hook.levels = levelMask(levels)
+	}
+}
+
+// WithContext sets the parent context of the
+// requests made by the hook, cancelling it
+// aborts them
+func WithContext(ctx context.Context) Option {
+	return func(hook *ElasticHook) {
+		hook.parentCtx = ctx
+	}
+}
+
+// WithDocumentType sets the document type, default
+// "_doc", or "log" on ElasticSearch 5 and older
+func WithDocumentType(typ string) Option {
+	return func(hook *ElasticHook) {
+		hook.typ = typ
+	}
+}
+
+// WithType sets the document type.
+//
+// Deprecated: use WithDocumentType.
+func WithType(typ string) Option {
+	return WithDocumentType(typ)
+}
+
+// WithTypeless targets ElasticSearch 6 and 7, which allow
+// a single mapping type: documents use the "_doc" type,
+// mappings are read and templates written without type
+func WithTypeless() Option {
+	return func(hook *ElasticHook) {
+		hook.typ = "_doc"
+		hook.typeless = true
+	}
+}
+
+// docType returns the document type, of the hook
+// or else of the root, as it may be detected
+// after cloning
+func (hook *ElasticHook) docType() string {
+	if hook.typ != "" {
+		return hook.typ
+	}
+	if typ := hook.root().typ; typ != "" {
+		return typ
+	}
+	return "_doc"
+}
+
+// bulkType returns the type of bulk actions,
+// empty for typeless clusters, which reject it
+func (hook *ElasticHook) bulkType() string {
+	if hook.root().typeless {
+		return ""
+	}
+	return hook.docType()
+}
+
+// WithLevelField renames the field
+// holding the level name, e.g. to
+// "log.level" or "severity"
+func WithLevelField(name string) Option {
+	return func(hook *ElasticHook) {
+		hook.levelField = name
+	}
+}
+
+// TimestampEpochMillis formats timestamps
+// as milliseconds since the epoch
+const TimestampEpochMillis = "epoch_millis"
+
+// WithTimestampField renames the field holding
+// the entry time, e.g. to "@timestamp"
+func WithTimestampField(name string) Option {
+	return func(hook *ElasticHook) {
+		hook.timestampField = name
+	}
+}
+
+// WithTimestampFormat formats the entry time with
+// layout, e.g. time.RFC3339, or as epoch millis
+// with TimestampEpochMillis. The default is
+// time.RFC3339Nano in UTC. Custom layouts need
+// a matching date format in the mapping.
+func WithTimestampFormat(layout string) Option {
+	return func(hook *ElasticHook) {
+		hook.timestampFormat = layout
+	}
+}
+
+// timestamp formats t for
+// the timestamp field
+func (hook *ElasticHook) timestamp(t time.Time) interface{} {
+	switch hook.timestampFormat {
+	case "":
+		return t.UTC().Format(time.RFC3339Nano)
+	case TimestampEpochMillis:
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+	return t.UTC().Format(hook.timestampFormat)
+}
+
+// WithSeverityField emits the syslog
+// severity number of the entry level
+// under the given field name
+func WithSeverityField(name string) Option {
+	return func(hook *ElasticHook) {
+		hook.severityField = name
+	}
+}
+
+// WithLevelValue emits the numeric logrus
+// level next to the level name under the
+// given field name, e.g. "level_value"
+func WithLevelValue(name string) Option {
+	return func(hook *ElasticHook) {
+		hook.levelValueField = name
+	}
+}
+
+// WithMaxInFlight caps the number of requests
+// the hook has open against the cluster at any
+// time; further sends wait for a free slot
+func WithMaxInFlight(n int) Option {
+	return func(hook *ElasticHook) {
+		if n > 0 {
+			hook.inFlight = make(chan struct{}, n)
+		}
+	}
+}
+
+// acquire takes an in-flight slot,
+// giving up when the hook shuts down
+func (hook *ElasticHook) acquire() error {
+	if hook.inFlight == nil {
+		return nil
+	}
+	select {
+	case hook.inFlight <- struct{}{}:
+		return nil
+	case <-hook.ctx.Done():
+		return hook.ctx.Err()
+	}
+}
+
+func (hook *ElasticHook) release() {
+	if hook.inFlight != nil {
+		<-hook.inFlight
+	}
+}
+
+// Options is the effective configuration
+// of a hook, after defaults are applied.
+// Unset optional features are nil.
+type Options struct {
+	Host       string
+	Index      string
+	Type       string
+	Typeless   bool
+	DataStream bool
+	Levels     []logrus.Level
+
+	LevelField       string
+	TimestampField   string
+	TimestampFormat  string
+	SeverityField    string
+	LevelValueField  string
+	CorrelationField string
+	IndexPattern     string
+	Pipeline         string
+	Routing          bool
+	// Enrichment holds the fields
+	// of WithEnrichment
+	Enrichment map[string]interface{}
+	// Clusters names the clusters of
+	// WithClusters, the primary first
+	Clusters         []string
+	ClusterMode      ClusterMode
+	FieldTypes       map[string]FieldType
+	MaxMessageLength int
+	FieldLimits      *FieldLimits
+	MaxDocumentSize  int
+	Flattening       *FlattenConfig
+	Overflow         Overflow
+	MessageTemplate  bool
+	ECS              bool
+	Filebeat         *FilebeatConfig
+	ErrorDetails     bool
+	ErrorStack       bool
+	EventCategory    *EventCategory
+	Caller           *CallerConfig
+	PII              *PIIConfig
+	GeoPoints        []GeoConfig
+	UserAgentField   string
+	EventSequence    bool
+	OriginNamespace  string
+	InstanceID       string
+	AuditInstance    string
+	StaticFields     logrus.Fields
+	StrictFields     []string
+
+	// Bulk holds the current batch size and
+	// interval, which adaptive batching changes
+	Bulk          *BulkConfig
+	Adaptive      *AdaptiveConfig
+	Throttle      *HealthThrottleConfig
+	LocalOnly     *LocalOnlyConfig
+	GapReports    *GapReportConfig
+	SLO           *SLOConfig
+	Async         *AsyncConfig
+	FlushLevel    logrus.Level
+	FlushOnLevel  bool
+	MaxInFlight   int
+	LazyBootstrap bool
+	BatchIDs      bool
+	Timeouts      TimeoutConfig
+	// RequestTimeout bounds the
+	// delivery of each entry
+	RequestTimeout time.Duration
+	// EntryCancellation is set by
+	// WithEntryCancellation
+	EntryCancellation bool
+	StopTimeout       time.Duration
+
+	Breaker          *BreakerConfig
+	Fatal            *FatalConfig
+	Retry            *RetryConfig
+	Spool            *SpoolConfig
+	BreakerStateFile string
+	Quota            *QuotaConfig
+	Budget           *BudgetConfig
+	Sampling         *SamplingConfig
+	AlertRules       []AlertRule
+	SizeWarnings     *SizeWarningConfig
+	Warmup           *WarmupConfig
+	RuntimeMetrics   *RuntimeMetricsConfig
+	Retention        *RetentionConfig
+	Lease            *LeaseConfig
+	Template         *TemplateConfig
+
+	WriteOnly     bool
+	IndexMappings bool
+	SelfTest      bool
+	MappingCheck  bool
+	DeliveryTrace bool
+}
+
+// Options returns a copy of the effective
+// configuration, for wrappers and tests
+func (hook *ElasticHook) Options() Options {
+	o := Options{
+		Host:             hook.host,
+		Index:            hook.index,
+		Type:             hook.docType(),
+		Typeless:         hook.typeless,
+		DataStream:       hook.dataStream,
+		Levels:           hook.enabledLevels(),
+		LevelField:       hook.levelField,
+		TimestampField:   hook.timestampField,
+		TimestampFormat:  hook.timestampFormat,
+		SeverityField:    hook.severityField,
+		LevelValueField:  hook.levelValueField,
+		CorrelationField: hook.correlationField,
+		IndexPattern:     hook.patternSource,
+		Pipeline:         hook.pipeline,
+		MaxMessageLength: hook.maxMessage,
+		Overflow:         hook.overflow,
+		MessageTemplate:  hook.messageTemplate,
+		ECS:              hook.ecs,
+		ErrorDetails:     hook.errorDetails,
+		ErrorStack:       hook.errorStack,
+		GeoPoints:        append([]GeoConfig(nil), hook.geoPoints...),
+		UserAgentField:   hook.userAgentField,
+		InstanceID:       hook.instanceID,
+		FlushLevel:       hook.flushLevel,
+		FlushOnLevel:     hook.flushOnLevel,
+		MaxInFlight:      cap(hook.inFlight),
+		BatchIDs:         hook.batchIDs,
+		LazyBootstrap:    hook.root().lazyBootstrap,
+		EventSequence:    hook.eventSequence,
+		Routing:          hook.routingFunc != nil,
+		OriginNamespace:  hook.originNamespace,
+		Timeouts:         hook.timeouts,
+		RequestTimeout:   hook.requestTimeout,
+
+		EntryCancellation: hook.entryCancellation,
+		StopTimeout:       hook.stopTimeout,
+		BreakerStateFile:  hook.breakerStateFile,
+		WriteOnly:         hook.writeOnly,
+		IndexMappings:     hook.createMappings,
+		SelfTest:          hook.selfTest,
+		MappingCheck:      hook.mappingCheck,
+		DeliveryTrace:     hook.deliveryTrace,
+	}
+	if hook.fieldTypes != nil {
+		o.FieldTypes = make(map[string]FieldType, len(hook.fieldTypes))
+		for k, v := range hook.fieldTypes {
+			o.FieldTypes[k] = v
+		}
+	}
+	if hook.staticFields != nil {
+		o.StaticFields = make(logrus.Fields, len(hook.staticFields))
+		for k, v := range hook.staticFields {
+			o.StaticFields[k] = v
+		}
+	}
+	for f := range hook.strictFields {
+		o.StrictFields = append(o.StrictFields, f)
+	}
+	sort.Strings(o.StrictFields)
+	if b := hook.bulk; b != nil {
+		b.mu.Lock()
+		o.Bulk = &BulkConfig{
+			Actions:       b.actions,
+			FlushInterval: b.interval,
+			Workers:       cap(b.workers),
+			MaxBytes:      b.maxBytes,
+			MaxPending:    b.maxPending,
+			Policy:        b.policy,
+			BlockTimeout:  b.blockTimeout,
+			Jitter:        b.jitter,
+		}
+		b.mu.Unlock()
+	}
+	if hook.eventCategory != nil {
+		c := *hook.eventCategory
+		c.Category = append([]string(nil), c.Category...)
+		c.Type = append([]string(nil), c.Type...)
+		o.EventCategory = &c
+	}
+	if hook.caller != nil {
+		c := *hook.caller
+		c.SkipPackages = append([]string(nil), c.SkipPackages...)
+		o.Caller = &c
+	}
+	if hook.fieldLimits != nil {
+		c := hook.fieldLimits.FieldLimits
+		c.Fields = make(map[string]int, len(c.Fields))
+		for k, v := range hook.fieldLimits.Fields {
+			c.Fields[k] = v
+		}
+		o.FieldLimits = &c
+	}
+	if hook.flattener != nil {
+		c := hook.flattener.FlattenConfig
+		o.Flattening = &c
+	}
+	if hook.docLimit != nil {
+		o.MaxDocumentSize = hook.docLimit.max
+	}
+	if hook.pii != nil {
+		c := hook.pii.config
+		o.PII = &c
+	}
+	if hook.audit != nil {
+		o.AuditInstance = hook.audit.instance
+	}
+	if hook.async != nil {
+		c := hook.async.config
+		o.Async = &c
+	}
+	if hook.adaptive != nil {
+		c := *hook.adaptive
+		o.Adaptive = &c
+	}
+	if hook.throttle != nil {
+		c := hook.throttle.config
+		o.Throttle = &c
+	}
+	if hook.localOnly != nil {
+		c := hook.localOnly.config
+		o.LocalOnly = &c
+	}
+	if hook.gaps != nil {
+		c := hook.gaps.config
+		o.GapReports = &c
+	}
+	if slo := hook.root().slo; slo != nil {
+		c := slo.config
+		o.SLO = &c
+	}
+	if hook.spool != nil {
+		c := hook.spool.config
+		o.Spool = &c
+	}
+	if hook.retry != nil {
+		c := *hook.retry
+		o.Retry = &c
+	}
+	if hook.breaker != nil {
+		c := hook.breaker.config
+		o.Breaker = &c
+	}
+	if hook.quota != nil {
+		c := hook.quota.config
+		o.Quota = &c
+	}
+	if hook.filebeat != nil {
+		c := *hook.filebeat
+		o.Filebeat = &c
+	}
+	if hook.sampler != nil {
+		c := hook.sampler.config
+		c.Every = make(map[logrus.Level]int, len(c.Every))
+		for level, n := range hook.sampler.config.Every {
+			c.Every[level] = n
+		}
+		o.Sampling = &c
+	}
+	if hook.budget != nil {
+		c := hook.budget.config
+		o.Budget = &c
+	}
+	if hook.sizeWarnings != nil {
+		c := hook.sizeWarnings.config
+		o.SizeWarnings = &c
+	}
+	for _, r := range hook.alertRules {
+		o.AlertRules = append(o.AlertRules, r.AlertRule)
+	}
+	if hook.warmup != nil {
+		c := *hook.warmup
+		c.Fields = append([]logrus.Fields(nil), c.Fields...)
+		o.Warmup = &c
+	}
+	if hook.runtimeMetrics != nil {
+		c := *hook.runtimeMetrics
+		o.RuntimeMetrics = &c
+	}
+	if hook.retention != nil {
+		c := hook.retention.RetentionConfig
+		o.Retention = &c
+	}
+	if hook.lease != nil {
+		c := *hook.lease
+		o.Lease = &c
+	}
+	if hook.indexTemplate != nil {
+		c := *hook.indexTemplate
+		c.Patterns = append([]string(nil), c.Patterns...)
+		c.Components = append([]string(nil), c.Components...)
+		o.Template = &c
+	}
+	if hook.enrichment != nil {
+		o.Enrichment = make(map[string]interface{}, len(hook.enrichment))
+		for k, v := range hook.enrichment {
+			o.Enrichment[k] = v
+		}
+	}
+	if hook.fatal != nil {
+		c := *hook.fatal
+		o.Fatal = &c
+	}
+	if s := hook.root().clusters; s != nil {
+		o.ClusterMode = s.mode
+		for _, c := range s.clusters {
+			o.Clusters = append(o.Clusters, c.name)
+		}
+	}
+	return o
+}
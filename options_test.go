@@ -0,0 +1,133 @@
+package elogrus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestMaxInFlight(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithMaxInFlight(2))
+
+	var current, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hook.do(func(*elastic.Client) error {
+				n := atomic.AddInt32(&current, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&current, -1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("expected at most 2 requests in flight, saw %d", peak)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.InfoLevel, "test",
+		WithLevelField("log.level"),
+		WithIndexPattern("logs-{yyyy.MM.dd}", nil),
+		WithBulk(BulkConfig{Actions: 10, FlushInterval: time.Hour}),
+	)
+	defer hook.Close()
+
+	o := hook.Options()
+	if o.Host != "localhost" || o.Index != "test" || o.LevelField != "log.level" || o.IndexPattern != "logs-{yyyy.MM.dd}" {
+		t.Errorf("unexpected options %+v", o)
+	}
+	if len(o.Levels) != 5 || o.MaxInFlight != 0 || o.Breaker != nil {
+		t.Errorf("unexpected options %+v", o)
+	}
+	if o.Bulk == nil || o.Bulk.Actions != 10 || o.Bulk.FlushInterval != time.Hour || o.Bulk.Workers != 1 {
+		t.Errorf("unexpected bulk options %+v", o.Bulk)
+	}
+
+	o.Levels[0] = logrus.DebugLevel
+	if hook.Levels()[0] != logrus.PanicLevel {
+		t.Error("Options should return a copy")
+	}
+}
+
+func TestNewRequiresIndex(t *testing.T) {
+	if _, err := New(nil, WithHost("localhost")); err != ErrNoIndex {
+		t.Errorf("expected ErrNoIndex, got %v", err)
+	}
+}
+
+func TestConstructorOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	hook := newHook(nil, "", logrus.DebugLevel, "",
+		WithHost("web-1"),
+		WithIndex("app"),
+		WithLevels(logrus.ErrorLevel),
+		WithType("event"),
+		WithContext(ctx),
+	)
+	o := hook.Options()
+	if o.Host != "web-1" || o.Index != "app" || o.Type != "event" || len(o.Levels) != 1 {
+		t.Errorf("unexpected options %+v", o)
+	}
+	cancel()
+	if hook.ctx.Err() == nil || hook.controlCtx.Err() == nil {
+		t.Error("cancelling the parent context should abort the requests of the hook")
+	}
+}
+
+func TestTimestampFormat(t *testing.T) {
+	at := time.Date(2024, 5, 17, 10, 0, 0, 500e6, time.FixedZone("CEST", 2*3600))
+	for _, c := range []struct {
+		format string
+		expect interface{}
+	}{
+		{"", "2024-05-17T08:00:00.5Z"},
+		{time.RFC3339, "2024-05-17T08:00:00Z"},
+		{TimestampEpochMillis, int64(1715932800500)},
+	} {
+		hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+			WithTimestampField("@timestamp"), WithTimestampFormat(c.format))
+		doc := hook.document(&logrus.Entry{Time: at, Data: logrus.Fields{}})
+		if doc["@timestamp"] != c.expect {
+			t.Errorf("format %q: expected %v, got %v", c.format, c.expect, doc["@timestamp"])
+		}
+		if _, ok := doc["Timestamp"]; ok {
+			t.Error("expected the timestamp field to be renamed")
+		}
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.InfoLevel, "test", WithBulk(BulkConfig{FlushInterval: time.Hour}))
+	debug := &logrus.Entry{Level: logrus.DebugLevel, Message: "verbose", Data: logrus.Fields{}}
+
+	hook.Fire(debug)
+	hook.SetLevel(logrus.DebugLevel)
+	hook.Fire(debug)
+	if n := len(hook.bulk.take()); n != 1 {
+		t.Errorf("expected only the entry after SetLevel, got %d", n)
+	}
+	if len(hook.Levels()) != len(allLevels) {
+		t.Errorf("expected logrus to see all levels, got %v", hook.Levels())
+	}
+
+	hook.SetLevels([]logrus.Level{logrus.ErrorLevel})
+	if levels := hook.Options().Levels; len(levels) != 1 || levels[0] != logrus.ErrorLevel {
+		t.Errorf("unexpected levels %v", levels)
+	}
+}
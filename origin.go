@@ -0,0 +1,68 @@
+package elogrus
+
+import "github.com/Sirupsen/logrus"
+
+// DefaultOriginNamespace holds the fields
+// the hook adds about the origin of entries
+const DefaultOriginNamespace = "origin"
+
+// WithOriginNamespace puts the fields the hook adds about the
+// origin of entries under name, "origin" by default: the host,
+// the caller and the static fields become origin.host,
+// origin.file, origin.line, origin.function and e.g.
+// origin.service, so they never collide with entry data, which
+// ECS puts at the top level. Static fields are still merged
+// into the entry data routers, classifiers and quotas see,
+// only the document holds them under the namespace. The
+// Filebeat layout keeps its fields. Empty is WithFlatOrigin.
+func WithOriginNamespace(name string) Option {
+	return func(hook *ElasticHook) {
+		hook.originNamespace = name
+	}
+}
+
+// WithFlatOrigin keeps the flat layout of earlier versions:
+// Host and the caller fields at the top level and static
+// fields in the entry data, for existing dashboards
+func WithFlatOrigin() Option {
+	return WithOriginNamespace("")
+}
+
+// namespace returns the origin
+// namespace, empty when flat
+func (hook *ElasticHook) namespace() string {
+	if hook.filebeat != nil {
+		return ""
+	}
+	return hook.originNamespace
+}
+
+// originField returns the name of an origin
+// field, flat unless there is a namespace
+func (hook *ElasticHook) originField(flat, name string) string {
+	if hook.namespace() == "" {
+		return flat
+	}
+	return hook.namespace() + "." + name
+}
+
+// addOriginFields adds the static
+// fields under the namespace
+func (hook *ElasticHook) addOriginFields(doc map[string]interface{}) {
+	if hook.namespace() == "" {
+		return
+	}
+	for k, v := range hook.staticFields {
+		doc[hook.namespace()+"."+k] = v
+	}
+}
+
+// removeStaticFields removes the fields data holds
+// from the static fields, which the namespace holds
+func (hook *ElasticHook) removeStaticFields(data logrus.Fields) {
+	for k, v := range hook.staticFields {
+		if (staticField{value: v}).holds(data[k]) {
+			delete(data, k)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package elogrus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestOriginNamespace(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithECSFormat(),
+		WithCaller(CallerConfig{}),
+		WithStaticFields(logrus.Fields{"service": "api"}),
+		WithOriginNamespace("origin"),
+	)
+	entry := hook.addStaticFields(&logrus.Entry{Data: logrus.Fields{"host.name": "db-1", "service": "billing"}})
+	doc := hook.document(entry)
+
+	if doc["host.name"] != "db-1" || doc["service"] != "billing" {
+		t.Errorf("expected the entry fields to be kept, got %v", doc)
+	}
+	if doc["origin.host"] != "localhost" || doc["origin.service"] != "api" {
+		t.Errorf("expected the origin fields, got %v", doc)
+	}
+	if file, _ := doc["origin.file"].(string); !strings.HasSuffix(file, "origin_test.go") {
+		t.Errorf("expected the caller under origin, got %v", doc)
+	}
+	if _, ok := doc[CallerFileField]; ok {
+		t.Errorf("expected no flat caller field, got %v", doc)
+	}
+	if fields := hook.emittedFields(); fields["origin.host"] != "string" || fields["origin.line"] != "long" {
+		t.Errorf("unexpected mapping %v", fields)
+	}
+}
+
+func TestOriginNamespaceDefault(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithStaticFields(logrus.Fields{ServiceField: "api", "env": "prod"}),
+	)
+	entry := hook.addStaticFields(&logrus.Entry{Data: logrus.Fields{"env": "staging"}})
+	if ServiceRouting(entry) != "api" {
+		t.Errorf("expected routers to see the static fields, got %v", entry.Data)
+	}
+	doc := hook.document(entry)
+	if doc["origin.host"] != "localhost" || doc["origin."+ServiceField] != "api" {
+		t.Errorf("expected the origin fields, got %v", doc)
+	}
+	data := doc["Data"].(logrus.Fields)
+	if _, ok := data[ServiceField]; ok || data["env"] != "staging" {
+		t.Errorf("expected only the entry fields in Data, got %v", data)
+	}
+
+	flat := newHook(nil, "localhost", logrus.DebugLevel, "test", WithFlatOrigin())
+	if doc := flat.document(&logrus.Entry{Data: logrus.Fields{}}); doc["Host"] != "localhost" {
+		t.Errorf("expected the flat layout, got %v", doc)
+	}
+}
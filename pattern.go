@@ -0,0 +1,137 @@
+package elogrus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// WithIndexPattern routes entries to indices named by
+// pattern, e.g. "logs-{service}-{env}-{yyyy.MM.dd}".
+// Placeholders are resolved from vars, then "host"
+// from the hook and finally from the entry data;
+// placeholders made of y, M, d and H (optionally
+// m and s) format the entry time in UTC. Missing
+// values become "unknown". An invalid pattern is
+// reported by the constructor.
+func WithIndexPattern(pattern string, vars map[string]string) Option {
+	return func(hook *ElasticHook) {
+		p, err := parseIndexPattern(pattern)
+		if err != nil {
+			hook.optionErr(err)
+			return
+		}
+		hook.pattern = p
+		hook.patternSource = pattern
+		hook.router = func(entry *logrus.Entry) string {
+			return p.resolve(entry, func(name string) (string, bool) {
+				if v, ok := vars[name]; ok {
+					return v, true
+				}
+				if name == "host" {
+					return hook.host, true
+				}
+				return "", false
+			})
+		}
+	}
+}
+
+type patternPart struct {
+	literal string
+	name    string
+	layout  string
+}
+
+type indexPattern []patternPart
+
+func parseIndexPattern(pattern string) (indexPattern, error) {
+	var p indexPattern
+	rest := pattern
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if end := strings.IndexByte(rest, '}'); end >= 0 && (open < 0 || end < open) {
+			return nil, fmt.Errorf("Unexpected } in index pattern %q", pattern)
+		}
+		if open < 0 {
+			p = append(p, patternPart{literal: rest})
+			break
+		}
+		if open > 0 {
+			p = append(p, patternPart{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("Unclosed { in index pattern %q", pattern)
+		}
+		name := rest[open+1 : open+end]
+		if name == "" {
+			return nil, fmt.Errorf("Empty placeholder in index pattern %q", pattern)
+		}
+		if layout, ok := dateLayout(name); ok {
+			p = append(p, patternPart{layout: layout})
+		} else {
+			p = append(p, patternPart{name: name})
+		}
+		rest = rest[open+end+1:]
+	}
+	return p, nil
+}
+
+// dateLayout converts a Java style date
+// format to a Go time layout
+func dateLayout(format string) (string, bool) {
+	if strings.Trim(format, "yMdHms.-_") != "" || !strings.ContainsAny(format, "yMdH") {
+		return "", false
+	}
+	layout := strings.NewReplacer(
+		"yyyy", "2006",
+		"yy", "06",
+		"MM", "01",
+		"dd", "02",
+		"HH", "15",
+		"mm", "04",
+		"ss", "05",
+	).Replace(format)
+	if strings.ContainsAny(layout, "yMdHms") {
+		return "", false
+	}
+	return layout, true
+}
+
+func (p indexPattern) resolve(entry *logrus.Entry, lookup func(string) (string, bool)) string {
+	var b strings.Builder
+	for _, part := range p {
+		switch {
+		case part.layout != "":
+			b.WriteString(entry.Time.UTC().Format(part.layout))
+		case part.name != "":
+			v, ok := lookup(part.name)
+			if !ok {
+				if f, found := entry.Data[part.name]; found && f != nil {
+					v = fmt.Sprint(f)
+				}
+			}
+			if v == "" {
+				v = "unknown"
+			}
+			b.WriteString(sanitizeIndexName(v))
+		default:
+			b.WriteString(part.literal)
+		}
+	}
+	return b.String()
+}
+
+// sanitizeIndexName lowercases s and replaces
+// characters not allowed in index names
+func sanitizeIndexName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '\\', '/', '*', '?', '"', '<', '>', '|', ' ', ',', '#', ':':
+			return '_'
+		}
+		return r
+	}, strings.ToLower(s))
+}
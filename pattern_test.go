@@ -0,0 +1,52 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestIndexPattern(t *testing.T) {
+	hook := newHook(nil, "web-1", logrus.DebugLevel, "logs",
+		WithIndexPattern("logs-{service}-{env}-{host}-{yyyy.MM.dd}", map[string]string{"service": "Billing"}),
+	)
+	if hook.err != nil {
+		t.Fatal(hook.err)
+	}
+	entry := &logrus.Entry{
+		Time: time.Date(2024, 5, 17, 23, 30, 0, 0, time.FixedZone("CEST", 2*3600)),
+		Data: logrus.Fields{"env": "prod"},
+	}
+	if index := hook.indexFor(entry); index != "logs-billing-prod-web-1-2024.05.17" {
+		t.Errorf("unexpected index %s", index)
+	}
+
+	entry.Data = logrus.Fields{}
+	if index := hook.indexFor(entry); index != "logs-billing-unknown-web-1-2024.05.17" {
+		t.Errorf("unexpected index for missing field %s", index)
+	}
+}
+
+func TestIndexPatternHourly(t *testing.T) {
+	p, err := parseIndexPattern("app-{yyyy.MM.dd.HH}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &logrus.Entry{Time: time.Date(2024, 5, 17, 8, 0, 0, 0, time.UTC)}
+	if index := p.resolve(entry, func(string) (string, bool) { return "", false }); index != "app-2024.05.17.08" {
+		t.Errorf("unexpected index %s", index)
+	}
+}
+
+func TestIndexPatternInvalid(t *testing.T) {
+	for _, pattern := range []string{"logs-{service", "logs-}", "logs-{}"} {
+		if _, err := parseIndexPattern(pattern); err == nil {
+			t.Errorf("expected error for %q", pattern)
+		}
+	}
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "logs", WithIndexPattern("logs-{", nil))
+	if err := hook.Fire(&logrus.Entry{}); err == nil {
+		t.Error("invalid pattern should be reported")
+	}
+}
@@ -0,0 +1,146 @@
+package elogrus
+
+import (
+	"regexp"
+	"sync/atomic"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// PIIConfig selects the patterns masked
+// in messages and string field values
+type PIIConfig struct {
+	Emails bool
+	// CreditCards masks card numbers
+	// passing the Luhn check
+	CreditCards bool
+	// NationalIDs masks US social
+	// security numbers
+	NationalIDs bool
+	// Mask replaces a match,
+	// default "[REDACTED]"
+	Mask string
+}
+
+// PIICounts holds the number
+// of masked values per pattern
+type PIICounts struct {
+	Emails      int64
+	CreditCards int64
+	NationalIDs int64
+}
+
+var (
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	cardPattern       = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	nationalIDPattern = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+)
+
+// WithPIIMasking masks personal data detected
+// in the message and string fields before the
+// document is built, see PIIMasked
+func WithPIIMasking(config PIIConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Mask == "" {
+			config.Mask = "[REDACTED]"
+		}
+		hook.pii = &piiScanner{config: config}
+	}
+}
+
+// PIIMasked returns the number of
+// values masked so far per pattern
+func (hook *ElasticHook) PIIMasked() PIICounts {
+	if hook.pii == nil {
+		return PIICounts{}
+	}
+	c := &hook.pii.counts
+	return PIICounts{
+		Emails:      atomic.LoadInt64(&c.Emails),
+		CreditCards: atomic.LoadInt64(&c.CreditCards),
+		NationalIDs: atomic.LoadInt64(&c.NationalIDs),
+	}
+}
+
+type piiScanner struct {
+	// counts is first to be 64-bit
+	// aligned for atomic access
+	counts PIICounts
+	config PIIConfig
+}
+
+// mask returns s with the
+// enabled patterns masked
+func (p *piiScanner) mask(s string) string {
+	if p.config.Emails {
+		s = p.replace(s, emailPattern, nil, &p.counts.Emails)
+	}
+	if p.config.NationalIDs {
+		s = p.replace(s, nationalIDPattern, nil, &p.counts.NationalIDs)
+	}
+	if p.config.CreditCards {
+		s = p.replace(s, cardPattern, luhn, &p.counts.CreditCards)
+	}
+	return s
+}
+
+func (p *piiScanner) replace(s string, re *regexp.Regexp, valid func(string) bool, count *int64) string {
+	return re.ReplaceAllStringFunc(s, func(match string) string {
+		if valid != nil && !valid(match) {
+			return match
+		}
+		atomic.AddInt64(count, 1)
+		return p.config.Mask
+	})
+}
+
+// maskFields returns a copy of data with
+// its string and error values masked
+func (p *piiScanner) maskFields(data logrus.Fields) logrus.Fields {
+	masked := make(logrus.Fields, len(data))
+	for k, v := range data {
+		switch v := v.(type) {
+		case string:
+			masked[k] = p.mask(v)
+		case error:
+			masked[k] = p.mask(v.Error())
+		default:
+			masked[k] = v
+		}
+	}
+	return masked
+}
+
+// maskEntry returns a copy of entry
+// with its message and data masked
+func (hook *ElasticHook) maskEntry(entry *logrus.Entry) *logrus.Entry {
+	if hook.pii == nil {
+		return entry
+	}
+	masked := *entry
+	masked.Message = hook.pii.mask(entry.Message)
+	masked.Data = hook.pii.maskFields(entry.Data)
+	return &masked
+}
+
+// luhn reports whether the digits
+// of s pass the Luhn checksum
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
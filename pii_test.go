@@ -0,0 +1,38 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestPIIMasking(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithPIIMasking(PIIConfig{Emails: true, CreditCards: true}))
+	entry := &logrus.Entry{
+		Message: "order by joe@example.com paid with 4111 1111 1111 1111, ssn 078-05-1120",
+		Data: logrus.Fields{
+			"card":  "4111-1111-1111-1112",
+			"email": "jane@example.org",
+			"count": 3,
+		},
+	}
+	doc := hook.documents(entry)[0]
+
+	if msg := doc["Message"]; msg != "order by [REDACTED] paid with [REDACTED], ssn 078-05-1120" {
+		t.Errorf("unexpected message %q", msg)
+	}
+	data := doc["Data"].(logrus.Fields)
+	if data["email"] != "[REDACTED]" || data["count"] != 3 {
+		t.Errorf("unexpected data %v", data)
+	}
+	if data["card"] != "4111-1111-1111-1112" {
+		t.Error("numbers failing the Luhn check must be kept")
+	}
+	if entry.Data["email"] != "jane@example.org" {
+		t.Error("the entry must not be changed")
+	}
+	if c := hook.PIIMasked(); c.Emails != 2 || c.CreditCards != 1 || c.NationalIDs != 0 {
+		t.Errorf("unexpected counts %+v", c)
+	}
+}
@@ -0,0 +1,66 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// WithPipeline sends every document through the
+// named ingest pipeline (ElasticSearch 5+), e.g.
+// for geoip or fingerprint enrichment, both when
+// indexed one by one and in bulk
+func WithPipeline(name string) Option {
+	return func(hook *ElasticHook) {
+		hook.pipeline = name
+	}
+}
+
+// indexPipelined indexes body through the pipeline,
+// elastic.v3 predates ingest pipelines so the
+// request is made directly
+func (hook *ElasticHook) indexPipelined(ctx context.Context, client *elastic.Client, index, routing, pipeline string, body []byte, id string, create bool) error {
+	path := "/" + url.PathEscape(index) + "/" + url.PathEscape(hook.docType())
+	method := "POST"
+	params := url.Values{"pipeline": {pipeline}}
+	if id != "" {
+		path += "/" + url.PathEscape(id)
+		method = "PUT"
+	}
+	if create {
+		params.Set("op_type", "create")
+	}
+	if routing != "" {
+		params.Set("routing", routing)
+	}
+	_, err := client.PerformRequestC(ctx, method, path, params, json.RawMessage(body))
+	return err
+}
+
+// pipelineRequest adds the pipeline
+// to the action of a bulk request
+type pipelineRequest struct {
+	elastic.BulkableRequest
+	pipeline string
+}
+
+func (r pipelineRequest) Source() ([]string, error) {
+	lines, err := r.BulkableRequest.Source()
+	if err != nil || len(lines) == 0 {
+		return lines, err
+	}
+	var action map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &action); err != nil {
+		return nil, err
+	}
+	for _, meta := range action {
+		meta["pipeline"] = r.pipeline
+	}
+	first, err := json.Marshal(action)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{string(first)}, lines[1:]...), nil
+}
@@ -0,0 +1,47 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type rawBulkRequest []string
+
+func (r rawBulkRequest) String() string            { return "" }
+func (r rawBulkRequest) Source() ([]string, error) { return r, nil }
+
+func TestPipelineRequest(t *testing.T) {
+	req := pipelineRequest{rawBulkRequest{`{"index":{"_index":"logs","_type":"log"}}`, `{"Message":"hi"}`}, "geoip"}
+	lines, err := req.Source()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var action map[string]map[string]string
+	json.Unmarshal([]byte(lines[0]), &action)
+	if action["index"]["pipeline"] != "geoip" || action["index"]["_index"] != "logs" {
+		t.Errorf("unexpected action %s", lines[0])
+	}
+	if lines[1] != `{"Message":"hi"}` {
+		t.Errorf("the document must be kept, got %s", lines[1])
+	}
+}
+
+func TestBulkItemsCarryPipeline(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}), WithPipeline("geoip"))
+	hook.Fire(&logrus.Entry{Message: "hi", Data: logrus.Fields{}})
+	items := hook.bulk.take()
+	if len(items) != 1 {
+		t.Fatalf("expected one pending document, got %d", len(items))
+	}
+	req, _, err := items[0].request(hook.docType(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := req.(pipelineRequest); !ok || r.pipeline != "geoip" {
+		t.Errorf("expected a pipeline request, got %T", req)
+	}
+}
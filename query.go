@@ -0,0 +1,101 @@
+package elogrus
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// MaxStatementLength bounds the length
+// of db.statement in QueryFields
+var MaxStatementLength = 1024
+
+// QueryFields returns the standard fields of a database
+// operation: the sanitized statement, the number of rows
+// affected and the duration in nanoseconds
+func QueryFields(statement string, rowsAffected int64, duration time.Duration) logrus.Fields {
+	return logrus.Fields{
+		"db.statement":     truncate(SanitizeStatement(statement), MaxStatementLength),
+		"db.rows_affected": rowsAffected,
+		"event.duration":   duration.Nanoseconds(),
+	}
+}
+
+// LogQuery logs a database operation with QueryFields
+// through entry, at error level when err is set
+func LogQuery(entry *logrus.Entry, statement string, rowsAffected int64, duration time.Duration, err error) {
+	entry = entry.WithFields(QueryFields(statement, rowsAffected, duration))
+	if err != nil {
+		entry.WithError(err).Error("db query failed")
+		return
+	}
+	entry.Info("db query")
+}
+
+// SanitizeStatement replaces string and numeric
+// literals with ? and collapses whitespace, so
+// statements group by shape and carry no values
+func SanitizeStatement(statement string) string {
+	var b strings.Builder
+	runes := []rune(statement)
+	space := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if unicode.IsSpace(r) {
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		switch {
+		case r == '\'':
+			// skip to the closing quote, '' is an escaped quote
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteByte('?')
+		case unicode.IsDigit(r) && !isIdentRune(prevRune(runes, i)):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func prevRune(runes []rune, i int) rune {
+	if i == 0 {
+		return ' '
+	}
+	return runes[i-1]
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// truncate cuts s to at most max bytes
+// without splitting a character
+func truncate(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -0,0 +1,41 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSanitizeStatement(t *testing.T) {
+	for in, want := range map[string]string{
+		"SELECT * FROM users WHERE id = 42":                      "SELECT * FROM users WHERE id = ?",
+		"select *\n  from t1\twhere name = 'O''Brien' and x=3.5": "select * from t1 where name = ? and x=?",
+		"  UPDATE t SET a = $1  ":                                "UPDATE t SET a = $1",
+	} {
+		if got := SanitizeStatement(in); got != want {
+			t.Errorf("SanitizeStatement(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestQueryFields(t *testing.T) {
+	old := MaxStatementLength
+	MaxStatementLength = 10
+	defer func() { MaxStatementLength = old }()
+
+	fields := QueryFields("SELECT name FROM users", 3, 2*time.Millisecond)
+	if fields["db.statement"] != "SELECT nam" {
+		t.Errorf("expected truncated statement, got %q", fields["db.statement"])
+	}
+	if fields["db.rows_affected"] != int64(3) {
+		t.Errorf("unexpected rows affected %v", fields["db.rows_affected"])
+	}
+	if fields["event.duration"] != int64(2000000) {
+		t.Errorf("expected duration in nanoseconds, got %v", fields["event.duration"])
+	}
+}
+
+func TestTruncateKeepsRunes(t *testing.T) {
+	if got := truncate("héllo", 2); got != "h" {
+		t.Errorf("expected rune boundary cut, got %q", got)
+	}
+}
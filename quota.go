@@ -0,0 +1,131 @@
+package elogrus
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// QuotaConfig limits the documents
+// per second shipped for each tenant
+type QuotaConfig struct {
+	// Rate is the number of documents
+	// per second allowed per tenant
+	Rate float64
+	// Burst is the number of documents a tenant
+	// may send at once, default is Rate
+	Burst int
+	// Key returns the tenant of an entry,
+	// default is the routed index
+	Key func(*logrus.Entry) string
+}
+
+// WithTenantQuota drops entries of tenants exceeding
+// their quota, so one noisy tenant cannot starve the
+// others. Dropped entries are counted per tenant,
+// see QuotaDropped. Tenants idle until their
+// quota is full again are forgotten.
+func WithTenantQuota(config QuotaConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Burst <= 0 {
+			config.Burst = int(config.Rate)
+		}
+		hook.quota = &quota{
+			config:  config,
+			buckets: map[string]*tokenBucket{},
+			dropped: map[string]int64{},
+			now:     time.Now,
+		}
+	}
+}
+
+// QuotaDropped returns the number of entries
+// dropped per tenant for exceeding the quota
+func (hook *ElasticHook) QuotaDropped() map[string]int64 {
+	if hook.quota == nil {
+		return nil
+	}
+	return hook.quota.snapshot()
+}
+
+type quota struct {
+	mu      sync.Mutex
+	config  QuotaConfig
+	buckets map[string]*tokenBucket
+	dropped map[string]int64
+	now     func() time.Time
+	// swept is when idle
+	// buckets were evicted
+	swept time.Time
+}
+
+// allow reports whether the
+// tenant is within its quota
+func (q *quota) allow(tenant string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	q.evict(now)
+	b, ok := q.buckets[tenant]
+	if !ok {
+		b = newTokenBucket(q.config.Rate, q.config.Burst, now)
+		q.buckets[tenant] = b
+	}
+	if b.take(now) {
+		return true
+	}
+	q.dropped[tenant]++
+	return false
+}
+
+// evict drops the buckets idle long enough to be
+// full again, as a new one is, so tenants seen
+// once do not accumulate; q.mu must be held
+func (q *quota) evict(now time.Time) {
+	if q.config.Rate <= 0 {
+		return
+	}
+	burst := math.Max(float64(q.config.Burst), 1)
+	idle := time.Duration(burst / q.config.Rate * float64(time.Second))
+	if idle < time.Minute {
+		idle = time.Minute
+	}
+	if now.Sub(q.swept) < idle {
+		return
+	}
+	q.swept = now
+	for tenant, b := range q.buckets {
+		if now.Sub(b.last) >= idle {
+			delete(q.buckets, tenant)
+		}
+	}
+}
+
+func (q *quota) snapshot() map[string]int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dropped := make(map[string]int64, len(q.dropped))
+	for k, v := range q.dropped {
+		dropped[k] = v
+	}
+	return dropped
+}
+
+// withinQuota reports whether
+// entry may be shipped
+func (hook *ElasticHook) withinQuota(entry *logrus.Entry) bool {
+	if hook.quota == nil {
+		return true
+	}
+	tenant := ""
+	if hook.quota.config.Key != nil {
+		tenant = hook.quota.config.Key(entry)
+	} else {
+		tenant = hook.indexFor(entry)
+	}
+	return hook.quota.allow(tenant)
+}
@@ -0,0 +1,60 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestTenantQuota(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "app",
+		WithIndexRouter(func(entry *logrus.Entry) string {
+			return "app-" + entry.Data["tenant"].(string)
+		}),
+		WithTenantQuota(QuotaConfig{Rate: 2}),
+	)
+	now := time.Now()
+	hook.quota.now = func() time.Time { return now }
+
+	noisy := &logrus.Entry{Data: logrus.Fields{"tenant": "noisy"}}
+	quiet := &logrus.Entry{Data: logrus.Fields{"tenant": "quiet"}}
+	for i := 0; i < 5; i++ {
+		hook.withinQuota(noisy)
+	}
+	if !hook.withinQuota(quiet) {
+		t.Error("a noisy tenant must not use up the quota of others")
+	}
+
+	dropped := hook.QuotaDropped()
+	if dropped["app-noisy"] != 3 {
+		t.Errorf("expected 3 dropped entries for the noisy tenant, got %v", dropped)
+	}
+
+	now = now.Add(time.Second)
+	if !hook.withinQuota(noisy) {
+		t.Error("quota should refill over time")
+	}
+}
+
+func TestTenantQuotaEviction(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "app",
+		WithTenantQuota(QuotaConfig{Rate: 1, Burst: 1, Key: func(entry *logrus.Entry) string {
+			return entry.Data["tenant"].(string)
+		}}),
+	)
+	now := time.Now()
+	hook.quota.now = func() time.Time { return now }
+
+	hook.withinQuota(&logrus.Entry{Data: logrus.Fields{"tenant": "once"}})
+	hook.withinQuota(&logrus.Entry{Data: logrus.Fields{"tenant": "busy"}})
+	now = now.Add(30 * time.Second)
+	hook.withinQuota(&logrus.Entry{Data: logrus.Fields{"tenant": "busy"}})
+	now = now.Add(45 * time.Second)
+	if hook.withinQuota(&logrus.Entry{Data: logrus.Fields{"tenant": "other"}}); len(hook.quota.buckets) != 2 {
+		t.Errorf("expected the idle tenant evicted, got %d buckets", len(hook.quota.buckets))
+	}
+	if _, ok := hook.quota.buckets["once"]; ok {
+		t.Error("expected the idle tenant evicted")
+	}
+}
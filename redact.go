@@ -0,0 +1,82 @@
+package elogrus
+
+import (
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// FieldFilter is called for every field of an entry
+// before the document is built. It returns the value
+// to ship, or false to drop the field.
+type FieldFilter func(key string, value interface{}) (interface{}, bool)
+
+// WithFieldFilter applies filter to the entry
+// data, e.g. to strip secrets; filters run in
+// the order they are given
+func WithFieldFilter(filter FieldFilter) Option {
+	return func(hook *ElasticHook) {
+		hook.fieldFilters = append(hook.fieldFilters, filter)
+	}
+}
+
+// RedactConfig names fields never shipped,
+// matched case-insensitively
+type RedactConfig struct {
+	// Drop lists fields removed from documents
+	Drop []string
+	// Mask lists fields whose value is replaced
+	Mask []string
+	// Replacement is the masked
+	// value, default "[REDACTED]"
+	Replacement string
+}
+
+// WithRedaction drops or masks the configured
+// fields, e.g. tokens and passwords, see
+// WithFieldFilter and WithPIIMasking
+func WithRedaction(config RedactConfig) Option {
+	if config.Replacement == "" {
+		config.Replacement = "[REDACTED]"
+	}
+	names := map[string]bool{}
+	for _, name := range config.Drop {
+		names[strings.ToLower(name)] = false
+	}
+	for _, name := range config.Mask {
+		names[strings.ToLower(name)] = true
+	}
+	return WithFieldFilter(func(key string, value interface{}) (interface{}, bool) {
+		mask, ok := names[strings.ToLower(key)]
+		switch {
+		case !ok:
+			return value, true
+		case mask:
+			return config.Replacement, true
+		}
+		return nil, false
+	})
+}
+
+// filterEntry returns a copy of entry
+// with its data passed through the filters
+func (hook *ElasticHook) filterEntry(entry *logrus.Entry) *logrus.Entry {
+	if len(hook.fieldFilters) == 0 {
+		return entry
+	}
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		keep := true
+		for _, filter := range hook.fieldFilters {
+			if v, keep = filter(k, v); !keep {
+				break
+			}
+		}
+		if keep {
+			data[k] = v
+		}
+	}
+	filtered := *entry
+	filtered.Data = data
+	return &filtered
+}
@@ -0,0 +1,36 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestRedaction(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithRedaction(RedactConfig{Drop: []string{"password"}, Mask: []string{"token"}}),
+		WithFieldFilter(func(key string, value interface{}) (interface{}, bool) {
+			if key == "email" {
+				return "someone@example.com", true
+			}
+			return value, true
+		}),
+	)
+	entry := &logrus.Entry{Data: logrus.Fields{
+		"Password": "hunter2",
+		"token":    "abc123",
+		"email":    "jane@corp.com",
+		"user_id":  42,
+	}}
+	data := hook.documents(entry)[0]["Data"].(logrus.Fields)
+
+	if _, ok := data["Password"]; ok {
+		t.Error("expected the password to be dropped")
+	}
+	if data["token"] != "[REDACTED]" || data["email"] != "someone@example.com" || data["user_id"] != 42 {
+		t.Errorf("unexpected data %v", data)
+	}
+	if entry.Data["token"] != "abc123" {
+		t.Error("the entry data must not be changed")
+	}
+}
@@ -0,0 +1,113 @@
+package elogrus
+
+import (
+	"sync"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+var registry = struct {
+	sync.Mutex
+	clients map[string]*sharedClient
+	// retired holds the clients lazy hooks dropped
+	// after a failure, until their users release them
+	retired []*sharedClient
+}{clients: map[string]*sharedClient{}}
+
+type sharedClient struct {
+	url    string
+	client *elastic.Client
+	refs   int
+}
+
+// SharedClient returns the client for the cluster at
+// url, creating it with options on the first call, so
+// hooks in one process share a connection pool. The
+// options of later calls are ignored. Call
+// ReleaseClient when a user is done with it.
+func SharedClient(url string, options ...elastic.ClientOptionFunc) (*elastic.Client, error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if shared, ok := registry.clients[url]; ok {
+		shared.refs++
+		return shared.client, nil
+	}
+	options = append([]elastic.ClientOptionFunc{elastic.SetURL(url)}, options...)
+	client, err := elastic.NewClient(options...)
+	if err != nil {
+		return nil, err
+	}
+	registry.clients[url] = &sharedClient{url: url, client: client, refs: 1}
+	return client, nil
+}
+
+// SharedClientFunc returns a ClientFunc for lazy
+// hooks which uses SharedClient
+func SharedClientFunc(url string, options ...elastic.ClientOptionFunc) ClientFunc {
+	return func() (*elastic.Client, error) {
+		return SharedClient(url, options...)
+	}
+}
+
+// ReleaseClient gives back a client obtained from
+// SharedClient, the client is stopped when the last
+// user released it. Clients a lazy hook dropped after
+// a failure are released before the current one.
+func ReleaseClient(url string) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, shared := range registry.retired {
+		if shared.url == url {
+			release(shared)
+			return
+		}
+	}
+	if shared, ok := registry.clients[url]; ok {
+		release(shared)
+	}
+}
+
+// releaseShared releases client for a lazy hook
+// dropping it, so SharedClient creates another;
+// it reports false for clients not shared
+func releaseShared(client *elastic.Client) bool {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, shared := range registry.retired {
+		if shared.client == client {
+			release(shared)
+			return true
+		}
+	}
+	for url, shared := range registry.clients {
+		if shared.client == client {
+			delete(registry.clients, url)
+			registry.retired = append(registry.retired, shared)
+			release(shared)
+			return true
+		}
+	}
+	return false
+}
+
+// release drops a reference to shared, stopping
+// the client after the last, registry must be held
+func release(shared *sharedClient) {
+	shared.refs--
+	if shared.refs > 0 {
+		return
+	}
+	if registry.clients[shared.url] == shared {
+		delete(registry.clients, shared.url)
+	}
+	for i, retired := range registry.retired {
+		if retired == shared {
+			registry.retired = append(registry.retired[:i], registry.retired[i+1:]...)
+			break
+		}
+	}
+	shared.client.Stop()
+}
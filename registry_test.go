@@ -0,0 +1,93 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// share registers client for url
+// as if SharedClient returned it refs times
+func share(t *testing.T, url string, client *elastic.Client, refs int) {
+	registry.Lock()
+	registry.clients[url] = &sharedClient{url: url, client: client, refs: refs}
+	registry.Unlock()
+	t.Cleanup(func() {
+		registry.Lock()
+		delete(registry.clients, url)
+		registry.retired = nil
+		registry.Unlock()
+	})
+}
+
+func TestSharedClient(t *testing.T) {
+	client := &elastic.Client{}
+	share(t, "http://shared:9200", client, 1)
+	got, err := SharedClient("http://shared:9200")
+	if err != nil || got != client {
+		t.Fatalf("expected the registered client, got %p: %v", got, err)
+	}
+	ReleaseClient("http://shared:9200")
+	if registry.clients["http://shared:9200"] == nil {
+		t.Fatal("expected the client kept for its other user")
+	}
+	ReleaseClient("http://shared:9200")
+	if registry.clients["http://shared:9200"] != nil {
+		t.Error("expected the client dropped after the last release")
+	}
+	ReleaseClient("http://shared:9200")
+}
+
+func TestLazySharedClientReleased(t *testing.T) {
+	client := &elastic.Client{}
+	// the lazy hook and another user
+	share(t, "http://shared:9200", client, 2)
+	hook := NewLazyElasticHook(SharedClientFunc("http://shared:9200"), "localhost", logrus.DebugLevel, "test")
+	defer hook.Close()
+	hook.client = client
+
+	hook.checkClient(client, elastic.ErrNoClient)
+	hook.checkClient(client, elastic.ErrNoClient)
+	if hook.client != nil {
+		t.Error("expected the hook to drop the client")
+	}
+	if registry.clients["http://shared:9200"] != nil {
+		t.Error("expected the next SharedClient call to create a client")
+	}
+	if len(registry.retired) != 1 || registry.retired[0].refs != 1 {
+		t.Fatalf("expected the client kept for its other user, got %+v", registry.retired)
+	}
+	ReleaseClient("http://shared:9200")
+	if len(registry.retired) != 0 {
+		t.Error("expected the client stopped after the last release")
+	}
+}
+
+func TestLazyClientStopped(t *testing.T) {
+	client := &elastic.Client{}
+	hook := NewLazyElasticHook(func() (*elastic.Client, error) { return client, nil }, "localhost", logrus.DebugLevel, "test")
+	defer hook.Close()
+	hook.client = client
+	hook.checkClient(client, elastic.ErrRetry)
+	if hook.client != nil || releaseShared(client) {
+		t.Error("expected the client dropped without the registry")
+	}
+}
+
+func TestLazyBootstrapReleasesClient(t *testing.T) {
+	client := &elastic.Client{}
+	share(t, "http://shared:9200", client, 1)
+	hook := NewLazyElasticHook(SharedClientFunc("http://shared:9200"), "localhost", logrus.DebugLevel, "test", WithLazyBootstrap())
+	defer hook.Close()
+
+	if _, err := hook.getClient(); err == nil {
+		t.Fatal("expected the bootstrap to fail")
+	}
+	if registry.clients["http://shared:9200"] != nil {
+		t.Error("expected the client released after the failed bootstrap")
+	}
+	if len(registry.retired) != 1 || registry.retired[0].refs != 1 {
+		t.Fatalf("expected the client kept for its other user, got %+v", registry.retired)
+	}
+}
@@ -0,0 +1,39 @@
+package elogrus
+
+import "net/http"
+
+// RequestIDHeader is the header carrying
+// the request ID read and set by
+// RequestIDMiddleware
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware gives every request an ID, taken
+// from the X-Request-ID header or generated as a ULID,
+// echoes it in the response and stores it in the
+// request context. Entries logged with that context,
+// e.g. logger.WithContext(r.Context()), carry it in
+// the field set by WithCorrelationID.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = NewULID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ContextWithCorrelationID(r.Context(), id)))
+	})
+}
+
+// validRequestID accepts short printable IDs,
+// so clients cannot inject arbitrary values
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
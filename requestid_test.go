@@ -0,0 +1,47 @@
+package elogrus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestRequestIDEndToEnd(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithCorrelationID("request_id"))
+	logger := logrus.New()
+
+	var docs []map[string]interface{}
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entry := logger.WithContext(r.Context())
+		entry.Message = "handled"
+		docs = append(docs, hook.document(entry))
+	}))
+
+	for _, c := range []struct {
+		header    string
+		generated bool
+	}{
+		{"req-42", false},
+		{"", true},
+		{"bad id\n" + strings.Repeat("x", 10), true},
+	} {
+		docs = nil
+		req := httptest.NewRequest("GET", "/", nil)
+		if c.header != "" {
+			req.Header.Set(RequestIDHeader, c.header)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		id := w.Header().Get(RequestIDHeader)
+		if len(docs) != 1 || docs[0]["request_id"] != id {
+			t.Errorf("%q: expected documents to carry the response ID %q, got %v", c.header, id, docs)
+		}
+		if generated := id != c.header; generated != c.generated {
+			t.Errorf("%q: unexpected ID %q", c.header, id)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package elogrus
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+)
+
+var (
+	// Fired if an entry is not shipped
+	// because of quotas or reentrancy
+	ErrDropped = fmt.Errorf("Entry dropped")
+)
+
+// FireWithResult ships entry like Fire and returns
+// a channel receiving nil once ElasticSearch
+// acknowledged it, or the error which made
+// delivery fail. For code paths which must
+// confirm persistence, e.g. audit logs:
+//
+//	err := <-hook.FireWithResult(&logrus.Entry{
+//		Data:    logrus.Fields{"user": id},
+//		Time:    time.Now(),
+//		Level:   logrus.InfoLevel,
+//		Message: "permissions changed",
+//	})
+//
+// In bulk mode the result arrives with the batch,
+// Flush to send it without waiting.
+func (hook *ElasticHook) FireWithResult(entry *logrus.Entry) <-chan error {
+	result := make(chan error, 1)
+	var once sync.Once
+	hook.fire(entry, func(err error) {
+		once.Do(func() {
+			result <- err
+		})
+	})
+	return result
+}
+
+// resolve calls done
+// unless it is nil
+func resolve(done func(error), err error) {
+	if done != nil {
+		done(err)
+	}
+}
+
+// resolveAll returns a func which calls done
+// with the first error after n calls
+func resolveAll(n int, done func(error)) func(error) {
+	if done == nil || n <= 1 {
+		return done
+	}
+	var mu sync.Mutex
+	var first error
+	return func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil && first == nil {
+			first = err
+		}
+		n--
+		if n == 0 {
+			done(first)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package elogrus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestResolveAll(t *testing.T) {
+	var results []error
+	done := resolveAll(3, func(err error) { results = append(results, err) })
+	failed := errors.New("rejected")
+	done(nil)
+	done(failed)
+	if len(results) != 0 {
+		t.Fatal("resolved before all documents finished")
+	}
+	done(nil)
+	if len(results) != 1 || results[0] != failed {
+		t.Errorf("expected the first error once, got %v", results)
+	}
+}
+
+func TestFireWithResultDropped(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test")
+	entry := &logrus.Entry{Data: logrus.Fields{InternalField: true}}
+	if err := <-hook.FireWithResult(entry); err != ErrDropped {
+		t.Errorf("expected ErrDropped, got %v", err)
+	}
+}
+
+func TestFireWithResultShutdown(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithMaxInFlight(1),
+	)
+	hook.inFlight <- struct{}{}
+	result := hook.FireWithResult(&logrus.Entry{Data: logrus.Fields{}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	hook.Shutdown(ctx)
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Error("expected the aborted entry to fail")
+		}
+	default:
+		t.Error("result not resolved by Shutdown")
+	}
+}
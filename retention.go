@@ -0,0 +1,181 @@
+package elogrus
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+var (
+	// Fired if index retention is set
+	// up without an index pattern
+	ErrRetentionPattern = fmt.Errorf("Index retention needs a dated index pattern")
+)
+
+// RetentionAction is what is
+// done with expired indices
+type RetentionAction int
+
+const (
+	// CloseIndices closes expired indices
+	CloseIndices RetentionAction = iota
+	// FreezeIndices freezes expired indices,
+	// it needs ElasticSearch 6.6 or later
+	FreezeIndices
+)
+
+// RetentionConfig configures
+// the retirement of old indices
+type RetentionConfig struct {
+	// Days is the age after which an index is
+	// retired, counted from the date in its
+	// name, default 30
+	Days   int
+	Action RetentionAction
+}
+
+// WithIndexRetention closes or freezes indices of
+// the WithIndexPattern pattern once their date is
+// more than Days days old, checked at start-up and
+// after every midnight (UTC). For clusters where
+// no curator runs; it needs a pattern with a date.
+func WithIndexRetention(config RetentionConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Days <= 0 {
+			config.Days = 30
+		}
+		hook.retention = &retention{RetentionConfig: config, retired: map[string]bool{}}
+	}
+}
+
+type retention struct {
+	RetentionConfig
+	// prefix is the literal start of the
+	// pattern, expr matches its indices
+	prefix  string
+	expr    *regexp.Regexp
+	layout  string
+	retired map[string]bool
+}
+
+// setup prepares the matching of
+// index names to the pattern p
+func (r *retention) setup(p indexPattern) error {
+	var expr strings.Builder
+	var layouts []string
+	prefix := true
+	expr.WriteString("^")
+	for _, part := range p {
+		switch {
+		case part.layout != "":
+			expr.WriteString("(" + layoutExpr(part.layout) + ")")
+			layouts = append(layouts, part.layout)
+		case part.name != "":
+			expr.WriteString(".+?")
+		default:
+			expr.WriteString(regexp.QuoteMeta(part.literal))
+			if prefix {
+				r.prefix += part.literal
+			}
+		}
+		prefix = prefix && part.layout == "" && part.name == ""
+	}
+	expr.WriteString("$")
+	if len(layouts) == 0 {
+		return ErrRetentionPattern
+	}
+	r.expr = regexp.MustCompile(expr.String())
+	r.layout = strings.Join(layouts, " ")
+	return nil
+}
+
+// layoutExpr returns a regular expression
+// matching times formatted with layout
+func layoutExpr(layout string) string {
+	var b strings.Builder
+	for layout != "" {
+		switch {
+		case strings.HasPrefix(layout, "2006"):
+			b.WriteString(`\d{4}`)
+			layout = layout[4:]
+		case layout[0] >= '0' && layout[0] <= '9':
+			b.WriteString(`\d{2}`)
+			layout = layout[2:]
+		default:
+			b.WriteString(regexp.QuoteMeta(layout[:1]))
+			layout = layout[1:]
+		}
+	}
+	return b.String()
+}
+
+// expired reports whether the date of index
+// is more than Days days before the day of now
+func (r *retention) expired(index string, now time.Time) bool {
+	m := r.expr.FindStringSubmatch(index)
+	if m == nil {
+		return false
+	}
+	date, err := time.Parse(r.layout, strings.Join(m[1:], " "))
+	if err != nil {
+		return false
+	}
+	y, mo, d := now.UTC().Date()
+	cutoff := time.Date(y, mo, d-r.Days, 0, 0, 0, 0, time.UTC)
+	return date.Before(cutoff)
+}
+
+// runRetention retires expired indices
+// at start-up and after every midnight
+func (hook *ElasticHook) runRetention() {
+	for {
+		if err := hook.retire(time.Now()); err != nil {
+			hook.reportError(err)
+		}
+		timer := time.NewTimer(time.Until(nextMidnight(time.Now())))
+		select {
+		case <-timer.C:
+		case <-hook.quit:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// retire closes or freezes the
+// indices expired at now
+func (hook *ElasticHook) retire(now time.Time) error {
+	r := hook.retention
+	return hook.maintain(func(client *elastic.Client) error {
+		ctx, cancel := hook.controlContext()
+		defer cancel()
+		aliases, err := client.Aliases().Index(r.prefix + "*").DoC(ctx)
+		if err != nil {
+			return err
+		}
+		for index := range aliases.Indices {
+			if r.retired[index] || index == hook.index || !r.expired(index, now) {
+				continue
+			}
+			if err := hook.retireIndex(ctx, client, index); err != nil {
+				return err
+			}
+			r.retired[index] = true
+			hook.clusterEvent(ClusterEvent{Kind: IndexRetired, Index: index})
+		}
+		return nil
+	})
+}
+
+func (hook *ElasticHook) retireIndex(ctx context.Context, client *elastic.Client, index string) error {
+	if hook.retention.Action == FreezeIndices {
+		_, err := client.PerformRequestC(ctx, "POST", "/"+index+"/_freeze", nil, nil)
+		return err
+	}
+	_, err := client.CloseIndex(index).DoC(ctx)
+	return err
+}
@@ -0,0 +1,42 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionExpired(t *testing.T) {
+	p, err := parseIndexPattern("logs-{service}-{yyyy.MM.dd}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &retention{RetentionConfig: RetentionConfig{Days: 7}}
+	if err := r.setup(p); err != nil {
+		t.Fatal(err)
+	}
+	if r.prefix != "logs-" {
+		t.Errorf("unexpected prefix %q", r.prefix)
+	}
+
+	now := time.Date(2018, 1, 10, 15, 0, 0, 0, time.UTC)
+	for index, expected := range map[string]bool{
+		"logs-billing-2018.01.02":    true,
+		"logs-my-service-2017.12.01": true,
+		"logs-billing-2018.01.03":    false,
+		"logs-billing-2018.01.10":    false,
+		"logs-billing":               false,
+		"other-billing-2017.01.01":   false,
+	} {
+		if r.expired(index, now) != expected {
+			t.Errorf("expired(%q) should be %t", index, expected)
+		}
+	}
+}
+
+func TestRetentionNeedsDate(t *testing.T) {
+	p, _ := parseIndexPattern("logs-{service}")
+	r := &retention{}
+	if err := r.setup(p); err != ErrRetentionPattern {
+		t.Errorf("expected ErrRetentionPattern, got %v", err)
+	}
+}
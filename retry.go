@@ -0,0 +1,129 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// RetryConfig configures retries of transient
+// failures, zero values fall back to the defaults
+type RetryConfig struct {
+	// Attempts is the number of retries
+	// after the first try, default 3
+	Attempts int
+	// MinBackoff is the wait before the first
+	// retry, doubled for every further one,
+	// default 100 milliseconds
+	MinBackoff time.Duration
+	// MaxBackoff caps the wait,
+	// default 10 seconds
+	MaxBackoff time.Duration
+}
+
+// WithRetry retries documents failing with a transient
+// error (timeouts, connection errors, 429 and 5xx) with
+// exponential backoff and jitter, before they are given
+// to the fallbacks and the discard handler
+func WithRetry(config RetryConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Attempts <= 0 {
+			config.Attempts = 3
+		}
+		if config.MinBackoff <= 0 {
+			config.MinBackoff = 100 * time.Millisecond
+		}
+		if config.MaxBackoff <= 0 {
+			config.MaxBackoff = 10 * time.Second
+		}
+		if config.MaxBackoff < config.MinBackoff {
+			config.MaxBackoff = config.MinBackoff
+		}
+		hook.retry = &config
+	}
+}
+
+// WithOnDiscard calls fn with every document
+// which could not be delivered, once retries
+// are exhausted, e.g. to spool it elsewhere
+func WithOnDiscard(fn func(doc json.RawMessage, err error)) Option {
+	return func(hook *ElasticHook) {
+		hook.onDiscard = fn
+	}
+}
+
+// discard hands an undeliverable document
+// to the discard and error handlers
+func (hook *ElasticHook) discard(doc interface{}, err error) {
+	if hook.onDiscard == nil && hook.errorHandler == nil {
+		return
+	}
+	raw, merr := json.Marshal(doc)
+	if merr != nil {
+		hook.reportError(merr)
+		return
+	}
+	if hook.onDiscard != nil {
+		hook.onDiscard(raw, err)
+	}
+	if hook.errorHandler != nil {
+		hook.errorHandler(err, raw)
+	}
+}
+
+// backoff waits before retry attempt, counted
+// from 0, it reports false when no retry is left
+// or the hook shuts down
+func (hook *ElasticHook) backoff(attempt int, err error) bool {
+	return hook.backoffContext(hook.ctx, 0, attempt, err)
+}
+
+// backoffContext is backoff for the delivery
+// id, giving up too when ctx is done
+func (hook *ElasticHook) backoffContext(ctx context.Context, id uint64, attempt int, err error) bool {
+	r := hook.retry
+	if r == nil || attempt >= r.Attempts || !isTransient(err) {
+		return false
+	}
+	d := r.MaxBackoff
+	if attempt < 30 && r.MinBackoff<<uint(attempt) < d {
+		d = r.MinBackoff << uint(attempt)
+	}
+	// jitter between half and the full
+	// wait spreads retries of a fleet
+	d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		hook.retried(err)
+		hook.trace(id, "retried", err)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isTransient reports errors
+// worth retrying
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	switch e := err.(type) {
+	case *elastic.Error:
+		return isClusterStatus(e.Status)
+	case net.Error:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestIsTransient(t *testing.T) {
+	for _, c := range []struct {
+		err       error
+		transient bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, true},
+		{&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{&elastic.Error{Status: 429}, true},
+		{&elastic.Error{Status: 503}, true},
+		{&elastic.Error{Status: 400}, false},
+		{ErrBreakerOpen, false},
+	} {
+		if isTransient(c.err) != c.transient {
+			t.Errorf("%v: expected transient %v", c.err, c.transient)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithRetry(RetryConfig{Attempts: 2, MinBackoff: time.Millisecond}))
+	transient := &elastic.Error{Status: 503}
+
+	start := time.Now()
+	if !hook.backoff(0, transient) || !hook.backoff(1, transient) {
+		t.Fatal("expected two retries")
+	}
+	if time.Since(start) < 1500*time.Microsecond {
+		t.Error("expected the retries to wait")
+	}
+	if hook.backoff(2, transient) {
+		t.Error("expected the retries to be exhausted")
+	}
+	if hook.backoff(0, &elastic.Error{Status: 400}) {
+		t.Error("permanent errors must not be retried")
+	}
+	hook.cancel()
+	if hook.backoff(0, transient) {
+		t.Error("expected no retry once the hook shuts down")
+	}
+}
+
+func TestOnDiscard(t *testing.T) {
+	var discarded []string
+	var reason error
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithMaxInFlight(1),
+		WithRetry(RetryConfig{}),
+		WithOnDiscard(func(doc json.RawMessage, err error) {
+			discarded = append(discarded, string(doc))
+			reason = err
+		}),
+	)
+	hook.inFlight <- struct{}{}
+	hook.cancel()
+
+	err := hook.Fire(&logrus.Entry{Message: "lost", Data: logrus.Fields{}})
+	if len(discarded) != 1 || !strings.Contains(discarded[0], "lost") {
+		t.Fatalf("unexpected discarded documents %v", discarded)
+	}
+	if !errors.Is(err, context.Canceled) || reason != err {
+		t.Errorf("expected the delivery error, got %v and %v", err, reason)
+	}
+}
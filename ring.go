@@ -0,0 +1,134 @@
+package elogrus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+var (
+	// Fired if the spool ring file
+	// cannot be mapped on this system
+	ErrRingUnsupported = fmt.Errorf("Ring spool files need mmap")
+	// Fired if the spool is
+	// written after Close
+	ErrSpoolClosed = fmt.Errorf("Spool closed")
+)
+
+// ringMagic starts a ring file,
+// followed by head and used
+var ringMagic = []byte("ELGRING1")
+
+// ringHeader is the size of the
+// header before the ring data
+const ringHeader = 24
+
+// ring is a fixed size buffer of lines in
+// a memory mapped file. When it is full the
+// oldest lines are overwritten.
+type ring struct {
+	mem   []byte
+	unmap func() error
+	// data is mem after the header,
+	// head the offset of the oldest
+	// line and used the bytes held
+	data []byte
+	head int
+	used int
+	// pending is the bytes at head returned
+	// by peek and not yet acknowledged,
+	// overwritten those of them lost since
+	pending     int
+	overwritten int
+}
+
+// newRing uses mem as ring, keeping
+// the lines it already holds
+func newRing(mem []byte, unmap func() error) *ring {
+	r := &ring{mem: mem, unmap: unmap, data: mem[ringHeader:]}
+	head := int(binary.LittleEndian.Uint64(mem[8:]))
+	used := int(binary.LittleEndian.Uint64(mem[16:]))
+	if bytes.Equal(mem[:8], ringMagic) && head >= 0 && head < len(r.data) && used >= 0 && used <= len(r.data) {
+		r.head, r.used = head, used
+	}
+	copy(mem, ringMagic)
+	r.sync()
+	return r
+}
+
+// sync writes head and used
+// to the header
+func (r *ring) sync() {
+	binary.LittleEndian.PutUint64(r.mem[8:], uint64(r.head))
+	binary.LittleEndian.PutUint64(r.mem[16:], uint64(r.used))
+}
+
+// append writes lines, overwriting the oldest
+// lines as needed; it returns the number of
+// lines lost
+func (r *ring) append(lines []byte) int {
+	if len(lines) > len(r.data) {
+		return bytes.Count(lines, []byte{'\n'})
+	}
+	dropped := 0
+	for r.used+len(lines) > len(r.data) {
+		r.dropOldest()
+		dropped++
+	}
+	tail := (r.head + r.used) % len(r.data)
+	n := copy(r.data[tail:], lines)
+	copy(r.data, lines[n:])
+	r.used += len(lines)
+	r.sync()
+	return dropped
+}
+
+// dropOldest removes the oldest line
+func (r *ring) dropOldest() {
+	n := 0
+	for n < r.used {
+		b := r.data[(r.head+n)%len(r.data)]
+		n++
+		if b == '\n' {
+			break
+		}
+	}
+	r.head = (r.head + n) % len(r.data)
+	r.used -= n
+	if lost := min(n, r.pending); lost > 0 {
+		r.pending -= lost
+		r.overwritten += lost
+	}
+}
+
+// peek returns the lines held, they
+// stay in the ring until acknowledged
+func (r *ring) peek() []byte {
+	r.pending, r.overwritten = r.used, 0
+	if r.used == 0 {
+		return nil
+	}
+	lines := make([]byte, r.used)
+	n := copy(lines, r.data[r.head:min(r.head+r.used, len(r.data))])
+	copy(lines[n:], r.data)
+	return lines
+}
+
+// ack removes the first n bytes returned by
+// peek, less those overwritten meanwhile
+func (r *ring) ack(n int) {
+	n = min(n-r.overwritten, r.pending)
+	if n > 0 {
+		r.head = (r.head + n) % len(r.data)
+		r.used -= n
+	}
+	r.pending, r.overwritten = 0, 0
+	r.sync()
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,41 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+package elogrus
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapRing maps the ring file at path,
+// created or resized to size bytes
+func mapRing(path string, size int) (*ring, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	// the mapping outlives the descriptor
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	// a ring of another size
+	// is started over
+	if info.Size() != int64(size) {
+		if err := f.Truncate(0); err != nil {
+			return nil, err
+		}
+		if err := f.Truncate(int64(size)); err != nil {
+			return nil, err
+		}
+	}
+	mem, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	return newRing(mem, func() error {
+		return syscall.Munmap(mem)
+	}), nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd
+
+package elogrus
+
+// mapRing fails, ring files
+// need mmap
+func mapRing(path string, size int) (*ring, error) {
+	return nil, ErrRingUnsupported
+}
@@ -0,0 +1,66 @@
+package elogrus
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRingOverwritesOldest(t *testing.T) {
+	r := newRing(make([]byte, ringHeader+16), func() error { return nil })
+	if r.append([]byte("aaaa\nbbbb\n")) != 0 {
+		t.Fatal("expected room for two lines")
+	}
+	// wraps around the end, overwriting the first line
+	if dropped := r.append([]byte("cccccc\n")); dropped != 1 {
+		t.Errorf("expected one overwritten line, got %d", dropped)
+	}
+	lines := r.peek()
+	if string(lines) != "bbbb\ncccccc\n" {
+		t.Errorf("unexpected lines %q", lines)
+	}
+	r.ack(len(lines))
+	if r.peek() != nil {
+		t.Error("expected the ring to be emptied")
+	}
+	if dropped := r.append([]byte("too long for the ring\n")); dropped != 1 {
+		t.Errorf("expected the oversized line to be dropped, got %d", dropped)
+	}
+}
+
+func TestRingAckAfterOverwrite(t *testing.T) {
+	r := newRing(make([]byte, ringHeader+16), func() error { return nil })
+	r.append([]byte("aaaa\nbbbb\n"))
+	r.peek()
+	// overwrites a line being replayed
+	r.append([]byte("cccccc\n"))
+	r.ack(len("aaaa\n"))
+	if rest := string(r.peek()); rest != "bbbb\ncccccc\n" {
+		t.Errorf("expected the unacknowledged and newer lines, got %q", rest)
+	}
+	r.ack(len("bbbb\n"))
+	if rest := string(r.peek()); rest != "cccccc\n" {
+		t.Errorf("expected the newer line to be kept, got %q", rest)
+	}
+}
+
+func TestRingFileSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool.ring")
+	s := &spool{config: SpoolConfig{Path: path, MaxBytes: 4096, Ring: true}}
+	if err := s.open(); err != nil {
+		t.Fatal(err)
+	}
+	s.append([]byte(`{"index":"test","doc":{}}` + "\n"))
+	s.close()
+	if err := s.append([]byte("late\n")); err != ErrSpoolClosed {
+		t.Errorf("expected ErrSpoolClosed, got %v", err)
+	}
+
+	s = &spool{config: SpoolConfig{Path: path, MaxBytes: 4096, Ring: true}}
+	if err := s.open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+	if lines, _ := s.take(); string(lines) != `{"index":"test","doc":{}}`+"\n" {
+		t.Errorf("expected the spooled line to be kept, got %q", lines)
+	}
+}
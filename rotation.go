@@ -0,0 +1,44 @@
+package elogrus
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// NewElasticHookWithFunc creates a hook whose index
+// is named by index on every Fire, e.g. DailyIndex
+// for time based indices. The other parameters are
+// the same as for NewElasticHook.
+func NewElasticHookWithFunc(client *elastic.Client, host string, level logrus.Level, index func() string, opts ...Option) (*ElasticHook, error) {
+	return NewElasticHook(client, host, level, index(), append(opts, WithIndexFunc(index))...)
+}
+
+// WithIndexFunc names the target index on every
+// Fire, an empty result selects the hook index.
+// It replaces WithIndexRouter and WithIndexPattern.
+func WithIndexFunc(index func() string) Option {
+	return WithIndexRouter(func(*logrus.Entry) string {
+		return index()
+	})
+}
+
+// DailyIndex names indices by prefix and
+// the UTC date, e.g. "myapp-2024.05.17"
+func DailyIndex(prefix string) func() string {
+	return timeIndex(prefix, "2006.01.02")
+}
+
+// HourlyIndex names indices by prefix and
+// the UTC hour, e.g. "myapp-2024.05.17.13"
+func HourlyIndex(prefix string) func() string {
+	return timeIndex(prefix, "2006.01.02.15")
+}
+
+func timeIndex(prefix, layout string) func() string {
+	return func() string {
+		return prefix + time.Now().UTC().Format(layout)
+	}
+}
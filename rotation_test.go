@@ -0,0 +1,30 @@
+package elogrus
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestTimeIndex(t *testing.T) {
+	if index := DailyIndex("myapp-")(); !regexp.MustCompile(`^myapp-\d{4}\.\d{2}\.\d{2}$`).MatchString(index) {
+		t.Errorf("unexpected daily index %q", index)
+	}
+	if index := HourlyIndex("myapp-")(); !regexp.MustCompile(`^myapp-\d{4}\.\d{2}\.\d{2}\.\d{2}$`).MatchString(index) {
+		t.Errorf("unexpected hourly index %q", index)
+	}
+}
+
+func TestWithIndexFunc(t *testing.T) {
+	name := "logs-1"
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "logs", WithIndexFunc(func() string { return name }))
+	entry := &logrus.Entry{Data: logrus.Fields{}}
+	if index := hook.indexFor(entry); index != "logs-1" {
+		t.Errorf("expected logs-1, got %q", index)
+	}
+	name = "logs-2"
+	if index := hook.indexFor(entry); index != "logs-2" {
+		t.Errorf("expected the index to be named on every entry, got %q", index)
+	}
+}
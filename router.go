@@ -0,0 +1,150 @@
+package elogrus
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// WithIndexRouter chooses the target index per
+// entry, an empty result selects the hook index.
+// Routed indices are created on first use.
+func WithIndexRouter(router func(*logrus.Entry) string) Option {
+	return func(hook *ElasticHook) {
+		hook.router = router
+	}
+}
+
+// LevelRouter routes entries by level, e.g. errors
+// to a high retention index; levels missing from
+// indices use the hook index
+func LevelRouter(indices map[logrus.Level]string) func(*logrus.Entry) string {
+	return func(entry *logrus.Entry) string {
+		return indices[entry.Level]
+	}
+}
+
+// FieldRouter routes entries by the value of
+// field, e.g. a tenant, to prefix followed by
+// the value sanitized for index names; entries
+// without the field use the hook index
+func FieldRouter(field, prefix string) func(*logrus.Entry) string {
+	return func(entry *logrus.Entry) string {
+		v, ok := entry.Data[field]
+		if !ok || v == nil || fmt.Sprint(v) == "" {
+			return ""
+		}
+		return prefix + sanitizeIndexName(fmt.Sprint(v))
+	}
+}
+
+// FirstRoute combines routers, the
+// first index chosen is used
+func FirstRoute(routers ...func(*logrus.Entry) string) func(*logrus.Entry) string {
+	return func(entry *logrus.Entry) string {
+		for _, router := range routers {
+			if index := router(entry); index != "" {
+				return index
+			}
+		}
+		return ""
+	}
+}
+
+// WithIndexCacheTTL sets how long routed index
+// existence is remembered (default 1 hour) and
+// how long a failed check or creation blocks
+// new attempts for that index (default 1 minute)
+func WithIndexCacheTTL(ttl, negativeTTL time.Duration) Option {
+	return func(hook *ElasticHook) {
+		hook.indices = newIndexCache(ttl, negativeTTL)
+	}
+}
+
+// indexFor returns the
+// target index of entry
+func (hook *ElasticHook) indexFor(entry *logrus.Entry) string {
+	if hook.quarantined(entry) {
+		return hook.schemaConfig.QuarantineIndex
+	}
+	if hook.router != nil {
+		if index := hook.router(entry); index != "" {
+			return index
+		}
+	}
+	return hook.index
+}
+
+// ensureRouted makes sure a routed index
+// exists, consulting the cache first
+func (hook *ElasticHook) ensureRouted(client *elastic.Client, index string) error {
+	if index == hook.root().index {
+		// checked at construction
+		return nil
+	}
+	if known, err := hook.indices.get(index); known {
+		return err
+	}
+	err := hook.ensureIndex(client, index)
+	hook.indices.put(index, err)
+	return err
+}
+
+// indexCache remembers the outcome
+// of index existence checks
+type indexCache struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	negativeTTL time.Duration
+	entries     map[string]indexCacheEntry
+	now         func() time.Time
+}
+
+type indexCacheEntry struct {
+	err     error
+	expires time.Time
+}
+
+func newIndexCache(ttl, negativeTTL time.Duration) *indexCache {
+	return &indexCache{
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		entries:     map[string]indexCacheEntry{},
+		now:         time.Now,
+	}
+}
+
+// get returns whether the outcome for
+// index is known and the cached error
+func (c *indexCache) get(index string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[index]
+	if !ok {
+		return false, nil
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, index)
+		return false, nil
+	}
+	return true, e.err
+}
+
+func (c *indexCache) put(index string, err error) {
+	ttl := c.ttl
+	if err != nil {
+		ttl = c.negativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	c.entries[index] = indexCacheEntry{err: err, expires: c.now().Add(ttl)}
+	c.mu.Unlock()
+}
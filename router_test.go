@@ -0,0 +1,76 @@
+package elogrus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestIndexFor(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "app",
+		WithIndexRouter(func(entry *logrus.Entry) string {
+			if tenant, ok := entry.Data["tenant"].(string); ok {
+				return "app-" + tenant
+			}
+			return ""
+		}),
+	)
+
+	if index := hook.indexFor(&logrus.Entry{Data: logrus.Fields{"tenant": "acme"}}); index != "app-acme" {
+		t.Errorf("expected app-acme, got %s", index)
+	}
+	if index := hook.indexFor(&logrus.Entry{Data: logrus.Fields{}}); index != "app" {
+		t.Errorf("expected fallback to app, got %s", index)
+	}
+}
+
+func TestRouters(t *testing.T) {
+	router := FirstRoute(
+		FieldRouter("tenant", "app-"),
+		LevelRouter(map[logrus.Level]string{logrus.ErrorLevel: "app-errors"}),
+	)
+	for _, c := range []struct {
+		entry *logrus.Entry
+		index string
+	}{
+		{&logrus.Entry{Level: logrus.ErrorLevel, Data: logrus.Fields{"tenant": "Acme Corp"}}, "app-acme_corp"},
+		{&logrus.Entry{Level: logrus.ErrorLevel, Data: logrus.Fields{}}, "app-errors"},
+		{&logrus.Entry{Level: logrus.DebugLevel, Data: logrus.Fields{"tenant": ""}}, ""},
+	} {
+		if index := router(c.entry); index != c.index {
+			t.Errorf("expected %q, got %q", c.index, index)
+		}
+	}
+}
+
+func TestIndexCache(t *testing.T) {
+	c := newIndexCache(time.Hour, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	if known, _ := c.get("logs"); known {
+		t.Fatal("empty cache should not know any index")
+	}
+
+	c.put("logs", nil)
+	failure := errors.New("forbidden")
+	c.put("broken", failure)
+
+	now = now.Add(30 * time.Second)
+	if known, err := c.get("logs"); !known || err != nil {
+		t.Error("existing index should be cached")
+	}
+	if known, err := c.get("broken"); !known || err != failure {
+		t.Error("failure should be cached")
+	}
+
+	now = now.Add(time.Minute)
+	if known, _ := c.get("broken"); known {
+		t.Error("failure should expire after the negative ttl")
+	}
+	if known, _ := c.get("logs"); !known {
+		t.Error("existing index should still be cached")
+	}
+}
@@ -0,0 +1,45 @@
+package elogrus
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// ServiceField is the field
+// ServiceRouting routes by
+const ServiceField = "service"
+
+// WithRoutingFunc sets the routing key of the documents
+// of every entry, so e.g. the logs of a service live on
+// fewer shards and queries by it search only those; nil
+// routes by ServiceRouting. Empty keys route by ID.
+// Searches must pass the same routing to benefit.
+func WithRoutingFunc(fn func(*logrus.Entry) string) Option {
+	return func(hook *ElasticHook) {
+		if fn == nil {
+			fn = ServiceRouting
+		}
+		hook.routingFunc = fn
+	}
+}
+
+// ServiceRouting routes entries by their
+// service field, or static field of that
+// name, empty without one
+func ServiceRouting(entry *logrus.Entry) string {
+	v, ok := entry.Data[ServiceField]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// routing returns the
+// routing key of entry
+func (hook *ElasticHook) routing(entry *logrus.Entry) string {
+	if hook.routingFunc == nil {
+		return ""
+	}
+	return hook.routingFunc(entry)
+}
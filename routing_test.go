@@ -0,0 +1,26 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestRoutingFunc(t *testing.T) {
+	fwd := &flakyForwarder{}
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test", WithRoutingFunc(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := logrus.New()
+	log.Hooks.Add(hook)
+	log.WithField("service", "billing").Info("charged")
+	log.Info("no service")
+
+	if len(fwd.docs) != 2 || fwd.docs[0].Routing != "billing" || fwd.docs[1].Routing != "" {
+		t.Errorf("unexpected routing of %+v", fwd.docs)
+	}
+	if !hook.Options().Routing {
+		t.Error("expected the routing in the options")
+	}
+}
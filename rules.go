@@ -0,0 +1,127 @@
+package elogrus
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+var (
+	// Fired if an alert rule has
+	// no name, count or window
+	ErrAlertRule = fmt.Errorf("Alert rules need a name, count and window")
+)
+
+// Fields of the documents
+// written by alert rules
+const (
+	AlertRuleField   = "alert.rule"
+	AlertCountField  = "alert.count"
+	AlertWindowField = "alert.window_seconds"
+)
+
+// AlertRule fires when more than Count entries
+// at Level or more severe are logged within Window
+type AlertRule struct {
+	Name string
+	// Level defaults to logrus.ErrorLevel,
+	// Match optionally narrows the entries
+	// counted
+	Level  logrus.Level
+	Match  func(*logrus.Entry) bool
+	Count  int
+	Window time.Duration
+	// Cooldown is the least time between
+	// two firings, default Window
+	Cooldown time.Duration
+	// OnFire is called with the number of
+	// entries in the window, may be nil
+	OnFire func(rule AlertRule, count int)
+	// Document writes an alert document
+	// marked with AlertRuleField
+	Document bool
+}
+
+// WithAlertRules evaluates rules on every entry, so
+// incidents are caught on the producer side even
+// without Kibana alerting. Entries are counted before
+// sampling, quotas and budgets drop them.
+func WithAlertRules(rules ...AlertRule) Option {
+	return func(hook *ElasticHook) {
+		for _, rule := range rules {
+			if rule.Name == "" || rule.Count <= 0 || rule.Window <= 0 {
+				hook.optionErr(ErrAlertRule)
+				return
+			}
+			if rule.Level == logrus.PanicLevel {
+				rule.Level = logrus.ErrorLevel
+			}
+			if rule.Cooldown <= 0 {
+				rule.Cooldown = rule.Window
+			}
+			hook.alertRules = append(hook.alertRules, &alertRule{AlertRule: rule, now: time.Now})
+		}
+	}
+}
+
+type alertRule struct {
+	AlertRule
+	mu sync.Mutex
+	// times of the entries
+	// within the window
+	times []time.Time
+	fired time.Time
+	now   func() time.Time
+}
+
+// count adds an entry and returns the entries
+// in the window when the rule fires, else 0
+func (r *alertRule) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	cut := 0
+	for cut < len(r.times) && now.Sub(r.times[cut]) >= r.Window {
+		cut++
+	}
+	r.times = append(r.times[cut:], now)
+	if len(r.times) <= r.Count || (!r.fired.IsZero() && now.Sub(r.fired) < r.Cooldown) {
+		return 0
+	}
+	r.fired = now
+	return len(r.times)
+}
+
+// evaluateRules counts entry for
+// the rules and fires them
+func (hook *ElasticHook) evaluateRules(entry *logrus.Entry) {
+	if _, ok := entry.Data[AlertRuleField]; ok {
+		return
+	}
+	for _, r := range hook.alertRules {
+		if entry.Level > r.Level || (r.Match != nil && !r.Match(entry)) {
+			continue
+		}
+		n := r.count()
+		if n == 0 {
+			continue
+		}
+		if r.OnFire != nil {
+			r.OnFire(r.AlertRule, n)
+		}
+		if r.Document {
+			hook.fire(&logrus.Entry{
+				Time:    r.now(),
+				Level:   r.Level,
+				Message: fmt.Sprintf("Alert rule %s fired: %d entries within %s", r.Name, n, r.Window),
+				Data: logrus.Fields{
+					AlertRuleField:   r.Name,
+					AlertCountField:  n,
+					AlertWindowField: r.Window.Seconds(),
+				},
+			}, nil)
+		}
+	}
+}
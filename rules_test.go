@@ -0,0 +1,54 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestAlertRules(t *testing.T) {
+	var fired []int
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithAlertRules(AlertRule{
+			Name:     "errors",
+			Count:    2,
+			Window:   time.Minute,
+			Document: true,
+			OnFire:   func(rule AlertRule, count int) { fired = append(fired, count) },
+		}),
+	)
+	now := time.Date(2024, 5, 17, 10, 0, 0, 0, time.UTC)
+	hook.alertRules[0].now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "failed", Data: logrus.Fields{}})
+		hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "not counted", Data: logrus.Fields{}})
+		now = now.Add(time.Second)
+	}
+	if len(fired) != 1 || fired[0] != 3 {
+		t.Fatalf("expected one firing with 3 entries during the cooldown, got %v", fired)
+	}
+	items := hook.bulk.take()
+	if len(items) != 9 {
+		t.Fatalf("expected 8 entries and the alert document, got %d", len(items))
+	}
+	alert := items[4].doc.(map[string]interface{})["Data"].(logrus.Fields)
+	if alert[AlertRuleField] != "errors" || alert[AlertCountField] != 3 {
+		t.Errorf("unexpected alert document %v", alert)
+	}
+
+	now = now.Add(2 * time.Minute)
+	hook.Fire(&logrus.Entry{Level: logrus.ErrorLevel, Message: "failed", Data: logrus.Fields{}})
+	if len(fired) != 1 {
+		t.Error("expected the window to have expired")
+	}
+}
+
+func TestInvalidAlertRule(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithAlertRules(AlertRule{Name: "errors"}))
+	if hook.err != ErrAlertRule {
+		t.Errorf("expected ErrAlertRule, got %v", hook.err)
+	}
+}
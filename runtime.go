@@ -0,0 +1,82 @@
+package elogrus
+
+import (
+	"io/ioutil"
+	"runtime"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// RuntimeMetricsConfig configures the runtime
+// metrics shipper, zero values fall back to
+// the defaults
+type RuntimeMetricsConfig struct {
+	// Index receiving the documents,
+	// default the hook index + "-metrics"
+	Index string
+	// Interval between documents,
+	// default 1 minute
+	Interval time.Duration
+}
+
+// WithRuntimeMetrics periodically ships a document with
+// Go runtime metrics (heap, GC, goroutines and, on
+// Linux, open file descriptors) through the hook, for
+// deployments without a metrics stack
+func WithRuntimeMetrics(config RuntimeMetricsConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Index == "" {
+			config.Index = hook.index + "-metrics"
+		}
+		if config.Interval <= 0 {
+			config.Interval = time.Minute
+		}
+		hook.runtimeMetrics = &config
+	}
+}
+
+func (hook *ElasticHook) runRuntimeMetrics() {
+	config := hook.runtimeMetrics
+	for {
+		timer := time.NewTimer(config.Interval)
+		select {
+		case <-timer.C:
+		case <-hook.quit:
+			timer.Stop()
+			return
+		}
+		docs := []map[string]interface{}{hook.runtimeDocument(time.Now())}
+		ids := hook.documentIDs(docs)
+		err := hook.do(func(client *elastic.Client) error {
+			_, err := hook.send(hook.ctx, client, config.Index, "", docs, ids)
+			return err
+		})
+		if err != nil {
+			hook.reportError(err)
+		}
+	}
+}
+
+// runtimeDocument returns the
+// runtime metrics at now
+func (hook *ElasticHook) runtimeDocument(now time.Time) map[string]interface{} {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	doc := map[string]interface{}{
+		hook.timestampField:         hook.timestamp(now),
+		"runtime.goroutines":        runtime.NumGoroutine(),
+		"runtime.heap.alloc_bytes":  m.HeapAlloc,
+		"runtime.heap.sys_bytes":    m.HeapSys,
+		"runtime.heap.objects":      m.HeapObjects,
+		"runtime.gc.count":          m.NumGC,
+		"runtime.gc.pause_total_ns": m.PauseTotalNs,
+		"runtime.gc.cpu_fraction":   m.GCCPUFraction,
+		"runtime.memory.sys_bytes":  m.Sys,
+	}
+	doc[hook.originField("Host", "host")] = hook.host
+	if fds, err := ioutil.ReadDir("/proc/self/fd"); err == nil {
+		doc["runtime.fds"] = len(fds)
+	}
+	return doc
+}
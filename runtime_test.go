@@ -0,0 +1,24 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestRuntimeMetrics(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "app", WithRuntimeMetrics(RuntimeMetricsConfig{}))
+	defer hook.Close()
+	if c := hook.Options().RuntimeMetrics; c == nil || c.Index != "app-metrics" || c.Interval != time.Minute {
+		t.Errorf("unexpected defaults %+v", c)
+	}
+
+	doc := hook.runtimeDocument(time.Now())
+	if n, _ := doc["runtime.goroutines"].(int); n <= 0 {
+		t.Errorf("expected a goroutine count, got %v", doc["runtime.goroutines"])
+	}
+	if doc["runtime.heap.alloc_bytes"] == nil || doc["origin.host"] != "localhost" {
+		t.Errorf("unexpected document %v", doc)
+	}
+}
@@ -0,0 +1,153 @@
+package elogrus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// SamplingConfig thins out entries
+// before they are shipped
+type SamplingConfig struct {
+	// Every keeps 1 in N entries of a level,
+	// e.g. {logrus.DebugLevel: 100}
+	Every map[logrus.Level]int
+	// Rate limits the entries per second kept
+	// after sampling, 0 disables the limit
+	Rate float64
+	// Burst is the number of entries shipped
+	// at once under Rate, default is Rate
+	Burst int
+}
+
+// Fields stamped on entries which
+// stand for siblings sampled away
+const (
+	SamplingRateField    = "sampling.rate"
+	SamplingDroppedField = "sampling.dropped_estimate"
+)
+
+// SamplingCounts holds the number
+// of entries sampled away
+type SamplingCounts struct {
+	// Sampled counts entries
+	// dropped per level by Every
+	Sampled map[logrus.Level]int64
+	// RateLimited counts entries
+	// dropped for exceeding Rate
+	RateLimited int64
+}
+
+// WithSampling keeps 1 in N entries of noisy levels
+// and caps the entries per second, so log storms do
+// not overwhelm the cluster; see SamplingDropped
+func WithSampling(config SamplingConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Burst <= 0 {
+			config.Burst = int(config.Rate)
+		}
+		every := make(map[logrus.Level]int, len(config.Every))
+		for level, n := range config.Every {
+			every[level] = n
+		}
+		config.Every = every
+		s := &sampler{
+			config:  config,
+			seen:    map[logrus.Level]int64{},
+			sampled: map[logrus.Level]int64{},
+			since:   map[logrus.Level]int64{},
+			now:     time.Now,
+		}
+		if config.Rate > 0 {
+			s.bucket = newTokenBucket(config.Rate, config.Burst, s.now())
+		}
+		hook.sampler = s
+	}
+}
+
+// SamplingDropped returns the number
+// of entries sampled away so far
+func (hook *ElasticHook) SamplingDropped() SamplingCounts {
+	if hook.sampler == nil {
+		return SamplingCounts{}
+	}
+	return hook.sampler.snapshot()
+}
+
+type sampler struct {
+	mu          sync.Mutex
+	config      SamplingConfig
+	seen        map[logrus.Level]int64
+	sampled     map[logrus.Level]int64
+	rateLimited int64
+	// since counts the entries dropped
+	// per level since one was kept
+	since  map[logrus.Level]int64
+	bucket *tokenBucket
+	now    func() time.Time
+}
+
+// sample is the decision on a kept entry,
+// dropped the siblings it stands for
+type sample struct {
+	dropped int64
+}
+
+// keep reports whether an entry at level is
+// shipped, and the siblings dropped since the
+// last one kept
+func (s *sampler) keep(level logrus.Level) (sample, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := s.config.Every[level]; n > 1 {
+		seen := s.seen[level]
+		s.seen[level]++
+		if seen%int64(n) != 0 {
+			s.sampled[level]++
+			s.since[level]++
+			return sample{}, false
+		}
+	}
+	if s.bucket != nil && !s.bucket.take(s.now()) {
+		s.rateLimited++
+		s.since[level]++
+		return sample{}, false
+	}
+	kept := sample{dropped: s.since[level]}
+	s.since[level] = 0
+	return kept, true
+}
+
+func (s *sampler) snapshot() SamplingCounts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := SamplingCounts{Sampled: make(map[logrus.Level]int64, len(s.sampled)), RateLimited: s.rateLimited}
+	for level, n := range s.sampled {
+		c.Sampled[level] = n
+	}
+	return c
+}
+
+// sampledIn reports whether
+// entry survives sampling
+func (hook *ElasticHook) sampledIn(entry *logrus.Entry) (sample, bool) {
+	if hook.sampler == nil {
+		return sample{}, true
+	}
+	return hook.sampler.keep(entry.Level)
+}
+
+// stamp records the sampling rate on docs
+// when siblings were dropped, to scale
+// counts back to the true volume
+func (s sample) stamp(docs []map[string]interface{}) []map[string]interface{} {
+	if s.dropped == 0 {
+		return docs
+	}
+	for _, doc := range docs {
+		doc[SamplingRateField] = 1 / float64(s.dropped+1)
+		doc[SamplingDroppedField] = s.dropped
+	}
+	return docs
+}
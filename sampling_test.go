@@ -0,0 +1,56 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestSampling(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithSampling(SamplingConfig{Every: map[logrus.Level]int{logrus.DebugLevel: 10}, Rate: 5}))
+	now := time.Now()
+	hook.sampler.now = func() time.Time { return now }
+	hook.sampler.bucket = newTokenBucket(5, 5, now)
+
+	kept := 0
+	for i := 0; i < 100; i++ {
+		if _, ok := hook.sampledIn(&logrus.Entry{Level: logrus.DebugLevel}); ok {
+			kept++
+		}
+	}
+	if kept != 5 {
+		t.Errorf("expected 1 in 10 debug entries up to the rate of 5, kept %d", kept)
+	}
+	counts := hook.SamplingDropped()
+	if counts.Sampled[logrus.DebugLevel] != 90 || counts.RateLimited != 5 {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := hook.sampledIn(&logrus.Entry{Level: logrus.ErrorLevel}); !ok {
+		t.Error("levels without sampling must only be rate limited")
+	}
+}
+
+func TestSamplingStamp(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithSampling(SamplingConfig{Every: map[logrus.Level]int{logrus.DebugLevel: 4}}))
+	for i := 0; i < 5; i++ {
+		hook.Fire(&logrus.Entry{Level: logrus.DebugLevel, Data: logrus.Fields{}})
+	}
+
+	items := hook.bulk.take()
+	if len(items) != 2 {
+		t.Fatalf("expected 2 kept entries, got %d", len(items))
+	}
+	if _, ok := items[0].doc.(map[string]interface{})[SamplingRateField]; ok {
+		t.Error("the first entry stands for no dropped siblings")
+	}
+	doc := items[1].doc.(map[string]interface{})
+	if doc[SamplingRateField] != 0.25 || doc[SamplingDroppedField] != int64(3) {
+		t.Errorf("unexpected sampling fields %v, %v", doc[SamplingRateField], doc[SamplingDroppedField])
+	}
+}
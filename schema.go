@@ -0,0 +1,252 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync/atomic"
+
+	"github.com/Sirupsen/logrus"
+)
+
+var (
+	// Fired if WithSchema
+	// is given no schema
+	ErrNoSchema = fmt.Errorf("Schema is required")
+)
+
+// SchemaViolationsField lists the violations
+// in the data of quarantined entries
+const SchemaViolationsField = "schema.violations"
+
+// Kinds of schema violations
+const (
+	UndeclaredField = "undeclared"
+	FieldTypeError  = "type"
+	MissingField    = "missing"
+)
+
+// Schema is the subset of a JSON schema describing
+// entry data: the properties and their types, the
+// required ones, and whether others are allowed
+type Schema struct {
+	Properties map[string]struct {
+		// Type is a JSON type name or a list of
+		// them, an empty one accepts anything
+		Type schemaTypes `json:"type"`
+	} `json:"properties"`
+	Required             []string `json:"required"`
+	AdditionalProperties *bool    `json:"additionalProperties"`
+}
+
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var one string
+	if json.Unmarshal(b, &one) == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
+// ParseSchema parses a JSON schema,
+// e.g. a file embedded with go:embed
+func ParseSchema(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// FetchSchema gets the JSON schema
+// at url, e.g. from a registry
+func FetchSchema(ctx context.Context, url string) (*Schema, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil, fmt.Errorf("Schema fetch failed: %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSchema(data)
+}
+
+// SchemaViolation is a field of
+// an entry breaking the schema
+type SchemaViolation struct {
+	Field string
+	// Kind is UndeclaredField,
+	// FieldTypeError or MissingField
+	Kind string
+}
+
+func (v SchemaViolation) String() string {
+	return v.Kind + ":" + v.Field
+}
+
+// SchemaObserver is an optional extension
+// of Observer, called with every violation
+type SchemaObserver interface {
+	SchemaViolated(v SchemaViolation)
+}
+
+// SchemaConfig configures WithSchema
+type SchemaConfig struct {
+	// QuarantineIndex receives the entries breaking
+	// the schema, with their violations listed in
+	// SchemaViolationsField; when empty they are
+	// sent as usual
+	QuarantineIndex string
+}
+
+// WithSchema validates the data of every entry
+// against schema, counting violations in Stats
+// and reporting them to a SchemaObserver
+func WithSchema(schema *Schema, config SchemaConfig) Option {
+	return func(hook *ElasticHook) {
+		if schema == nil {
+			hook.optionErr(ErrNoSchema)
+			return
+		}
+		hook.schema = schema
+		hook.schemaConfig = config
+	}
+}
+
+// validate returns the
+// violations of data
+func (s *Schema) validate(data logrus.Fields) []SchemaViolation {
+	var violations []SchemaViolation
+	for k, v := range data {
+		p, ok := s.Properties[k]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				violations = append(violations, SchemaViolation{Field: k, Kind: UndeclaredField})
+			}
+			continue
+		}
+		if len(p.Type) > 0 && !p.Type.accept(v) {
+			violations = append(violations, SchemaViolation{Field: k, Kind: FieldTypeError})
+		}
+	}
+	for _, k := range s.Required {
+		if _, ok := data[k]; !ok {
+			violations = append(violations, SchemaViolation{Field: k, Kind: MissingField})
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].Field < violations[j].Field
+	})
+	return violations
+}
+
+func (t schemaTypes) accept(v interface{}) bool {
+	kind := jsonType(v)
+	for _, name := range t {
+		if name == kind || name == "number" && kind == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON
+// type v is encoded as
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string, error:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	case float32:
+		return jsonType(float64(v))
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	}
+	b, err := json.Marshal(v)
+	if err != nil || len(b) == 0 {
+		return ""
+	}
+	switch b[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	var f float64
+	json.Unmarshal(b, &f)
+	return jsonType(f)
+}
+
+// checkAgainstSchema reports the violations
+// of entry, it returns the entry to send,
+// tagged when it is quarantined
+func (hook *ElasticHook) checkAgainstSchema(entry *logrus.Entry) *logrus.Entry {
+	if hook.schema == nil {
+		return entry
+	}
+	violations := hook.schema.validate(entry.Data)
+	if len(violations) == 0 {
+		return entry
+	}
+	atomic.AddInt64(&hook.root().schemaViolations, int64(len(violations)))
+	if o, ok := hook.observer.(SchemaObserver); ok {
+		for _, v := range violations {
+			o.SchemaViolated(v)
+		}
+	}
+	if hook.schemaConfig.QuarantineIndex == "" {
+		return entry
+	}
+	names := make([]string, len(violations))
+	for i, v := range violations {
+		names[i] = v.String()
+	}
+	data := make(logrus.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	data[SchemaViolationsField] = names
+	tagged := *entry
+	tagged.Data = data
+	return &tagged
+}
+
+// quarantined reports whether entry
+// goes to the quarantine index
+func (hook *ElasticHook) quarantined(entry *logrus.Entry) bool {
+	if hook.schemaConfig.QuarantineIndex == "" {
+		return false
+	}
+	_, ok := entry.Data[SchemaViolationsField]
+	return ok
+}
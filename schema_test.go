@@ -0,0 +1,67 @@
+package elogrus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const testSchema = `{
+	"properties": {
+		"user":     {"type": "string"},
+		"status":   {"type": "integer"},
+		"duration": {"type": ["number", "null"]}
+	},
+	"required": ["user"],
+	"additionalProperties": false
+}`
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := schema.validate(logrus.Fields{"user": "bob", "status": 200.0, "duration": 1.5}); len(v) != 0 {
+		t.Errorf("expected no violations, got %v", v)
+	}
+	v := schema.validate(logrus.Fields{"status": "ok", "body": "x"})
+	expected := []SchemaViolation{{"body", UndeclaredField}, {"status", FieldTypeError}, {"user", MissingField}}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("expected %v, got %v", expected, v)
+	}
+}
+
+func TestSchemaQuarantine(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testSchema))
+	}))
+	defer srv.Close()
+	schema, err := FetchSchema(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithSchema(schema, SchemaConfig{QuarantineIndex: "test-quarantine"}),
+	)
+	hook.Fire(&logrus.Entry{Message: "valid", Data: logrus.Fields{"user": "bob"}})
+	hook.Fire(&logrus.Entry{Message: "invalid", Data: logrus.Fields{"user": "bob", "status": true}})
+
+	items := hook.bulk.take()
+	if len(items) != 2 || items[0].index != "test" || items[1].index != "test-quarantine" {
+		t.Fatalf("expected the invalid entry to be quarantined, got %v", items)
+	}
+	data := items[1].doc.(map[string]interface{})["Data"].(logrus.Fields)
+	if !reflect.DeepEqual(data[SchemaViolationsField], []string{"type:status"}) {
+		t.Errorf("unexpected violations %v", data[SchemaViolationsField])
+	}
+	if n := hook.Stats().SchemaViolations; n != 1 {
+		t.Errorf("expected one violation, got %d", n)
+	}
+}
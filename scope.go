@@ -0,0 +1,80 @@
+package elogrus
+
+import (
+	"context"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type (
+	fieldsKey struct{}
+	loggerKey struct{}
+)
+
+// ContextWithFields returns a copy of ctx carrying
+// fields, merged with those ctx already carries,
+// e.g. set by middleware for the request
+func ContextWithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	parent := FieldsFromContext(ctx)
+	merged := make(logrus.Fields, len(parent)+len(fields))
+	for k, v := range parent {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext returns the fields
+// stored in ctx, nil if none; they must
+// not be modified
+func FieldsFromContext(ctx context.Context) logrus.Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).(logrus.Fields)
+	return fields
+}
+
+// ContextWithLogger returns a copy of ctx
+// whose FromContext entries log to logger
+func ContextWithLogger(ctx context.Context, logger *logrus.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns an entry with the fields of ctx
+// and ctx as context, logging to the logger of ctx,
+// the standard logger by default, so deep call stacks
+// log with the request fields through the shared hook
+func FromContext(ctx context.Context) *logrus.Entry {
+	var logger *logrus.Logger
+	if ctx != nil {
+		logger, _ = ctx.Value(loggerKey{}).(*logrus.Logger)
+	}
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return logger.WithContext(ctx).WithFields(FieldsFromContext(ctx))
+}
+
+// addContextFields returns entry with the fields
+// of its context, for entries logged with the
+// context but not FromContext; entry data
+// takes precedence
+func addContextFields(entry *logrus.Entry) *logrus.Entry {
+	fields := FieldsFromContext(entry.Context)
+	if len(fields) == 0 {
+		return entry
+	}
+	data := make(logrus.Fields, len(entry.Data)+len(fields))
+	for k, v := range fields {
+		data[k] = v
+	}
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	scoped := *entry
+	scoped.Data = data
+	return &scoped
+}
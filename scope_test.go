@@ -0,0 +1,45 @@
+package elogrus
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestFromContext(t *testing.T) {
+	fwd := &flakyForwarder{}
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logrus.New()
+	logger.Hooks.Add(hook)
+
+	ctx := ContextWithFields(context.Background(), logrus.Fields{"request_id": "r-1", "user": "alice"})
+	ctx = ContextWithFields(ctx, logrus.Fields{"user": "bob"})
+	ctx = ContextWithLogger(ctx, logger)
+	entry := FromContext(ctx)
+	if entry.Logger != logger || entry.Data["request_id"] != "r-1" || entry.Data["user"] != "bob" {
+		t.Fatalf("unexpected entry %v", entry.Data)
+	}
+	entry.Info("scoped")
+
+	// entries logged with the context only
+	// carry its fields in the document
+	logger.WithContext(ctx).WithField("user", "carol").Info("plain")
+
+	if len(fwd.docs) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(fwd.docs))
+	}
+	for i, user := range []string{"bob", "carol"} {
+		body := string(fwd.docs[i].Body)
+		if !strings.Contains(body, `"request_id":"r-1"`) || !strings.Contains(body, `"user":"`+user+`"`) {
+			t.Errorf("unexpected document %s", body)
+		}
+	}
+	if FieldsFromContext(nil) != nil || FromContext(context.Background()).Logger != logrus.StandardLogger() {
+		t.Error("expected the standard logger without fields")
+	}
+}
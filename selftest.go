@@ -0,0 +1,74 @@
+package elogrus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// WithSelfTest writes a probe document when
+// the client is set up, so bad credentials or
+// conflicting mappings fail the constructor
+// (or the first Fire of a lazy hook) instead
+// of every later entry.
+// remove - delete the probe document afterwards
+func WithSelfTest(remove bool) Option {
+	return func(hook *ElasticHook) {
+		hook.selfTest = true
+		hook.selfTestRemove = remove
+	}
+}
+
+// runSelfTest writes and optionally
+// deletes the probe document
+func (hook *ElasticHook) runSelfTest(client *elastic.Client) error {
+	if !hook.selfTest {
+		return nil
+	}
+	ctx, cancel := hook.controlContext()
+	defer cancel()
+	doc, id := hook.probe()
+	req := client.
+		Index().
+		Index(hook.index).
+		Type(hook.docType()).
+		BodyJson(doc)
+	if id != "" {
+		req.Id(id)
+	}
+	if hook.creates(id) {
+		req.OpType("create")
+	}
+	resp, err := req.DoC(ctx)
+	if err != nil {
+		return fmt.Errorf("Self-test write failed: %w", err)
+	}
+	if !hook.selfTestRemove {
+		return nil
+	}
+	_, err = client.
+		Delete().
+		Index(resp.Index).
+		Type(resp.Type).
+		Id(resp.Id).
+		DoC(ctx)
+	if err != nil {
+		return fmt.Errorf("Self-test delete failed: %w", err)
+	}
+	return nil
+}
+
+// probe returns the self-test document and its ID,
+// which are written as send writes documents
+func (hook *ElasticHook) probe() (map[string]interface{}, string) {
+	doc := hook.document(&logrus.Entry{
+		Data:    logrus.Fields{"elogrus_self_test": true},
+		Time:    time.Now(),
+		Level:   logrus.InfoLevel,
+		Message: "elogrus self-test",
+	})
+	return doc, hook.documentID(doc)
+}
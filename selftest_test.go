@@ -0,0 +1,39 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestSelfTestProbe(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithSelfTest(true))
+	defer hook.Close()
+	doc, id := hook.probe()
+	if id != "" || hook.creates(id) {
+		t.Errorf("expected an indexed probe without ID, got %q", id)
+	}
+	if data, ok := doc["Data"].(logrus.Fields); !ok || data["elogrus_self_test"] != true {
+		t.Errorf("expected the self-test field, got %v", doc)
+	}
+}
+
+func TestSelfTestProbeDataStream(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithSelfTest(true), WithDataStream("logs-app-default"))
+	defer hook.Close()
+	doc, id := hook.probe()
+	if !hook.creates(id) {
+		t.Error("data streams only accept probes written with op_type create")
+	}
+	if _, ok := doc["@timestamp"]; !ok {
+		t.Errorf("expected the data stream timestamp, got %v", doc)
+	}
+}
+
+func TestSelfTestProbeDocumentIDs(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithSelfTest(true), WithDocumentIDs("node-1"))
+	defer hook.Close()
+	if _, id := hook.probe(); id == "" || !hook.creates(id) {
+		t.Errorf("expected the probe created with an ID, got %q", id)
+	}
+}
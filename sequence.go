@@ -0,0 +1,38 @@
+package elogrus
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SequenceField orders documents
+// in their emission order
+const SequenceField = "event.sequence"
+
+// WithEventSequence adds SequenceField to documents:
+// the entry time in nanoseconds since the epoch,
+// counted up past the last value of the hook and
+// its clones, so documents of an instance logged
+// within the same millisecond, or with the clock
+// stepping back, sort in the order they were fired
+func WithEventSequence() Option {
+	return func(hook *ElasticHook) {
+		hook.eventSequence = true
+	}
+}
+
+// nextSequence returns the sequence of an
+// entry logged at t, above all previous ones
+func (hook *ElasticHook) nextSequence(t time.Time) int64 {
+	last := &hook.root().lastSequence
+	for {
+		prev := atomic.LoadInt64(last)
+		n := t.UnixNano()
+		if n <= prev {
+			n = prev + 1
+		}
+		if atomic.CompareAndSwapInt64(last, prev, n) {
+			return n
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestEventSequence(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithEventSequence())
+	clone := hook.Clone()
+	now := time.Now()
+	var seqs []int64
+	for _, h := range []*ElasticHook{hook, clone, hook} {
+		doc := h.document(&logrus.Entry{Time: now, Message: "same millisecond"})
+		seqs = append(seqs, doc[SequenceField].(int64))
+	}
+	// the clock stepping back
+	doc := hook.document(&logrus.Entry{Time: now.Add(-time.Second)})
+	seqs = append(seqs, doc[SequenceField].(int64))
+
+	if seqs[0] != now.UnixNano() {
+		t.Errorf("expected the entry time, got %d", seqs[0])
+	}
+	for i := 1; i < len(seqs); i++ {
+		if seqs[i] != seqs[i-1]+1 {
+			t.Errorf("expected increasing sequences, got %v", seqs)
+		}
+	}
+}
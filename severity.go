@@ -0,0 +1,23 @@
+package elogrus
+
+import "github.com/Sirupsen/logrus"
+
+// Severity maps a logrus level to
+// its syslog (RFC 5424) severity,
+// lower numbers being more severe
+func Severity(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel:
+		return 0
+	case logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
@@ -0,0 +1,47 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestSeverityOrdering(t *testing.T) {
+	prev := -1
+	for _, l := range []logrus.Level{
+		logrus.PanicLevel,
+		logrus.FatalLevel,
+		logrus.ErrorLevel,
+		logrus.WarnLevel,
+		logrus.InfoLevel,
+		logrus.DebugLevel,
+	} {
+		s := Severity(l)
+		if s <= prev {
+			t.Errorf("severity of %s (%d) should be above %d", l, s, prev)
+		}
+		prev = s
+	}
+}
+
+func TestDocumentLevelFields(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithLevelField("log.level"),
+		WithSeverityField("severity"),
+		WithLevelValue("level_value"),
+	)
+
+	doc := hook.document(&logrus.Entry{Level: logrus.ErrorLevel, Message: "boom"})
+	if doc["log.level"] != "ERROR" {
+		t.Errorf("expected ERROR under log.level, got %v", doc["log.level"])
+	}
+	if _, ok := doc["Level"]; ok {
+		t.Error("default Level field should not be present after rename")
+	}
+	if doc["severity"] != 3 {
+		t.Errorf("expected severity 3, got %v", doc["severity"])
+	}
+	if doc["level_value"] != uint32(logrus.ErrorLevel) {
+		t.Errorf("expected the logrus level value, got %v", doc["level_value"])
+	}
+}
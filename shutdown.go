@@ -0,0 +1,167 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// UnsentError is returned by Shutdown when
+// its deadline passed before all pending
+// documents were delivered
+type UnsentError struct {
+	// Documents holds the JSON
+	// of the unsent documents
+	Documents []json.RawMessage
+	// IDs holds the document IDs in the same
+	// order, empty without WithDocumentIDs
+	IDs []string
+	Err error
+}
+
+func (e *UnsentError) Error() string {
+	return fmt.Sprintf("%d documents not sent: %v", len(e.Documents), e.Err)
+}
+
+// WithResidueWriter makes Shutdown write documents
+// left unsent at its deadline to w, one JSON
+// document per line, so callers can persist them
+func WithResidueWriter(w io.Writer) Option {
+	return func(hook *ElasticHook) {
+		hook.residue = w
+	}
+}
+
+// Shutdown flushes pending documents and stops
+// background work like Close, but gives up when
+// ctx is done: requests in flight are aborted and
+// the documents not delivered are returned in an
+// *UnsentError and written to the residue writer,
+// not spooled or passed to the fallbacks. Queued
+// async entries are returned too.
+func (hook *ElasticHook) Shutdown(ctx context.Context) error {
+	if hook.parent != nil {
+		return hook.Flush()
+	}
+	hook.stop()
+	defer hook.controlCancel()
+	defer hook.closeSpool()
+	if hook.bulk == nil && hook.async == nil {
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.drain()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	hook.cancel()
+	hook.controlCancel()
+	<-done
+	uerr := &UnsentError{Err: ctx.Err()}
+	if hook.async != nil {
+		for _, item := range hook.async.takeUnsent() {
+			for i, doc := range item.docs {
+				hook.addUnsent(uerr, doc, item.ids[i])
+			}
+		}
+	}
+	if hook.bulk == nil {
+		if len(uerr.Documents) == 0 {
+			return ctx.Err()
+		}
+		return uerr
+	}
+	// pending items are aborted, so
+	// finish leaves them unsent
+	for _, item := range hook.bulk.take() {
+		hook.bulk.finish(item, ctx.Err())
+	}
+	for _, item := range hook.bulk.takeUnsent() {
+		hook.addUnsent(uerr, item.doc, item.docID)
+	}
+	if len(uerr.Documents) == 0 {
+		return nil
+	}
+	return uerr
+}
+
+// addUnsent adds doc to uerr and
+// writes it to the residue writer
+func (hook *ElasticHook) addUnsent(uerr *UnsentError, doc interface{}, id string) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return
+	}
+	uerr.Documents = append(uerr.Documents, raw)
+	uerr.IDs = append(uerr.IDs, id)
+	if hook.residue != nil {
+		hook.residue.Write(append(raw, '\n'))
+	}
+}
+
+// aborted reports the failure of a document
+// Shutdown gave up on, it returns the
+// document instead of spooling it
+func (hook *ElasticHook) aborted(err error) bool {
+	return hook.root().ctx.Err() != nil && (errors.Is(err, context.Canceled) || isClusterFailure(err))
+}
+
+// takeUnsent removes and returns the
+// documents aborted by Shutdown
+func (b *batcher) takeUnsent() []bulkItem {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := b.unsent
+	b.unsent = nil
+	return items
+}
+
+// WithStopTimeout sets how long Flush, Close
+// and a graceful Stop wait for pending documents.
+// Stop defaults to 5 seconds, Flush and Close
+// wait without limit when it is not set.
+func WithStopTimeout(d time.Duration) Option {
+	return func(hook *ElasticHook) {
+		hook.stopTimeout = d
+	}
+}
+
+// Stop shuts the hook down. A graceful stop drains
+// pending documents within the stop timeout, see
+// WithStopTimeout; otherwise requests in flight are
+// aborted at once. Either way the documents left
+// unsent are returned as by Shutdown.
+func (hook *ElasticHook) Stop(graceful bool) error {
+	if hook.parent != nil {
+		return hook.Flush()
+	}
+	if !graceful {
+		hook.cancel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return hook.Shutdown(ctx)
+	}
+	timeout := hook.stopTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return hook.Shutdown(ctx)
+}
+
+// Cancel aborts requests in flight and stops the hook.
+//
+// Deprecated: use Stop(false).
+func (hook *ElasticHook) Cancel() error {
+	return hook.Stop(false)
+}
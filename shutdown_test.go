@@ -0,0 +1,132 @@
+package elogrus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestShutdownReturnsUnsent(t *testing.T) {
+	var residue bytes.Buffer
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithMaxInFlight(1),
+		WithResidueWriter(&residue),
+	)
+	// occupy the only slot, as if the cluster hung
+	hook.inFlight <- struct{}{}
+
+	if err := hook.Fire(&logrus.Entry{Message: "last words", Data: logrus.Fields{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := hook.Shutdown(ctx)
+
+	uerr, ok := err.(*UnsentError)
+	if !ok {
+		t.Fatalf("expected *UnsentError, got %v", err)
+	}
+	if len(uerr.Documents) != 1 || !strings.Contains(string(uerr.Documents[0]), "last words") {
+		t.Errorf("unexpected unsent documents %s", uerr.Documents)
+	}
+	if !strings.Contains(residue.String(), "last words") {
+		t.Errorf("expected residue to be written, got %q", residue.String())
+	}
+}
+
+func TestStopAbortsImmediately(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithMaxInFlight(1),
+	)
+	hook.inFlight <- struct{}{}
+
+	if err := hook.Fire(&logrus.Entry{Message: "last words", Data: logrus.Fields{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	err := hook.Stop(false)
+	if time.Since(start) > time.Second {
+		t.Error("non-graceful stop must not wait for pending documents")
+	}
+	uerr, ok := err.(*UnsentError)
+	if !ok || len(uerr.Documents) != 1 {
+		t.Fatalf("expected one unsent document, got %v", err)
+	}
+}
+
+// hangingForwarder holds requests
+// until they are aborted
+type hangingForwarder struct {
+	started chan struct{}
+}
+
+func (f *hangingForwarder) Forward(ctx context.Context, docs []ForwardedDocument) ([]error, error) {
+	f.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestShutdownReturnsAbortedOnce(t *testing.T) {
+	fwd := &hangingForwarder{started: make(chan struct{}, 1)}
+	var mu sync.Mutex
+	var handled []string
+	record := func(doc json.RawMessage, err error) {
+		mu.Lock()
+		handled = append(handled, string(doc))
+		mu.Unlock()
+	}
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{Actions: 1, FlushInterval: time.Hour}),
+		WithOnDiscard(record),
+		WithFallback(logrus.DebugLevel, FallbackFunc(func(doc json.RawMessage) error {
+			record(doc, nil)
+			return nil
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "in flight", Data: logrus.Fields{}})
+	<-fwd.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	uerr, ok := hook.Shutdown(ctx).(*UnsentError)
+	if !ok || len(uerr.Documents) != 1 || !strings.Contains(string(uerr.Documents[0]), "in flight") {
+		t.Fatalf("expected the aborted document, got %v", uerr)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 0 {
+		t.Errorf("expected the unsent document only returned, got %q", handled)
+	}
+}
+
+func TestShutdownReturnsQueuedAsync(t *testing.T) {
+	fwd := &hangingForwarder{started: make(chan struct{}, 1)}
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test",
+		WithAsync(AsyncConfig{Buffer: 10, Workers: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "in flight", Data: logrus.Fields{}})
+	<-fwd.started
+	hook.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: "queued", Data: logrus.Fields{}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	uerr, ok := hook.Shutdown(ctx).(*UnsentError)
+	if !ok || len(uerr.Documents) != 1 || !strings.Contains(string(uerr.Documents[0]), "queued") {
+		t.Fatalf("expected the queued entry, got %v", uerr)
+	}
+}
@@ -0,0 +1,148 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// maxSizeSites bounds the call
+// sites counted for Stats
+const maxSizeSites = 1000
+
+// SizeWarningConfig configures warnings about
+// documents estimated larger than Bytes
+type SizeWarningConfig struct {
+	// Bytes defaults to 64 KiB
+	Bytes int
+	// Interval is the least time between two
+	// warnings for a call site, default 1 minute
+	Interval time.Duration
+}
+
+// WithSizeWarnings estimates the serialized size of
+// every document before it is sent and warns on the
+// internal logger about the call sites logging larger
+// ones, to find accidental payload logging. Stats
+// counts the outliers per call site.
+func WithSizeWarnings(config SizeWarningConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Bytes <= 0 {
+			config.Bytes = 64 << 10
+		}
+		if config.Interval <= 0 {
+			config.Interval = time.Minute
+		}
+		hook.sizeWarnings = &sizeWarnings{
+			config: config,
+			warned: map[string]time.Time{},
+			sites:  map[string]int64{},
+			now:    time.Now,
+		}
+	}
+}
+
+type sizeWarnings struct {
+	mu     sync.Mutex
+	config SizeWarningConfig
+	warned map[string]time.Time
+	sites  map[string]int64
+	now    func() time.Time
+}
+
+// record counts an outlier of site and
+// reports whether to warn about it
+func (w *sizeWarnings) record(site string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.sites[site]; ok || len(w.sites) < maxSizeSites {
+		w.sites[site]++
+	}
+	now := w.now()
+	if last, ok := w.warned[site]; ok && now.Sub(last) < w.config.Interval {
+		return false
+	}
+	w.warned[site] = now
+	return true
+}
+
+func (w *sizeWarnings) snapshot() map[string]int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sites := make(map[string]int64, len(w.sites))
+	for k, n := range w.sites {
+		sites[k] = n
+	}
+	return sites
+}
+
+// checkSizes warns about the documents
+// of entry estimated too large
+func (hook *ElasticHook) checkSizes(entry *logrus.Entry, docs []map[string]interface{}) {
+	if hook.sizeWarnings == nil {
+		return
+	}
+	for _, doc := range docs {
+		size := estimateSize(doc)
+		if size <= hook.sizeWarnings.config.Bytes {
+			continue
+		}
+		site := "unknown"
+		if frame, ok := hook.callerFrame(entry); ok {
+			site = frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if hook.sizeWarnings.record(site) {
+			hook.warn(fmt.Sprintf("Document of about %d bytes logged at %s exceeds %d bytes",
+				size, site, hook.sizeWarnings.config.Bytes))
+		}
+		return
+	}
+}
+
+// estimateSize estimates the JSON size
+// of v without encoding common types
+func estimateSize(v interface{}) int {
+	switch v := v.(type) {
+	case nil:
+		return 4
+	case string:
+		return len(v) + 2
+	case bool:
+		return 5
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return 8
+	case error:
+		return len(v.Error()) + 2
+	case json.RawMessage:
+		return len(v)
+	case []byte:
+		return len(v)*4/3 + 4
+	case map[string]interface{}:
+		return estimateFields(v)
+	case logrus.Fields:
+		return estimateFields(v)
+	case []interface{}:
+		n := 2
+		for _, e := range v {
+			n += estimateSize(e) + 1
+		}
+		return n
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+func estimateFields(m map[string]interface{}) int {
+	n := 2
+	for k, e := range m {
+		n += len(k) + 4 + estimateSize(e)
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package elogrus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestSizeWarnings(t *testing.T) {
+	logger := logrus.New()
+	rec := &recordingHook{}
+	logger.Hooks.Add(rec)
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithInternalLogger(logger),
+		WithSizeWarnings(SizeWarningConfig{Bytes: 1024}),
+	)
+
+	payload := strings.Repeat("x", 2048)
+	for i := 0; i < 3; i++ {
+		hook.Fire(&logrus.Entry{Message: "response", Data: logrus.Fields{"body": payload}})
+	}
+	hook.Fire(&logrus.Entry{Message: "small", Data: logrus.Fields{}})
+
+	if len(rec.entries) != 1 || !strings.Contains(rec.entries[0].Message, "sizewarn_test.go:") {
+		t.Fatalf("expected one warning naming the call site, got %v", rec.entries)
+	}
+	sites := hook.Stats().Oversized
+	if len(sites) != 1 {
+		t.Fatalf("expected one call site, got %v", sites)
+	}
+	for _, n := range sites {
+		if n != 3 {
+			t.Errorf("expected 3 oversized documents, got %d", n)
+		}
+	}
+}
+
+func TestEstimateSize(t *testing.T) {
+	doc := map[string]interface{}{"Message": "hello", "Data": logrus.Fields{"n": 1, "ok": true}}
+	if n := estimateSize(doc); n < 40 || n > 60 {
+		t.Errorf("expected about 45 bytes, got %d", n)
+	}
+}
@@ -0,0 +1,96 @@
+package elogrus
+
+import (
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// queuedEntryBytes estimates the memory
+// of an entry waiting in the async queue
+const queuedEntryBytes = 1 << 10
+
+// Sizing holds the defaults of async mode used
+// when AsyncConfig leaves them zero, its own
+// zero fields fall back to DefaultSizing
+type Sizing struct {
+	AsyncWorkers int
+	AsyncBuffer  int
+}
+
+// DefaultSizing is the heuristic sizing the async
+// worker pool and queue for procs (GOMAXPROCS) and
+// memory bytes, 0 when unknown: a worker per two
+// procs up to 8, and 1000 entries per worker, the
+// queue using at most 1/64 of the memory
+func DefaultSizing(procs int, memory uint64) Sizing {
+	workers := procs / 2
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > 8 {
+		workers = 8
+	}
+	buffer := 1000 * workers
+	if memory > 0 {
+		if max := int(memory / 64 / queuedEntryBytes); max < buffer {
+			buffer = max
+		}
+		if buffer < 100 {
+			buffer = 100
+		}
+	}
+	return Sizing{AsyncWorkers: workers, AsyncBuffer: buffer}
+}
+
+// WithSizing overrides the defaults of async mode,
+// e.g. with DefaultSizing for a pool sized to the
+// machine; it must precede WithAsync, which
+// NewAsyncElasticHook applies last
+func WithSizing(s Sizing) Option {
+	return func(hook *ElasticHook) {
+		hook.sizing = &s
+	}
+}
+
+// defaultSizing returns the sizing of the hook:
+// a single worker, keeping entries in order, with
+// a buffer for the memory limit, unless WithSizing
+// overrides it
+func (hook *ElasticHook) defaultSizing() Sizing {
+	if hook.sizing == nil {
+		return DefaultSizing(1, memoryLimit())
+	}
+	s := *hook.sizing
+	d := DefaultSizing(runtime.GOMAXPROCS(0), memoryLimit())
+	if s.AsyncWorkers <= 0 {
+		s.AsyncWorkers = d.AsyncWorkers
+	}
+	if s.AsyncBuffer <= 0 {
+		s.AsyncBuffer = d.AsyncBuffer
+	}
+	return s
+}
+
+// memoryLimit returns the cgroup memory limit
+// of the process, 0 when there is none
+func memoryLimit() uint64 {
+	for _, path := range []string{
+		"/sys/fs/cgroup/memory.max",
+		"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+	} {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		limit, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		// "max" or the v1 value
+		// standing for no limit
+		if err != nil || limit >= 1<<62 {
+			return 0
+		}
+		return limit
+	}
+	return 0
+}
@@ -0,0 +1,171 @@
+package elogrus
+
+import (
+	"sync"
+	"time"
+)
+
+// sloBuckets is the number of
+// buckets of the rolling window
+const sloBuckets = 60
+
+// SLOConfig sets the delivery objectives,
+// zero values take the defaults
+type SLOConfig struct {
+	// Success is the target ratio of
+	// entries delivered, default 0.999
+	Success float64
+	// Latency is the target time from Fire to
+	// delivery, default 5 seconds, which the
+	// ratio LatencyTarget of deliveries meet,
+	// default 0.99
+	Latency       time.Duration
+	LatencyTarget float64
+	// Window is the rolling
+	// window, default 1 hour
+	Window time.Duration
+}
+
+// SLOStats is the compliance
+// over the rolling window
+type SLOStats struct {
+	// Total counts the outcomes, Delivered the
+	// entries delivered and Fast those within
+	// the latency target
+	Total     int64
+	Delivered int64
+	Fast      int64
+	// SuccessRatio and LatencyRatio
+	// are 1 without deliveries
+	SuccessRatio float64
+	LatencyRatio float64
+	// ErrorBudget and LatencyBudget are the
+	// parts of the budgets left, 1 untouched,
+	// negative when the objective is missed
+	ErrorBudget   float64
+	LatencyBudget float64
+}
+
+// SLOObserver is an optional extension of
+// Observer, it is called with the compliance
+// whenever a bucket of the window is closed
+type SLOObserver interface {
+	SLOReported(SLOStats)
+}
+
+// WithSLO tracks the delivery success ratio and the
+// share of deliveries within the latency target
+// over a rolling window, with the error budgets
+// left, in Stats and for SLOObserver. Entries
+// rejected by WithStrictFields do not count.
+func WithSLO(config SLOConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Success <= 0 || config.Success >= 1 {
+			config.Success = 0.999
+		}
+		if config.Latency <= 0 {
+			config.Latency = 5 * time.Second
+		}
+		if config.LatencyTarget <= 0 || config.LatencyTarget >= 1 {
+			config.LatencyTarget = 0.99
+		}
+		if config.Window <= 0 {
+			config.Window = time.Hour
+		}
+		hook.slo = &sloTracker{
+			config: config,
+			width:  int64(config.Window / sloBuckets),
+			now:    time.Now,
+		}
+		if hook.slo.width <= 0 {
+			hook.slo.width = 1
+		}
+	}
+}
+
+type sloBucket struct {
+	// slot is the index of the bucket
+	// since the epoch, in widths
+	slot      int64
+	total     int64
+	delivered int64
+	fast      int64
+}
+
+type sloTracker struct {
+	mu      sync.Mutex
+	config  SLOConfig
+	width   int64
+	buckets [sloBuckets]sloBucket
+	now     func() time.Time
+}
+
+// record counts an outcome delivered after latency,
+// it returns the compliance when a bucket closed
+func (t *sloTracker) record(err error, latency time.Duration) (SLOStats, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	slot := t.now().UnixNano() / t.width
+	b := &t.buckets[slot%sloBuckets]
+	closed := b.slot != slot
+	var s SLOStats
+	if closed {
+		// the previous bucket
+		// is complete
+		s = t.stats(slot - 1)
+		*b = sloBucket{slot: slot}
+	}
+	b.total++
+	if err == nil {
+		b.delivered++
+		if latency <= t.config.Latency {
+			b.fast++
+		}
+	}
+	return s, closed
+}
+
+func (t *sloTracker) snapshot() SLOStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats(t.now().UnixNano() / t.width)
+}
+
+// stats sums the buckets of the
+// window ending with slot
+func (t *sloTracker) stats(slot int64) SLOStats {
+	var s SLOStats
+	for _, b := range t.buckets {
+		if b.slot <= slot && b.slot > slot-sloBuckets {
+			s.Total += b.total
+			s.Delivered += b.delivered
+			s.Fast += b.fast
+		}
+	}
+	s.SuccessRatio, s.ErrorBudget = compliance(s.Delivered, s.Total, t.config.Success)
+	s.LatencyRatio, s.LatencyBudget = compliance(s.Fast, s.Delivered, t.config.LatencyTarget)
+	return s
+}
+
+// compliance returns the ratio of good
+// events and the error budget left
+func compliance(good, total int64, target float64) (float64, float64) {
+	if total == 0 {
+		return 1, 1
+	}
+	ratio := float64(good) / float64(total)
+	return ratio, 1 - (1-ratio)/(1-target)
+}
+
+// recordSLO counts the outcome
+// of an entry fired at fired
+func (hook *ElasticHook) recordSLO(err error, fired time.Time) {
+	t := hook.root().slo
+	if t == nil || fired.IsZero() {
+		return
+	}
+	s, closed := t.record(err, time.Since(fired))
+	if o, ok := hook.observer.(SLOObserver); ok && closed {
+		o.SLOReported(s)
+	}
+}
@@ -0,0 +1,62 @@
+package elogrus
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type sloObserver struct {
+	reports []SLOStats
+}
+
+func (o *sloObserver) Delivered(labels map[string]string, err error) {}
+
+func (o *sloObserver) SLOReported(s SLOStats) {
+	o.reports = append(o.reports, s)
+}
+
+func TestSLO(t *testing.T) {
+	obs := &sloObserver{}
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithObserver(obs),
+		WithSLO(SLOConfig{Success: 0.9, Latency: time.Second, LatencyTarget: 0.5, Window: time.Minute}),
+	)
+	now := time.Unix(1700000000, 0)
+	hook.slo.now = func() time.Time { return now }
+
+	fired := time.Now()
+	for i := 0; i < 8; i++ {
+		hook.recordSLO(nil, fired)
+	}
+	hook.recordSLO(nil, fired.Add(-2*time.Second))
+	hook.recordSLO(errors.New("unavailable"), fired)
+	hook.recordSLO(nil, time.Time{})
+
+	s := hook.Stats().SLO
+	if s.Total != 10 || s.Delivered != 9 || s.Fast != 8 {
+		t.Fatalf("unexpected counts %+v", s)
+	}
+	// 10% failed is the whole budget of 0.9
+	if s.SuccessRatio != 0.9 || math.Abs(s.ErrorBudget) > 1e-9 {
+		t.Errorf("unexpected success %v, budget %v", s.SuccessRatio, s.ErrorBudget)
+	}
+	if math.Abs(s.LatencyBudget-(1-(1.0/9)/0.5)) > 1e-9 {
+		t.Errorf("unexpected latency budget %v", s.LatencyBudget)
+	}
+
+	// the next bucket reports the window,
+	// which forgets the buckets left behind
+	now = now.Add(time.Second)
+	hook.recordSLO(nil, fired)
+	if n := len(obs.reports); n != 2 || obs.reports[1].Total != 10 {
+		t.Errorf("expected the window to be reported, got %+v", obs.reports)
+	}
+	now = now.Add(2 * time.Minute)
+	if s := hook.Stats().SLO; s.Total != 0 || s.ErrorBudget != 1 {
+		t.Errorf("expected an empty window, got %+v", s)
+	}
+}
@@ -0,0 +1,358 @@
+package elogrus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// SpoolConfig configures the disk spool,
+// zero values fall back to the defaults
+type SpoolConfig struct {
+	// Path of the spool file
+	Path string
+	// MaxBytes bounds the file, further documents
+	// are dropped, default 100 MB
+	MaxBytes int64
+	// ReplayInterval is the period in which spooled
+	// documents are resent, default 30 seconds
+	ReplayInterval time.Duration
+	// Ring makes the spool a memory mapped file
+	// of MaxBytes, overwriting the oldest documents
+	// when full instead of dropping new ones
+	Ring bool
+}
+
+// WithDiskSpool appends documents failing because the
+// cluster is unreachable or overloaded to a local file,
+// and resends them in the background once it is back.
+// Documents rejected for other reasons are not spooled.
+func WithDiskSpool(config SpoolConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.MaxBytes <= 0 {
+			config.MaxBytes = 100 << 20
+		}
+		if config.ReplayInterval <= 0 {
+			config.ReplayInterval = 30 * time.Second
+		}
+		hook.spool = &spool{config: config}
+	}
+}
+
+// SpoolDropped returns the number of documents
+// dropped, or overwritten in ring mode, because
+// the spool file was full
+func (hook *ElasticHook) SpoolDropped() int64 {
+	if hook.spool == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&hook.spool.dropped)
+}
+
+type spool struct {
+	// dropped is first to be 64-bit
+	// aligned for atomic access
+	dropped int64
+	config  SpoolConfig
+	mu      sync.Mutex
+	// ring is set with config.Ring
+	// and nil after close
+	ring   *ring
+	closed bool
+}
+
+// open maps the ring file
+func (s *spool) open() error {
+	if !s.config.Ring {
+		return nil
+	}
+	if s.config.MaxBytes <= ringHeader {
+		return ErrRingUnsupported
+	}
+	r, err := mapRing(s.config.Path, int(s.config.MaxBytes))
+	if err != nil {
+		return err
+	}
+	s.ring = r
+	return nil
+}
+
+// close unmaps the ring file
+func (s *spool) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ring == nil {
+		return nil
+	}
+	r := s.ring
+	s.ring, s.closed = nil, true
+	return r.unmap()
+}
+
+// spoolRecord is a line of the spool file, with
+// what the document is written with on replay
+type spoolRecord struct {
+	Index    string `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Pipeline string `json:"pipeline,omitempty"`
+	Routing  string `json:"routing,omitempty"`
+	// Create writes with op_type create, as
+	// documents with ID are written too
+	Create bool            `json:"create,omitempty"`
+	Level  uint32          `json:"level,omitempty"`
+	Doc    json.RawMessage `json:"doc"`
+}
+
+// forwarded returns the
+// record for a Forwarder
+func (r spoolRecord) forwarded() ForwardedDocument {
+	return ForwardedDocument{Index: r.Index, ID: r.ID, Pipeline: r.Pipeline, Routing: r.Routing, Create: r.Create, Body: r.Doc}
+}
+
+// spoolDoc spools doc as r when err indicates
+// an unavailable cluster, it reports whether
+// the document was spooled
+func (hook *ElasticHook) spoolDoc(r spoolRecord, doc interface{}, err error) bool {
+	if hook.spool == nil || !isClusterFailure(err) || err == ErrDropped {
+		return false
+	}
+	raw, merr := json.Marshal(doc)
+	if merr != nil {
+		return false
+	}
+	r.Doc = raw
+	line, merr := json.Marshal(r)
+	if merr != nil {
+		return false
+	}
+	if werr := hook.spool.append(append(line, '\n')); werr != nil {
+		hook.reportError(werr)
+		return false
+	}
+	return true
+}
+
+// append writes lines to the
+// file, unless it is full
+func (s *spool) append(lines []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrSpoolClosed
+	}
+	if s.ring != nil {
+		atomic.AddInt64(&s.dropped, int64(s.ring.append(lines)))
+		return nil
+	}
+	f, err := os.OpenFile(s.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	if replay, err := os.Stat(s.replayPath()); err == nil {
+		size += replay.Size()
+	}
+	if size+int64(len(lines)) > s.config.MaxBytes {
+		atomic.AddInt64(&s.dropped, int64(bytes.Count(lines, []byte{'\n'})))
+		return nil
+	}
+	_, err = f.Write(lines)
+	return err
+}
+
+// replayPath is the file the lines being
+// replayed are kept in until acknowledged
+func (s *spool) replayPath() string {
+	return s.config.Path + ".replay"
+}
+
+// take returns the spooled lines to replay, they
+// stay on disk until acknowledged with ack. The
+// file is renamed to the replay file, which is
+// replayed first while it exists, so lines left
+// by a crash or a failure stay ahead of newer ones.
+func (s *spool) take() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil
+	}
+	if s.ring != nil {
+		return s.ring.peek(), nil
+	}
+	data, err := ioutil.ReadFile(s.replayPath())
+	if os.IsNotExist(err) {
+		if err := os.Rename(s.config.Path, s.replayPath()); os.IsNotExist(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		data, err = ioutil.ReadFile(s.replayPath())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, os.Remove(s.replayPath())
+	}
+	return data, nil
+}
+
+// ack removes the first n bytes of
+// data, as returned by take
+func (s *spool) ack(data []byte, n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ring != nil {
+		s.ring.ack(n)
+		return nil
+	}
+	if s.closed || n == 0 {
+		return nil
+	}
+	if n >= len(data) {
+		return os.Remove(s.replayPath())
+	}
+	// written aside and renamed, so a crash
+	// leaves either the old or the new lines
+	tmp := s.replayPath() + ".tmp"
+	if err := ioutil.WriteFile(tmp, data[n:], 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.replayPath())
+}
+
+// closeSpool unmaps the ring file, documents
+// failing afterwards are not spooled
+func (hook *ElasticHook) closeSpool() {
+	if hook.spool == nil {
+		return
+	}
+	if err := hook.spool.close(); err != nil {
+		hook.reportError(err)
+	}
+}
+
+func (hook *ElasticHook) runSpool() {
+	for {
+		timer := time.NewTimer(hook.spool.config.ReplayInterval)
+		select {
+		case <-timer.C:
+		case <-hook.quit:
+			timer.Stop()
+			return
+		}
+		if err := hook.replaySpool(); err != nil {
+			hook.reportError(err)
+		}
+	}
+}
+
+// replaySpool resends the spooled documents in
+// order, those left after a cluster failure stay
+// spooled ahead of newer ones; rejected documents
+// go to the fallbacks and the discard handlers,
+// so they do not hold up the others
+func (hook *ElasticHook) replaySpool() error {
+	data, err := hook.spool.take()
+	if err != nil || data == nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	offset := 0
+	replay := func(client *elastic.Client) error {
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			var r spoolRecord
+			if err := json.Unmarshal(line, &r); err != nil {
+				// corrupt lines are dropped
+				offset += len(line) + 1
+				continue
+			}
+			err := hook.resend(client, r)
+			if isClusterFailure(err) || errors.Is(err, context.Canceled) {
+				return err
+			}
+			level := logrus.Level(r.Level)
+			if err != nil {
+				hook.fallback(level, r.Doc)
+				hook.discard(r.Doc, err)
+				hook.lost(level, 1)
+				hook.reportError(fmt.Errorf("Spooled document rejected: %w", err))
+			} else {
+				hook.shipped(r.Index, len(r.Doc))
+			}
+			offset += len(line) + 1
+		}
+		return nil
+	}
+	if hook.forwarder != nil {
+		err = replay(nil)
+	} else {
+		err = hook.do(replay)
+	}
+	if aerr := hook.spool.ack(data, offset); aerr != nil {
+		return aerr
+	}
+	return err
+}
+
+// resend writes a spooled document, through
+// the forwarder of forwarding hooks
+func (hook *ElasticHook) resend(client *elastic.Client, r spoolRecord) error {
+	ctx, cancel := hook.dataContext()
+	defer cancel()
+	if hook.forwarder != nil {
+		errs, err := hook.forwarder.Forward(ctx, []ForwardedDocument{r.forwarded()})
+		hook.available(err)
+		if len(errs) == 1 && errs[0] != nil {
+			return errs[0]
+		}
+		return err
+	}
+	if err := hook.ensureRouted(client, r.Index); err != nil {
+		return err
+	}
+	create := r.ID != "" || r.Create
+	var err error
+	if r.Pipeline != "" {
+		err = hook.indexPipelined(ctx, client, r.Index, r.Routing, r.Pipeline, r.Doc, r.ID, create)
+	} else {
+		req := client.Index().
+			Index(r.Index).
+			Type(hook.docType()).
+			BodyString(string(r.Doc))
+		if r.ID != "" {
+			req.Id(r.ID)
+		}
+		if r.Routing != "" {
+			req.Routing(r.Routing)
+		}
+		if create {
+			req.OpType("create")
+		}
+		_, err = req.DoC(ctx)
+	}
+	if isStatus(err, 409) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestSpool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool.jsonl")
+	hook := &ElasticHook{}
+	WithDiskSpool(SpoolConfig{Path: path, MaxBytes: 80})(hook)
+	doc := map[string]string{"Message": "cluster down"}
+
+	if hook.spoolDoc(spoolRecord{Index: "test"}, doc, &elastic.Error{Status: 400}) {
+		t.Error("rejected documents must not be spooled")
+	}
+	if !hook.spoolDoc(spoolRecord{Index: "test"}, doc, &elastic.Error{Status: 503}) {
+		t.Fatal("expected the document to be spooled")
+	}
+	data, _ := ioutil.ReadFile(path)
+	if string(data) != `{"index":"test","doc":{"Message":"cluster down"}}`+"\n" {
+		t.Errorf("unexpected spool file %q", data)
+	}
+
+	hook.spoolDoc(spoolRecord{Index: "test"}, doc, ErrBreakerOpen)
+	if hook.SpoolDropped() != 1 {
+		t.Errorf("expected the full spool to drop the document, got %d dropped", hook.SpoolDropped())
+	}
+
+	taken, err := hook.spool.take()
+	if err != nil || strings.Count(string(taken), "\n") != 1 {
+		t.Errorf("unexpected spooled lines %q: %v", taken, err)
+	}
+	if rest, _ := ioutil.ReadFile(path); len(rest) != 0 {
+		t.Error("expected the spool to be emptied")
+	}
+}
+
+func TestSpoolOption(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithDiskSpool(SpoolConfig{Path: filepath.Join(t.TempDir(), "spool.jsonl")}))
+	defer hook.Close()
+	if s := hook.Options().Spool; s == nil || s.MaxBytes != 100<<20 {
+		t.Errorf("unexpected spool options %+v", s)
+	}
+}
+
+// rejectingForwarder rejects the documents
+// with ID bad, and all while down
+type rejectingForwarder struct {
+	mu   sync.Mutex
+	down bool
+	docs []ForwardedDocument
+}
+
+func (f *rejectingForwarder) Forward(ctx context.Context, docs []ForwardedDocument) ([]error, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return nil, &elastic.Error{Status: 503}
+	}
+	errs := make([]error, len(docs))
+	var failed error
+	for i, doc := range docs {
+		if doc.ID == "bad" {
+			errs[i] = &elastic.Error{Status: 400}
+			failed = errs[i]
+			continue
+		}
+		f.docs = append(f.docs, doc)
+	}
+	return errs, failed
+}
+
+func TestSpoolReplay(t *testing.T) {
+	fwd := &rejectingForwarder{}
+	var discarded []string
+	hook, err := NewForwardingHook(fwd, "localhost", logrus.DebugLevel, "test",
+		WithDiskSpool(SpoolConfig{Path: filepath.Join(t.TempDir(), "spool.jsonl"), ReplayInterval: time.Hour}),
+		WithOnDiscard(func(doc json.RawMessage, err error) {
+			discarded = append(discarded, string(doc))
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+
+	unavailable := &elastic.Error{Status: 503}
+	hook.spoolDoc(spoolRecord{Index: "test", ID: "bad"}, map[string]string{"Message": "rejected"}, unavailable)
+	hook.spoolDoc(spoolRecord{Index: "test", ID: "1", Pipeline: "geoip", Routing: "checkout", Level: uint32(logrus.WarnLevel)}, map[string]string{"Message": "kept"}, unavailable)
+
+	fwd.down = true
+	if err := hook.replaySpool(); err == nil {
+		t.Fatal("expected the replay to fail while the cluster is down")
+	}
+	fwd.down = false
+	if err := hook.replaySpool(); err != nil {
+		t.Fatal(err)
+	}
+	if len(discarded) != 1 || discarded[0] != `{"Message":"rejected"}` {
+		t.Errorf("expected the rejected document to be discarded, got %q", discarded)
+	}
+	if len(fwd.docs) != 1 {
+		t.Fatalf("expected the other document to be delivered, got %+v", fwd.docs)
+	}
+	if d := fwd.docs[0]; d.ID != "1" || d.Pipeline != "geoip" || d.Routing != "checkout" || string(d.Body) != `{"Message":"kept"}` {
+		t.Errorf("expected the spooled ID, pipeline and routing, got %+v", d)
+	}
+	if taken, _ := hook.spool.take(); taken != nil {
+		t.Errorf("expected an empty spool, got %q", taken)
+	}
+}
+
+func TestSpoolKeepsReplayedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool.jsonl")
+	s := &spool{config: SpoolConfig{Path: path, MaxBytes: 1 << 20}}
+	s.append([]byte("a\nb\n"))
+	data, err := s.take()
+	if err != nil || string(data) != "a\nb\n" {
+		t.Fatalf("unexpected lines %q: %v", data, err)
+	}
+	s.append([]byte("c\n"))
+
+	// a crash before the ack replays the lines again
+	restarted := &spool{config: s.config}
+	if again, _ := restarted.take(); string(again) != "a\nb\n" {
+		t.Errorf("expected the unacknowledged lines to be kept, got %q", again)
+	}
+	if err := s.ack(data, 2); err != nil {
+		t.Fatal(err)
+	}
+	if rest, _ := s.take(); string(rest) != "b\n" {
+		t.Errorf("expected the rest to be replayed before newer lines, got %q", rest)
+	}
+	s.ack([]byte("b\n"), 2)
+	if newer, _ := s.take(); string(newer) != "c\n" {
+		t.Errorf("expected the newer line, got %q", newer)
+	}
+}
@@ -0,0 +1,149 @@
+package elogrus
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IndexStats counts what was
+// shipped to a single index
+type IndexStats struct {
+	// Documents accepted by ElasticSearch
+	Documents uint64
+	// Bytes is the JSON size
+	// of those documents
+	Bytes uint64
+}
+
+// Stats is a snapshot of
+// the hook's counters
+type Stats struct {
+	// Sent, Failed and Dropped count the
+	// document outcomes, Retries the retried
+	// requests
+	Sent    int64
+	Failed  int64
+	Dropped int64
+	Retries int64
+	// QueueDepth is the number of documents waiting
+	// in the bulk queue, or entries in the async one
+	QueueDepth int
+	// Flushes counts bulk requests,
+	// FlushLatency is their mean duration
+	Flushes      int64
+	FlushLatency time.Duration
+	// ClusterHealth is the status last polled
+	// and ThrottleRate the documents per second
+	// shipped, 0 when not throttled, with
+	// WithHealthThrottle
+	ClusterHealth string
+	ThrottleRate  float64
+	// LocalOnly is set while entries are
+	// only written locally, see WithLocalOnly
+	LocalOnly bool
+	// SLO is the delivery compliance
+	// over the window, with WithSLO
+	SLO *SLOStats
+	// SchemaViolations counts the fields
+	// breaking the schema, see WithSchema
+	SchemaViolations int64
+	// Oversized counts the documents over the
+	// size warning threshold per call site
+	Oversized map[string]int64
+	// Indices maps index names
+	// to what was shipped there
+	Indices map[string]IndexStats
+	// Batches holds the outcomes of the
+	// latest bulk batches, oldest first,
+	// with WithBatchIDs
+	Batches []BatchStats
+}
+
+// Stats returns the delivery counters and the
+// documents and bytes shipped per index since
+// the hook was created, e.g. to attribute
+// indexing load and storage to services
+func (hook *ElasticHook) Stats() Stats {
+	root := hook.root()
+	s := Stats{
+		Sent:    atomic.LoadInt64(&root.sent),
+		Failed:  atomic.LoadInt64(&root.failed),
+		Dropped: atomic.LoadInt64(&root.dropped),
+		Retries: atomic.LoadInt64(&root.retries),
+		Flushes: atomic.LoadInt64(&root.flushes),
+		Indices: root.volume.snapshot(),
+
+		SchemaViolations: atomic.LoadInt64(&root.schemaViolations),
+	}
+	if s.Flushes > 0 {
+		s.FlushLatency = time.Duration(atomic.LoadInt64(&root.flushNanos) / s.Flushes)
+	}
+	s.QueueDepth = hook.QueueDepth()
+	if root.throttle != nil {
+		s.ClusterHealth, s.ThrottleRate = root.throttle.snapshot()
+	}
+	if root.localOnly != nil {
+		root.localOnly.mu.Lock()
+		s.LocalOnly = root.localOnly.active
+		root.localOnly.mu.Unlock()
+	}
+	if root.slo != nil {
+		slo := root.slo.snapshot()
+		s.SLO = &slo
+	}
+	if root.sizeWarnings != nil {
+		s.Oversized = root.sizeWarnings.snapshot()
+	}
+	if root.bulk != nil && root.batchIDs {
+		s.Batches = root.bulk.batches.snapshot()
+	}
+	return s
+}
+
+// VolumeObserver is an optional extension of
+// Observer, it is called with the size of
+// every document accepted by ElasticSearch
+type VolumeObserver interface {
+	Shipped(index string, bytes int)
+}
+
+type volume struct {
+	mu      sync.Mutex
+	indices map[string]IndexStats
+}
+
+func (v *volume) add(index string, bytes int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.indices == nil {
+		v.indices = map[string]IndexStats{}
+	}
+	s := v.indices[index]
+	s.Documents++
+	s.Bytes += uint64(bytes)
+	v.indices[index] = s
+}
+
+func (v *volume) snapshot() map[string]IndexStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	indices := make(map[string]IndexStats, len(v.indices))
+	for k, s := range v.indices {
+		indices[k] = s
+	}
+	return indices
+}
+
+// shipped accounts a document
+// accepted by ElasticSearch
+func (hook *ElasticHook) shipped(index string, bytes int) {
+	atomic.StoreInt64(&hook.root().lastFlush, time.Now().UnixNano())
+	hook.root().volume.add(index, bytes)
+	if hook.budget != nil {
+		hook.budget.add(bytes)
+	}
+	if o, ok := hook.observer.(VolumeObserver); ok {
+		o.Shipped(index, bytes)
+	}
+}
@@ -0,0 +1,85 @@
+package elogrus
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+type volumeObserver struct {
+	countingObserver
+	mu    sync.Mutex
+	bytes map[string]int
+}
+
+func (o *volumeObserver) Shipped(index string, bytes int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.bytes[index] += bytes
+}
+
+func TestStatsPerIndex(t *testing.T) {
+	obs := &volumeObserver{bytes: map[string]int{}}
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithObserver(obs))
+
+	hook.shipped("billing", 100)
+	hook.shipped("billing", 50)
+	hook.shipped("search", 10)
+
+	stats := hook.Stats()
+	if s := stats.Indices["billing"]; s.Documents != 2 || s.Bytes != 150 {
+		t.Errorf("unexpected billing stats %+v", s)
+	}
+	if s := stats.Indices["search"]; s.Documents != 1 || s.Bytes != 10 {
+		t.Errorf("unexpected search stats %+v", s)
+	}
+	if obs.bytes["billing"] != 150 {
+		t.Errorf("expected observer to see 150 bytes, got %d", obs.bytes["billing"])
+	}
+
+	stats.Indices["billing"] = IndexStats{}
+	if hook.Stats().Indices["billing"].Documents != 2 {
+		t.Error("Stats should return a copy")
+	}
+}
+
+type operationObserver struct {
+	countingObserver
+	retries int
+	flushed []int
+}
+
+func (o *operationObserver) Retried(err error) {
+	o.retries++
+}
+
+func (o *operationObserver) Flushed(documents int, latency time.Duration, err error) {
+	o.flushed = append(o.flushed, documents)
+}
+
+func TestStatsCounters(t *testing.T) {
+	obs := &operationObserver{countingObserver: *newCountingObserver()}
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithObserver(obs))
+
+	hook.observe(nil, nil)
+	hook.observe(nil, nil)
+	hook.observe(nil, ErrDropped)
+	hook.observe(nil, fmt.Errorf("Rejected"))
+	hook.retried(fmt.Errorf("Unavailable"))
+	hook.flushed(10, 2*time.Millisecond, nil)
+	hook.flushed(5, 4*time.Millisecond, nil)
+
+	s := hook.Stats()
+	if s.Sent != 2 || s.Dropped != 1 || s.Failed != 1 || s.Retries != 1 {
+		t.Errorf("unexpected counters %+v", s)
+	}
+	if s.Flushes != 2 || s.FlushLatency != 3*time.Millisecond {
+		t.Errorf("expected 2 flushes of 3ms, got %d of %s", s.Flushes, s.FlushLatency)
+	}
+	if obs.retries != 1 || len(obs.flushed) != 2 || obs.flushed[0] != 10 {
+		t.Errorf("observer not called, %d retries %v flushes", obs.retries, obs.flushed)
+	}
+}
@@ -0,0 +1,67 @@
+package elogrus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// SchemaError is the error of an entry rejected
+// for fields missing from the strict schema
+type SchemaError struct {
+	Fields []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("Undeclared fields: %s", strings.Join(e.Fields, ", "))
+}
+
+// WithStrictFields rejects entries with data fields
+// other than fields before they are sent, so no
+// mapping is created for them. Their documents go
+// to the fallbacks and the discard and error
+// handlers with a SchemaError naming the fields.
+// Static fields and classifier tags are exempt.
+func WithStrictFields(fields ...string) Option {
+	return func(hook *ElasticHook) {
+		if hook.strictFields == nil {
+			hook.strictFields = map[string]bool{}
+		}
+		for _, f := range fields {
+			hook.strictFields[f] = true
+		}
+	}
+}
+
+// checkSchema returns a SchemaError
+// when entry has undeclared fields
+func (hook *ElasticHook) checkSchema(entry *logrus.Entry) error {
+	if hook.strictFields == nil {
+		return nil
+	}
+	var undeclared []string
+	for k := range entry.Data {
+		if !hook.strictFields[k] {
+			undeclared = append(undeclared, k)
+		}
+	}
+	if len(undeclared) == 0 {
+		return nil
+	}
+	sort.Strings(undeclared)
+	return &SchemaError{Fields: undeclared}
+}
+
+// reject hands the documents of an entry
+// failing the schema to the dead letter
+// destinations
+func (hook *ElasticHook) reject(entry *logrus.Entry, id uint64, err error) {
+	for _, doc := range hook.stampedDocuments(entry, sample{}) {
+		hook.fallback(entry.Level, doc)
+		hook.discard(doc, err)
+	}
+	hook.observe(hook.labels(entry, hook.indexFor(entry)), err)
+	hook.traceOutcome(id, err)
+}
@@ -0,0 +1,39 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestStrictFields(t *testing.T) {
+	var rejected []error
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithStaticFields(logrus.Fields{"env": "prod"}),
+		WithStrictFields("user", "request_id"),
+		WithOnDiscard(func(doc json.RawMessage, err error) {
+			rejected = append(rejected, err)
+		}),
+	)
+
+	if err := hook.Fire(&logrus.Entry{Message: "ok", Data: logrus.Fields{"user": "bob"}}); err != nil {
+		t.Fatalf("expected declared fields to pass, got %v", err)
+	}
+	err := hook.Fire(&logrus.Entry{Message: "bad", Data: logrus.Fields{"user": "bob", "payload": "x", "body": "y"}})
+	if err == nil || err.Error() != "Undeclared fields: body, payload" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if n := len(hook.bulk.take()); n != 1 {
+		t.Errorf("expected one queued document, got %d", n)
+	}
+	if len(rejected) != 1 || rejected[0] != err {
+		t.Errorf("expected the rejected document to be discarded, got %v", rejected)
+	}
+	if s := hook.Stats(); s.Failed != 1 {
+		t.Errorf("expected one failed entry, got %d", s.Failed)
+	}
+}
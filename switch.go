@@ -0,0 +1,82 @@
+package elogrus
+
+import "fmt"
+
+var (
+	// Fired if the alias
+	// cannot be repointed
+	ErrCannotSwitchAlias = fmt.Errorf("Cannot switch alias")
+)
+
+// SwitchIndex creates index with the given body
+// (settings and mappings) and atomically repoints
+// the hook index, which must be an alias, to it.
+// Requests in flight finish before the alias is
+// moved and pending bulk documents are sent after,
+// so no batch is split across the two indices.
+func (hook *ElasticHook) SwitchIndex(index string, body string) error {
+	if hook.writeOnly {
+		return ErrWriteOnly
+	}
+	client, err := hook.getClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := hook.controlContext()
+	defer cancel()
+
+	createIndex, err := client.CreateIndex(index).Body(body).DoC(ctx)
+	if err != nil {
+		return err
+	}
+	if !createIndex.Acknowledged {
+		return ErrCannotCreateIndex
+	}
+	hook.clusterEvent(ClusterEvent{Kind: IndexCreated, Index: index})
+
+	root := hook.root()
+	root.switchMu.Lock()
+	defer root.switchMu.Unlock()
+
+	aliases, err := client.Aliases().Index("_all").DoC(ctx)
+	if err != nil {
+		return err
+	}
+	alias := client.Alias()
+	for _, action := range switchActions(aliases.IndicesByAlias(hook.index), index, hook.index) {
+		if action.remove {
+			alias.Remove(action.index, action.alias)
+		} else {
+			alias.Add(action.index, action.alias)
+		}
+	}
+	result, err := alias.DoC(ctx)
+	if err != nil {
+		return err
+	}
+	if !result.Acknowledged {
+		return ErrCannotSwitchAlias
+	}
+	hook.clusterEvent(ClusterEvent{Kind: AliasSwitched, Index: index, Alias: hook.index})
+	return nil
+}
+
+// aliasAction adds alias to index,
+// or removes it with remove
+type aliasAction struct {
+	remove bool
+	index  string
+	alias  string
+}
+
+// switchActions returns the actions moving alias
+// from the indices holding it to index, at once
+func switchActions(holding []string, index, alias string) []aliasAction {
+	var actions []aliasAction
+	for _, old := range holding {
+		if old != index {
+			actions = append(actions, aliasAction{remove: true, index: old, alias: alias})
+		}
+	}
+	return append(actions, aliasAction{index: index, alias: alias})
+}
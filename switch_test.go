@@ -0,0 +1,31 @@
+package elogrus
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestSwitchActions(t *testing.T) {
+	actions := switchActions([]string{"logs-v1", "logs-v2", "logs-v3"}, "logs-v3", "logs")
+	expected := []aliasAction{
+		{remove: true, index: "logs-v1", alias: "logs"},
+		{remove: true, index: "logs-v2", alias: "logs"},
+		{index: "logs-v3", alias: "logs"},
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("unexpected actions %+v", actions)
+	}
+	if actions := switchActions(nil, "logs-v1", "logs"); !reflect.DeepEqual(actions, []aliasAction{{index: "logs-v1", alias: "logs"}}) {
+		t.Errorf("expected a new alias only to be added, got %+v", actions)
+	}
+}
+
+func TestSwitchIndexWriteOnly(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "logs", WithWriteOnly())
+	defer hook.Close()
+	if err := hook.SwitchIndex("logs-v2", `{}`); err != ErrWriteOnly {
+		t.Errorf("expected ErrWriteOnly, got %v", err)
+	}
+}
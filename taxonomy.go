@@ -0,0 +1,124 @@
+package elogrus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// grpcCodes holds the canonical gRPC code
+// names and their HTTP status, as mapped
+// by grpc-gateway, indexed by code
+var grpcCodes = []struct {
+	name   string
+	status int
+}{
+	{"OK", http.StatusOK},
+	{"CANCELLED", 499},
+	{"UNKNOWN", http.StatusInternalServerError},
+	{"INVALID_ARGUMENT", http.StatusBadRequest},
+	{"DEADLINE_EXCEEDED", http.StatusGatewayTimeout},
+	{"NOT_FOUND", http.StatusNotFound},
+	{"ALREADY_EXISTS", http.StatusConflict},
+	{"PERMISSION_DENIED", http.StatusForbidden},
+	{"RESOURCE_EXHAUSTED", http.StatusTooManyRequests},
+	{"FAILED_PRECONDITION", http.StatusBadRequest},
+	{"ABORTED", http.StatusConflict},
+	{"OUT_OF_RANGE", http.StatusBadRequest},
+	{"UNIMPLEMENTED", http.StatusNotImplemented},
+	{"INTERNAL", http.StatusInternalServerError},
+	{"UNAVAILABLE", http.StatusServiceUnavailable},
+	{"DATA_LOSS", http.StatusInternalServerError},
+	{"UNAUTHENTICATED", http.StatusUnauthorized},
+}
+
+// statusCodes maps HTTP statuses
+// to canonical gRPC code names
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          "INVALID_ARGUMENT",
+	http.StatusUnauthorized:        "UNAUTHENTICATED",
+	http.StatusForbidden:           "PERMISSION_DENIED",
+	http.StatusNotFound:            "NOT_FOUND",
+	http.StatusConflict:            "ALREADY_EXISTS",
+	http.StatusPreconditionFailed:  "FAILED_PRECONDITION",
+	http.StatusTooManyRequests:     "RESOURCE_EXHAUSTED",
+	499:                            "CANCELLED",
+	http.StatusInternalServerError: "INTERNAL",
+	http.StatusNotImplemented:      "UNIMPLEMENTED",
+	http.StatusServiceUnavailable:  "UNAVAILABLE",
+	http.StatusGatewayTimeout:      "DEADLINE_EXCEEDED",
+}
+
+// StatusFields returns the error taxonomy
+// of an HTTP status: error.kind ("client"
+// or "server"), error.code (the canonical
+// gRPC code name) and http.response.status_code.
+// It returns nil for statuses below 400.
+func StatusFields(status int) logrus.Fields {
+	if status < 400 {
+		return nil
+	}
+	code, ok := statusCodes[status]
+	if !ok {
+		code = "UNKNOWN"
+		if status < 500 {
+			code = "FAILED_PRECONDITION"
+		}
+	}
+	return taxonomyFields(code, status)
+}
+
+// GRPCCodeFields returns the error taxonomy of a
+// gRPC code, e.g. uint32(status.Code(err)), like
+// StatusFields. It returns nil for OK.
+func GRPCCodeFields(code uint32) logrus.Fields {
+	if code == 0 {
+		return nil
+	}
+	if int(code) >= len(grpcCodes) {
+		return taxonomyFields("UNKNOWN", http.StatusInternalServerError)
+	}
+	c := grpcCodes[code]
+	return taxonomyFields(c.name, c.status)
+}
+
+// ErrorFields returns the error taxonomy of err,
+// like StatusFields. The status is taken from
+// errors with a StatusCode() int method and
+// ElasticSearch errors, context errors map to
+// CANCELLED and DEADLINE_EXCEEDED, other errors
+// to UNKNOWN. It returns nil for a nil err.
+func ErrorFields(err error) logrus.Fields {
+	if err == nil {
+		return nil
+	}
+	var coder interface{ StatusCode() int }
+	var esErr *elastic.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return GRPCCodeFields(1)
+	case errors.Is(err, context.DeadlineExceeded):
+		return GRPCCodeFields(4)
+	case errors.As(err, &coder) && coder.StatusCode() >= 400:
+		return StatusFields(coder.StatusCode())
+	case errors.As(err, &esErr) && esErr.Status >= 400:
+		return StatusFields(esErr.Status)
+	}
+	return GRPCCodeFields(2)
+}
+
+func taxonomyFields(code string, status int) logrus.Fields {
+	kind := "server"
+	if status < 500 {
+		kind = "client"
+	}
+	return logrus.Fields{
+		"error.kind":                kind,
+		"error.code":                code,
+		"http.response.status_code": status,
+	}
+}
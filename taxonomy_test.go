@@ -0,0 +1,57 @@
+package elogrus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+type statusError int
+
+func (e statusError) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) StatusCode() int { return int(e) }
+
+func TestErrorFields(t *testing.T) {
+	for _, c := range []struct {
+		err    error
+		kind   string
+		code   string
+		status int
+	}{
+		{context.Canceled, "client", "CANCELLED", 499},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), "server", "DEADLINE_EXCEEDED", 504},
+		{statusError(404), "client", "NOT_FOUND", 404},
+		{statusError(418), "client", "FAILED_PRECONDITION", 418},
+		{&elastic.Error{Status: 503}, "server", "UNAVAILABLE", 503},
+		{errors.New("boom"), "server", "UNKNOWN", 500},
+	} {
+		fields := ErrorFields(c.err)
+		if fields["error.kind"] != c.kind || fields["error.code"] != c.code || fields["http.response.status_code"] != c.status {
+			t.Errorf("ErrorFields(%v) = %v", c.err, fields)
+		}
+	}
+	if ErrorFields(nil) != nil {
+		t.Error("expected no fields for nil")
+	}
+}
+
+func TestGRPCCodeFields(t *testing.T) {
+	fields := GRPCCodeFields(16)
+	expected := logrus.Fields{"error.kind": "client", "error.code": "UNAUTHENTICATED", "http.response.status_code": 401}
+	for k, v := range expected {
+		if fields[k] != v {
+			t.Errorf("expected %s %v, got %v", k, v, fields[k])
+		}
+	}
+	if GRPCCodeFields(0) != nil {
+		t.Error("expected no fields for OK")
+	}
+	if GRPCCodeFields(99)["error.code"] != "UNKNOWN" {
+		t.Error("expected unknown codes to map to UNKNOWN")
+	}
+}
@@ -0,0 +1,49 @@
+package elogrus
+
+import "github.com/Sirupsen/logrus"
+
+// TemplateField is the entry field holding
+// the unformatted template of the message
+const TemplateField = "message_template"
+
+// WithMessageTemplate moves the TemplateField of
+// entries to the MessageTemplate field next to
+// Message, so documents can be grouped by template
+// regardless of the formatted values
+func WithMessageTemplate() Option {
+	return func(hook *ElasticHook) {
+		hook.messageTemplate = true
+	}
+}
+
+// Logf logs the formatted message through entry at
+// level, recording format in TemplateField:
+//
+//	elogrus.Logf(log.WithField("service", "billing"), logrus.InfoLevel, "charged %d cents", amount)
+func Logf(entry *logrus.Entry, level logrus.Level, format string, args ...interface{}) {
+	entry = entry.WithField(TemplateField, format)
+	switch level {
+	case logrus.PanicLevel:
+		entry.Panicf(format, args...)
+	case logrus.FatalLevel:
+		entry.Fatalf(format, args...)
+	case logrus.ErrorLevel:
+		entry.Errorf(format, args...)
+	case logrus.WarnLevel:
+		entry.Warnf(format, args...)
+	case logrus.InfoLevel:
+		entry.Infof(format, args...)
+	default:
+		entry.Debugf(format, args...)
+	}
+}
+
+// template returns the message template
+// of entry when the option is set
+func (hook *ElasticHook) template(entry *logrus.Entry) (string, bool) {
+	if !hook.messageTemplate {
+		return "", false
+	}
+	t, ok := entry.Data[TemplateField].(string)
+	return t, ok
+}
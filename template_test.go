@@ -0,0 +1,34 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestMessageTemplate(t *testing.T) {
+	logger := logrus.New()
+	rec := &recordingHook{}
+	logger.Hooks.Add(rec)
+	Logf(logrus.NewEntry(logger).WithField("user", "joe"), logrus.WarnLevel, "user %s locked out", "joe")
+	if len(rec.entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(rec.entries))
+	}
+	entry := rec.entries[0]
+	if entry.Message != "user joe locked out" || entry.Level != logrus.WarnLevel {
+		t.Errorf("unexpected entry %q at %s", entry.Message, entry.Level)
+	}
+
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithMessageTemplate())
+	doc := hook.document(entry)
+	if doc["MessageTemplate"] != "user %s locked out" {
+		t.Errorf("unexpected template %v", doc["MessageTemplate"])
+	}
+	data := doc["Data"].(logrus.Fields)
+	if _, ok := data[TemplateField]; ok || data["user"] != "joe" {
+		t.Errorf("template should move out of Data, got %v", data)
+	}
+	if _, ok := entry.Data[TemplateField]; !ok {
+		t.Error("entry data must not be modified")
+	}
+}
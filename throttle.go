@@ -0,0 +1,197 @@
+package elogrus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// HealthThrottleConfig configures throttling
+// on the cluster health, zero values fall
+// back to the defaults
+type HealthThrottleConfig struct {
+	// Interval between two polls of
+	// _cluster/health, default 30 seconds
+	Interval time.Duration
+	// Rate is the documents per second shipped
+	// while the cluster is yellow, default 1000
+	Rate float64
+	// MinRate is the documents per second shipped
+	// while it is red, and the least rate 429s
+	// halve the rate to, default 10
+	MinRate float64
+}
+
+// WithHealthThrottle reduces the shipping rate while
+// the cluster is unhealthy, protecting shared clusters
+// during incidents: it polls _cluster/health, limits
+// the rate on yellow or red and halves it on every
+// 429. While the cluster is green the rate doubles
+// at every poll, until it is no longer limited.
+func WithHealthThrottle(config HealthThrottleConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Interval <= 0 {
+			config.Interval = 30 * time.Second
+		}
+		if config.Rate <= 0 {
+			config.Rate = 1000
+		}
+		if config.MinRate <= 0 {
+			config.MinRate = 10
+		}
+		if config.MinRate > config.Rate {
+			config.MinRate = config.Rate
+		}
+		hook.throttle = &healthThrottle{config: config}
+	}
+}
+
+type healthThrottle struct {
+	mu     sync.Mutex
+	config HealthThrottleConfig
+	// status is the latest
+	// cluster health
+	status string
+	// limit is the documents per second,
+	// 0 while not throttled
+	limit float64
+	// next is the earliest time
+	// the next request may start
+	next time.Time
+}
+
+// health applies a cluster health status,
+// it returns the limit and whether it changed
+func (t *healthThrottle) health(status string) (float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = status
+	limit := t.limit
+	switch status {
+	case "red":
+		t.limit = t.config.MinRate
+	case "yellow":
+		if t.limit == 0 || t.limit > t.config.Rate {
+			t.limit = t.config.Rate
+		} else {
+			t.limit = math.Min(t.limit*2, t.config.Rate)
+		}
+	case "green":
+		if t.limit *= 2; t.limit >= t.config.Rate {
+			t.limit = 0
+		}
+	}
+	return t.limit, t.limit != limit
+}
+
+// rejected halves the rate after a 429,
+// it returns the new limit
+func (t *healthThrottle) rejected() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.limit == 0 {
+		t.limit = t.config.Rate
+	}
+	t.limit = math.Max(t.limit/2, t.config.MinRate)
+	return t.limit
+}
+
+// delay reserves n documents at now, it
+// returns how long to wait before sending
+func (t *healthThrottle) delay(n int, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.limit == 0 {
+		return 0
+	}
+	if t.next.Before(now) {
+		t.next = now
+	}
+	d := t.next.Sub(now)
+	t.next = t.next.Add(time.Duration(float64(n) / t.limit * float64(time.Second)))
+	return d
+}
+
+func (t *healthThrottle) snapshot() (string, float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status, t.limit
+}
+
+// pace waits until n documents may be
+// shipped under the health throttle
+func (hook *ElasticHook) pace(ctx context.Context, n int) {
+	t := hook.root().throttle
+	if t == nil {
+		return
+	}
+	d := t.delay(n, time.Now())
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// throttled halves the shipping rate
+// when err is a rejection of the cluster
+func (hook *ElasticHook) throttled(err error) {
+	t := hook.root().throttle
+	if t == nil || !isThrottled(err) {
+		return
+	}
+	hook.warn(fmt.Sprintf("ElasticSearch rejected documents, shipping at most %.0f documents per second", t.rejected()))
+}
+
+// runHealthThrottle polls the cluster
+// health until the hook is closed
+func (hook *ElasticHook) runHealthThrottle() {
+	ticker := time.NewTicker(hook.throttle.config.Interval)
+	defer ticker.Stop()
+	for {
+		if err := hook.pollHealth(); err != nil {
+			hook.reportError(fmt.Errorf("Cluster health poll failed: %w", err))
+		}
+		select {
+		case <-ticker.C:
+		case <-hook.quit:
+			return
+		}
+	}
+}
+
+// pollHealth applies the
+// current cluster health
+func (hook *ElasticHook) pollHealth() error {
+	return hook.do(func(client *elastic.Client) error {
+		ctx, cancel := hook.controlContext()
+		defer cancel()
+		resp, err := client.PerformRequestC(ctx, "GET", "/_cluster/health", nil, nil)
+		if err != nil {
+			return err
+		}
+		var health struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(resp.Body, &health); err != nil {
+			return err
+		}
+		limit, changed := hook.throttle.health(health.Status)
+		switch {
+		case changed && limit == 0:
+			hook.warn("ElasticSearch cluster is green, shipping is no longer throttled")
+		case changed && health.Status != "green":
+			hook.warn(fmt.Sprintf("ElasticSearch cluster is %s, shipping at most %.0f documents per second", health.Status, limit))
+		}
+		return nil
+	})
+}
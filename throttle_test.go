@@ -0,0 +1,43 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+func TestHealthThrottle(t *testing.T) {
+	hook := &ElasticHook{}
+	WithHealthThrottle(HealthThrottleConfig{Rate: 100, MinRate: 10})(hook)
+	th := hook.throttle
+
+	for _, step := range []struct {
+		status string
+		limit  float64
+	}{
+		{"green", 0},
+		{"yellow", 100},
+		{"red", 10},
+		{"yellow", 20},
+		{"green", 40},
+		{"green", 80},
+		{"green", 0},
+	} {
+		if limit, _ := th.health(step.status); limit != step.limit {
+			t.Errorf("expected a limit of %v after %s, got %v", step.limit, step.status, limit)
+		}
+	}
+
+	hook.throttled(&elastic.Error{Status: 429})
+	if _, limit := th.snapshot(); limit != 50 {
+		t.Errorf("expected a 429 to halve the rate, got %v", limit)
+	}
+	now := time.Now()
+	if d := th.delay(100, now); d != 0 {
+		t.Errorf("expected the first request to pass, got %v", d)
+	}
+	if d := th.delay(1, now); d != 2*time.Second {
+		t.Errorf("expected to wait for 100 documents at 50/s, got %v", d)
+	}
+}
@@ -0,0 +1,105 @@
+package elogrus
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// TimeoutConfig bounds the requests of the hook,
+// zero durations leave them unbounded
+type TimeoutConfig struct {
+	// Control bounds each management operation,
+	// e.g. creating an index, installing the
+	// templates or retiring old indices
+	Control time.Duration
+	// Data bounds each document write,
+	// a single index or a bulk request
+	Data time.Duration
+}
+
+// WithTimeouts sets separate timeouts for
+// management operations and document writes,
+// so a slow management call on a loaded cluster
+// gives up instead of holding up log delivery
+func WithTimeouts(config TimeoutConfig) Option {
+	return func(hook *ElasticHook) {
+		hook.timeouts = config
+	}
+}
+
+// controlContext returns the context of a
+// management operation, it is cancelled once
+// Close or Shutdown returns
+func (hook *ElasticHook) controlContext() (context.Context, context.CancelFunc) {
+	return withTimeout(hook.controlCtx, hook.timeouts.Control)
+}
+
+// dataContext returns the context of a document
+// write, it is cancelled when Shutdown gives up
+func (hook *ElasticHook) dataContext() (context.Context, context.CancelFunc) {
+	return withTimeout(hook.ctx, hook.timeouts.Data)
+}
+
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// WithRequestTimeout bounds the delivery of each
+// entry, retries included, when documents are sent
+// one entry at a time; in bulk mode see TimeoutConfig
+func WithRequestTimeout(d time.Duration) Option {
+	return func(hook *ElasticHook) {
+		hook.requestTimeout = d
+	}
+}
+
+// WithEntryCancellation aborts synchronous writes
+// when the entry context is cancelled or its deadline
+// passes. By default only its values are used, so the
+// logs of a cancelled request are still shipped.
+func WithEntryCancellation() Option {
+	return func(hook *ElasticHook) {
+		hook.entryCancellation = true
+	}
+}
+
+// detachedContext keeps the values of a
+// context without its deadline and cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// fireContext returns the context delivering entry.
+// In synchronous mode the values of the entry context
+// are used when set, and with WithEntryCancellation
+// its cancellation; the hook context still cancels
+// the write. Queued entries only use the hook
+// context, as the entry context usually ends with
+// the request that logged it.
+func (hook *ElasticHook) fireContext(entry *logrus.Entry) (context.Context, context.CancelFunc) {
+	if entry == nil || entry.Context == nil {
+		return withTimeout(hook.ctx, hook.requestTimeout)
+	}
+	parent := entry.Context
+	if !hook.entryCancellation {
+		parent = detachedContext{parent}
+	}
+	ctx, cancel := withTimeout(internalContext(parent), hook.requestTimeout)
+	go func() {
+		select {
+		case <-hook.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
@@ -0,0 +1,71 @@
+package elogrus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestTimeouts(t *testing.T) {
+	hook := newHook(nil, "localhost", 0, "test", WithTimeouts(TimeoutConfig{Data: time.Minute}))
+
+	ctx, cancel := hook.dataContext()
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("expected a deadline on document writes")
+	}
+	control, cancelControl := hook.controlContext()
+	defer cancelControl()
+	if _, ok := control.Deadline(); ok {
+		t.Error("expected no deadline on management operations")
+	}
+	if !isInternal(ctx) || !isInternal(control) {
+		t.Error("expected the requests to be marked as internal")
+	}
+
+	hook.Close()
+	if control.Err() == nil {
+		t.Error("expected management operations to be cancelled on Close")
+	}
+}
+
+func TestFireContext(t *testing.T) {
+	hook := newHook(nil, "localhost", 0, "test", WithRequestTimeout(time.Minute))
+
+	ctx, cancel := hook.fireContext(nil)
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("expected the request timeout")
+	}
+	cancel()
+
+	entryCtx, cancelEntry := context.WithCancel(context.WithValue(context.Background(), correlationKey{}, "abc"))
+	ctx, cancel = hook.fireContext(&logrus.Entry{Context: entryCtx})
+	defer cancel()
+	if !isInternal(ctx) || ctx.Value(correlationKey{}) != "abc" {
+		t.Error("expected the request to keep the entry values and be marked as internal")
+	}
+	cancelEntry()
+	if ctx.Err() != nil {
+		t.Error("expected the write to outlive the entry context")
+	}
+
+	entryCtx, cancelEntry = context.WithCancel(context.Background())
+	WithEntryCancellation()(hook)
+	ctx, cancel = hook.fireContext(&logrus.Entry{Context: entryCtx})
+	defer cancel()
+	cancelEntry()
+	if ctx.Err() == nil {
+		t.Error("expected cancelling the entry context to abort the write")
+	}
+
+	ctx, cancel = hook.fireContext(&logrus.Entry{Context: context.Background()})
+	defer cancel()
+	hook.cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("expected the hook context to cancel the write")
+	}
+}
@@ -0,0 +1,55 @@
+package elogrus
+
+import (
+	"sync/atomic"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// WithDeliveryTrace gives every entry a delivery ID and
+// logs its lifecycle to the internal logger at debug
+// level: enqueued, batched, sent, retried, acked, failed
+// or dropped, with delivery.id and delivery.stage fields.
+// Meant for diagnosing delivery problems, it needs
+// WithInternalLogger.
+func WithDeliveryTrace() Option {
+	return func(hook *ElasticHook) {
+		hook.deliveryTrace = true
+	}
+}
+
+// deliveryID returns a new delivery
+// ID, 0 when tracing is off
+func (hook *ElasticHook) deliveryID() uint64 {
+	if !hook.deliveryTrace || hook.internalLogger == nil {
+		return 0
+	}
+	return atomic.AddUint64(&hook.root().deliveries, 1)
+}
+
+// trace logs a lifecycle stage
+// of delivery id
+func (hook *ElasticHook) trace(id uint64, stage string, err error) {
+	if id == 0 {
+		return
+	}
+	e := hook.internalLogger.WithFields(logrus.Fields{
+		InternalField:    true,
+		"delivery.id":    id,
+		"delivery.stage": stage,
+	})
+	if err != nil {
+		e = e.WithError(&internalError{err: err})
+	}
+	e.Debug("elogrus delivery " + stage)
+}
+
+// traceOutcome logs acked
+// or failed for id
+func (hook *ElasticHook) traceOutcome(id uint64, err error) {
+	if err != nil {
+		hook.trace(id, "failed", err)
+		return
+	}
+	hook.trace(id, "acked", nil)
+}
@@ -0,0 +1,70 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestDeliveryTrace(t *testing.T) {
+	logger := logrus.New()
+	logger.Level = logrus.DebugLevel
+	rec := &recordingHook{}
+	logger.Hooks.Add(rec)
+
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithBulk(BulkConfig{FlushInterval: time.Hour}),
+		WithInternalLogger(logger),
+		WithDeliveryTrace(),
+	)
+	hook.Fire(&logrus.Entry{Data: logrus.Fields{}})
+	hook.Fire(&logrus.Entry{Data: logrus.Fields{}})
+
+	if len(rec.entries) != 2 {
+		t.Fatalf("expected 2 trace entries, got %d", len(rec.entries))
+	}
+	for i, e := range rec.entries {
+		if e.Data["delivery.stage"] != "enqueued" || e.Data["delivery.id"] != uint64(i+1) {
+			t.Errorf("unexpected trace entry %v", e.Data)
+		}
+		if !isReentrant(e) {
+			t.Error("trace entries must not be shipped")
+		}
+	}
+}
+
+func TestDeliveryTraceOff(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithDeliveryTrace())
+	if id := hook.deliveryID(); id != 0 {
+		t.Errorf("tracing needs an internal logger, got id %d", id)
+	}
+}
+
+func TestDeliveryTraceRetried(t *testing.T) {
+	logger := logrus.New()
+	logger.Level = logrus.DebugLevel
+	rec := &recordingHook{}
+	logger.Hooks.Add(rec)
+
+	hook, err := NewForwardingHook(&retryingForwarder{}, "localhost", logrus.DebugLevel, "test",
+		WithRetry(RetryConfig{Attempts: 2, MinBackoff: time.Millisecond}),
+		WithInternalLogger(logger),
+		WithDeliveryTrace(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close()
+	hook.Fire(&logrus.Entry{Data: logrus.Fields{}})
+
+	var stages []interface{}
+	for _, e := range rec.entries {
+		if stage, ok := e.Data["delivery.stage"]; ok {
+			stages = append(stages, stage)
+		}
+	}
+	if len(stages) != 3 || stages[0] != "sent" || stages[1] != "retried" || stages[2] != "acked" {
+		t.Errorf("expected sent, retried and acked, got %v", stages)
+	}
+}
@@ -0,0 +1,62 @@
+package elogrus
+
+import (
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// TraceparentField is the entry field
+// holding a W3C traceparent header
+const TraceparentField = "traceparent"
+
+// addTraceFields sets trace.id and span.id
+// from the traceparent field of the entry
+func addTraceFields(doc map[string]interface{}, entry *logrus.Entry) {
+	s, ok := entry.Data[TraceparentField].(string)
+	if !ok {
+		return
+	}
+	traceID, spanID, ok := parseTraceparent(s)
+	if !ok {
+		return
+	}
+	doc["trace.id"] = traceID
+	doc["span.id"] = spanID
+}
+
+// parseTraceparent returns the lower case trace
+// and parent IDs of a W3C traceparent value,
+// "version-traceid-parentid-flags"
+func parseTraceparent(s string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), "-")
+	if len(parts) < 4 {
+		return "", "", false
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	// future versions may append fields,
+	// version 00 has exactly four
+	if version == "ff" || (version == "00" && len(parts) != 4) {
+		return "", "", false
+	}
+	if !isHex(version, 2) || !isHex(traceID, 32) || !isHex(spanID, 16) || !isHex(flags, 2) {
+		return "", "", false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	for _, c := range []struct {
+		in       string
+		trace    string
+		span     string
+		expected bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+		{" 00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-00", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "", "", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "", "", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", "", "", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", "", "", false},
+		{"00-4bf92f3577b34da6-00f067aa0ba902b7-01", "", "", false},
+		{"garbage", "", "", false},
+	} {
+		trace, span, ok := parseTraceparent(c.in)
+		if ok != c.expected || trace != c.trace || span != c.span {
+			t.Errorf("parseTraceparent(%q) = %q, %q, %t", c.in, trace, span, ok)
+		}
+	}
+}
+
+func TestTraceFieldsInDocument(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test")
+	doc := hook.document(&logrus.Entry{Data: logrus.Fields{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}})
+	if doc["trace.id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || doc["span.id"] != "00f067aa0ba902b7" {
+		t.Errorf("unexpected trace fields %v %v", doc["trace.id"], doc["span.id"])
+	}
+}
@@ -0,0 +1,78 @@
+package elogrus
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// crockford is the base32 alphabet of ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidSource generates ULIDs which are
+// monotonic within the same millisecond
+type ulidSource struct {
+	mu      sync.Mutex
+	lastMs  uint64
+	lastRnd [10]byte
+}
+
+var ulids ulidSource
+
+// NewULID returns a new lexicographically
+// sortable unique identifier
+func NewULID() string {
+	return ulids.next(time.Now())
+}
+
+func (s *ulidSource) next(t time.Time) string {
+	ms := uint64(t.UnixNano() / int64(time.Millisecond))
+
+	s.mu.Lock()
+	// within the same millisecond the random
+	// part is incremented to keep ordering
+	if ms != s.lastMs || !increment(s.lastRnd[:]) {
+		rand.Read(s.lastRnd[:])
+		s.lastMs = ms
+	}
+	var u [16]byte
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], s.lastMs)
+	copy(u[:6], ts[2:])
+	copy(u[6:], s.lastRnd[:])
+	s.mu.Unlock()
+
+	return encodeULID(u)
+}
+
+// increment adds one to b as a big-endian
+// number, false if it overflowed
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID encodes the 128 bits in 26
+// characters of 5 bits each, the first
+// character only holding 3 bits
+func encodeULID(u [16]byte) string {
+	var dst [26]byte
+	for i := range dst {
+		var v byte
+		for b := 0; b < 5; b++ {
+			v <<= 1
+			idx := 5*i + b - 2
+			if idx >= 0 {
+				v |= (u[idx/8] >> (7 - uint(idx%8))) & 1
+			}
+		}
+		dst[i] = crockford[v]
+	}
+	return string(dst[:])
+}
@@ -0,0 +1,41 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestULIDFormat(t *testing.T) {
+	id := NewULID()
+	if len(id) != 26 {
+		t.Fatalf("expected 26 characters, got %q", id)
+	}
+	if id[0] > '7' {
+		t.Errorf("first character holds only 3 bits, got %q", id)
+	}
+}
+
+func TestULIDOrdering(t *testing.T) {
+	var s ulidSource
+	now := time.Now()
+
+	prev := s.next(now)
+	for i := 0; i < 100; i++ {
+		id := s.next(now)
+		if id <= prev {
+			t.Fatalf("ids within the same millisecond must increase: %s <= %s", id, prev)
+		}
+		prev = id
+	}
+	if later := s.next(now.Add(time.Millisecond)); later <= prev {
+		t.Errorf("later id must sort after earlier ones: %s <= %s", later, prev)
+	}
+}
+
+func TestEncodeULIDTimestamp(t *testing.T) {
+	var u [16]byte
+	u[5] = 1
+	if id := encodeULID(u); id[:10] != "0000000001" {
+		t.Errorf("unexpected timestamp encoding %s", id)
+	}
+}
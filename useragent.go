@@ -0,0 +1,162 @@
+package elogrus
+
+import (
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// UserAgent is a parsed
+// User-Agent header
+type UserAgent struct {
+	Name      string
+	Version   string
+	OS        string
+	OSVersion string
+	// Device is Desktop, Mobile,
+	// Tablet, Spider or Other
+	Device string
+}
+
+// WithUserAgent parses the entry field holding a
+// User-Agent header, default "user_agent", into
+// the user_agent.* fields of the Elastic Common
+// Schema, see ParseUserAgent
+func WithUserAgent(field string) Option {
+	return func(hook *ElasticHook) {
+		if field == "" {
+			field = "user_agent"
+		}
+		hook.userAgentField = field
+	}
+}
+
+// addUserAgent sets the fields of
+// the parsed user agent of entry
+func (hook *ElasticHook) addUserAgent(doc map[string]interface{}, entry *logrus.Entry) {
+	if hook.userAgentField == "" {
+		return
+	}
+	s, ok := entry.Data[hook.userAgentField].(string)
+	if !ok || s == "" {
+		return
+	}
+	ua := ParseUserAgent(s)
+	doc["user_agent.original"] = s
+	doc["user_agent.device.name"] = ua.Device
+	for name, v := range map[string]string{
+		"user_agent.name":       ua.Name,
+		"user_agent.version":    ua.Version,
+		"user_agent.os.name":    ua.OS,
+		"user_agent.os.version": ua.OSVersion,
+	} {
+		if v != "" {
+			doc[name] = v
+		}
+	}
+}
+
+// browsers are checked in order, as most
+// browsers also claim to be the ones before
+var browsers = []struct {
+	token string
+	name  string
+}{
+	{"Edg/", "Edge"},
+	{"Edge/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"MSIE ", "IE"},
+	{"curl/", "curl"},
+	{"Go-http-client/", "Go-http-client"},
+}
+
+// ParseUserAgent recognises common browsers,
+// operating systems and bots by heuristics,
+// unknown parts are left empty
+func ParseUserAgent(s string) UserAgent {
+	var ua UserAgent
+	for _, b := range browsers {
+		if v, ok := uaVersion(s, b.token); ok {
+			ua.Name, ua.Version = b.name, v
+			break
+		}
+	}
+	if ua.Name == "" {
+		switch {
+		case strings.Contains(s, "Trident/"):
+			ua.Name = "IE"
+			ua.Version, _ = uaVersion(s, "rv:")
+		case strings.Contains(s, "Safari/"):
+			ua.Name = "Safari"
+			ua.Version, _ = uaVersion(s, "Version/")
+		}
+	}
+
+	switch {
+	case strings.Contains(s, "Windows NT "):
+		ua.OS = "Windows"
+		v, _ := uaVersion(s, "Windows NT ")
+		ua.OSVersion = windowsVersions[v]
+		if ua.OSVersion == "" {
+			ua.OSVersion = v
+		}
+	case strings.Contains(s, "Android"):
+		ua.OS = "Android"
+		ua.OSVersion, _ = uaVersion(s, "Android ")
+	case strings.Contains(s, "iPhone") || strings.Contains(s, "iPad"):
+		ua.OS = "iOS"
+		v, _ := uaVersion(s, "OS ")
+		ua.OSVersion = strings.Replace(v, "_", ".", -1)
+	case strings.Contains(s, "Mac OS X"):
+		ua.OS = "macOS"
+		v, _ := uaVersion(s, "Mac OS X ")
+		ua.OSVersion = strings.Replace(v, "_", ".", -1)
+	case strings.Contains(s, "CrOS"):
+		ua.OS = "Chrome OS"
+	case strings.Contains(s, "Linux"):
+		ua.OS = "Linux"
+	}
+
+	lower := strings.ToLower(s)
+	switch {
+	case strings.Contains(lower, "bot") || strings.Contains(lower, "spider") || strings.Contains(lower, "crawler"):
+		ua.Device = "Spider"
+	case strings.Contains(s, "iPad") || strings.Contains(s, "Tablet") || (ua.OS == "Android" && !strings.Contains(s, "Mobile")):
+		ua.Device = "Tablet"
+	case strings.Contains(s, "Mobile") || strings.Contains(s, "iPhone"):
+		ua.Device = "Mobile"
+	case ua.OS != "":
+		ua.Device = "Desktop"
+	default:
+		ua.Device = "Other"
+	}
+	return ua
+}
+
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+	"6.0":  "Vista",
+	"5.1":  "XP",
+}
+
+// uaVersion returns the version
+// following token in s
+func uaVersion(s, token string) (string, bool) {
+	i := strings.Index(s, token)
+	if i < 0 {
+		return "", false
+	}
+	v := s[i+len(token):]
+	if end := strings.IndexAny(v, " ;)"); end >= 0 {
+		v = v[:end]
+	}
+	return v, true
+}
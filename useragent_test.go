@@ -0,0 +1,55 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	for _, c := range []struct {
+		header string
+		ua     UserAgent
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			UserAgent{"Chrome", "124.0.0.0", "Windows", "10", "Desktop"},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51",
+			UserAgent{"Edge", "124.0.2478.51", "Windows", "10", "Desktop"},
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			UserAgent{"Safari", "17.4", "iOS", "17.4", "Mobile"},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 14; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			UserAgent{"Chrome", "124.0.0.0", "Android", "14", "Tablet"},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:125.0) Gecko/20100101 Firefox/125.0",
+			UserAgent{"Firefox", "125.0", "macOS", "10.15", "Desktop"},
+		},
+		{
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			UserAgent{Device: "Spider"},
+		},
+		{"curl/8.4.0", UserAgent{Name: "curl", Version: "8.4.0", Device: "Other"}},
+	} {
+		if ua := ParseUserAgent(c.header); ua != c.ua {
+			t.Errorf("%s: expected %+v, got %+v", c.header, c.ua, ua)
+		}
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithUserAgent(""))
+	doc := hook.document(&logrus.Entry{Data: logrus.Fields{"user_agent": "curl/8.4.0"}})
+	if doc["user_agent.name"] != "curl" || doc["user_agent.original"] != "curl/8.4.0" {
+		t.Errorf("unexpected document %v", doc)
+	}
+	if _, ok := doc["user_agent.os.name"]; ok {
+		t.Error("unknown parts must be left out")
+	}
+}
@@ -0,0 +1,57 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// detectType asks the cluster for its version when no
+// type is set: ElasticSearch 5 and older keep the "log"
+// type, as they reject "_doc", and 7 on are typeless.
+// The default type is kept when the version cannot
+// be read, e.g. without privileges.
+func (hook *ElasticHook) detectType(client *elastic.Client) {
+	if client == nil || hook.typ != "" || hook.writeOnly {
+		return
+	}
+	major, ok := hook.clusterMajor(client)
+	if !ok {
+		return
+	}
+	if major < 6 {
+		hook.typ = "log"
+		return
+	}
+	hook.typ = "_doc"
+	hook.typeless = major >= 7
+}
+
+// clusterMajor returns the major
+// version of the cluster
+func (hook *ElasticHook) clusterMajor(client *elastic.Client) (int, bool) {
+	ctx, cancel := hook.controlContext()
+	defer cancel()
+	resp, err := client.PerformRequestC(ctx, "GET", "/", nil, nil)
+	if err != nil {
+		return 0, false
+	}
+	return majorVersion(resp.Body)
+}
+
+// majorVersion reads the major version
+// from the root endpoint of a cluster
+func majorVersion(body json.RawMessage) (int, bool) {
+	var info struct {
+		Version struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return 0, false
+	}
+	major, err := strconv.Atoi(strings.SplitN(info.Version.Number, ".", 2)[0])
+	return major, err == nil
+}
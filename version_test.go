@@ -0,0 +1,37 @@
+package elogrus
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMajorVersion(t *testing.T) {
+	for body, want := range map[string]int{
+		`{"name":"node-1","version":{"number":"6.8.23","build_flavor":"default"}}`: 6,
+		`{"version":{"number":"7.17.0"}}`:                                          7,
+		`{"version":{"number":"5.6.16"}}`:                                          5,
+	} {
+		if major, ok := majorVersion(json.RawMessage(body)); !ok || major != want {
+			t.Errorf("expected %d from %s, got %d", want, body, major)
+		}
+	}
+	if _, ok := majorVersion(json.RawMessage(`{"tagline":"You Know, for Search"}`)); ok {
+		t.Error("expected no version without number")
+	}
+}
+
+func TestTypeKeptWithoutClient(t *testing.T) {
+	hook := newHook(nil, "localhost", 0, "test")
+	hook.detectType(nil)
+	if hook.docType() != "_doc" || hook.bulkType() != "_doc" || hook.typeless {
+		t.Errorf("expected the default type, got %s", hook.docType())
+	}
+	clone := hook.Clone()
+	hook.typ, hook.typeless = "log", true
+	if clone.docType() != "log" || clone.bulkType() != "" {
+		t.Errorf("expected clones to follow the detected type, got %s", clone.docType())
+	}
+	if typed := hook.Clone(WithDocumentType("audit")); typed.docType() != "audit" {
+		t.Errorf("expected the type of the clone, got %s", typed.docType())
+	}
+}
@@ -0,0 +1,96 @@
+package elogrus
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+
+	"gopkg.in/olivere/elastic.v3"
+)
+
+// WarmupConfig configures the creation
+// of the next day's indices
+type WarmupConfig struct {
+	// Lead is how long before midnight (UTC)
+	// the indices are created, default 10 minutes
+	Lead time.Duration
+	// Fields are sample entry data routed to find
+	// the indices, e.g. one per tenant. Without
+	// samples an entry with no data is routed.
+	Fields []logrus.Fields
+}
+
+// WithIndexWarmup creates the indices the router
+// will pick tomorrow shortly before midnight, so
+// the first entry of the day does not wait for
+// index creation or race other instances for it.
+// Use it with a daily WithIndexPattern.
+func WithIndexWarmup(config WarmupConfig) Option {
+	return func(hook *ElasticHook) {
+		if config.Lead <= 0 {
+			config.Lead = 10 * time.Minute
+		}
+		if len(config.Fields) == 0 {
+			config.Fields = []logrus.Fields{{}}
+		}
+		hook.warmup = &config
+	}
+}
+
+// runWarmup creates the next
+// day's indices each night
+func (hook *ElasticHook) runWarmup() {
+	for {
+		midnight := nextMidnight(time.Now())
+		timer := time.NewTimer(time.Until(midnight.Add(-hook.warmup.Lead)))
+		select {
+		case <-timer.C:
+		case <-hook.quit:
+			timer.Stop()
+			return
+		}
+
+		err := hook.maintain(func(client *elastic.Client) error {
+			for _, index := range hook.warmIndices(midnight) {
+				if err := hook.ensureRouted(client, index); err != nil {
+					hook.reportError(err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			hook.reportError(err)
+		}
+
+		// wait for the new day
+		timer = time.NewTimer(time.Until(midnight))
+		select {
+		case <-timer.C:
+		case <-hook.quit:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// warmIndices returns the indices
+// routed to at midnight
+func (hook *ElasticHook) warmIndices(midnight time.Time) []string {
+	seen := map[string]bool{}
+	var indices []string
+	for _, data := range hook.warmup.Fields {
+		index := hook.indexFor(&logrus.Entry{Time: midnight, Data: data})
+		if !seen[index] {
+			seen[index] = true
+			indices = append(indices, index)
+		}
+	}
+	return indices
+}
+
+// nextMidnight returns the
+// next midnight in UTC
+func nextMidnight(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
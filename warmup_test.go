@@ -0,0 +1,36 @@
+package elogrus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestNextMidnight(t *testing.T) {
+	now := time.Date(2017, 12, 31, 23, 55, 0, 0, time.FixedZone("CET", 3600))
+	expected := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	if m := nextMidnight(now); !m.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, m)
+	}
+}
+
+func TestWarmIndices(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test",
+		WithIndexPattern("logs-{tenant}-{yyyy.MM.dd}", nil),
+		WithIndexWarmup(WarmupConfig{Fields: []logrus.Fields{
+			{"tenant": "acme"},
+			{"tenant": "globex"},
+			{"tenant": "acme"},
+		}}),
+	)
+	defer hook.Close()
+
+	indices := hook.warmIndices(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(indices) != 2 || indices[0] != "logs-acme-2018.01.01" || indices[1] != "logs-globex-2018.01.01" {
+		t.Errorf("unexpected indices %v", indices)
+	}
+	if hook.warmup.Lead != 10*time.Minute {
+		t.Errorf("unexpected default lead %s", hook.warmup.Lead)
+	}
+}
@@ -0,0 +1,20 @@
+package elogrus
+
+import "fmt"
+
+var (
+	// Fired if a cluster management call
+	// is made on a write-only hook
+	ErrWriteOnly = fmt.Errorf("Cluster management is disabled")
+)
+
+// WithWriteOnly disables all cluster management
+// (index existence checks, index creation, alias
+// switches), the hook only writes documents. Use
+// it with credentials limited to index writes;
+// indices must exist or be auto-created.
+func WithWriteOnly() Option {
+	return func(hook *ElasticHook) {
+		hook.writeOnly = true
+	}
+}
@@ -0,0 +1,19 @@
+package elogrus
+
+import (
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+)
+
+func TestWriteOnlySkipsManagement(t *testing.T) {
+	hook := newHook(nil, "localhost", logrus.DebugLevel, "test", WithWriteOnly())
+
+	// a nil client would panic if any management call were made
+	if err := hook.ensureIndex(nil, "test-acme"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := hook.SwitchIndex("test-v2", "{}"); err != ErrWriteOnly {
+		t.Errorf("expected ErrWriteOnly, got %v", err)
+	}
+}